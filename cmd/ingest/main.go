@@ -6,18 +6,26 @@ import (
 	"dex-ingest-sol/internal/pkg/configloader"
 	"dex-ingest-sol/internal/pkg/logger"
 	"dex-ingest-sol/internal/pkg/monitor"
+	"dex-ingest-sol/internal/pkg/mq"
 	"dex-ingest-sol/internal/svc"
 	"flag"
 	"fmt"
 	"github.com/zeromicro/go-zero/core/logx"
 	zerosvc "github.com/zeromicro/go-zero/core/service"
+	"net/http"
 	"os"
 	"os/signal"
 	"runtime/debug"
 	"syscall"
 )
 
-var configFile = flag.String("f", "etc/ingest-balance.yaml", "the config file")
+var (
+	configFile  = flag.String("f", "etc/ingest-balance.yaml", "the config file")
+	replayGroup = flag.String("dlq-group", "", "replay-dlq 使用的消费者组（仅 kafka 死信），默认 <group_id>-dlq-replay")
+	startFrom   = flag.String("start-from", "", "消费起始位置：committed / earliest / time / slot / checkpoint，覆盖配置 kafka.start.mode")
+	startTime   = flag.String("start-time", "", "start-from=time 时的起始时间（RFC3339）")
+	startSlot   = flag.Uint64("start-slot", 0, "start-from=slot 时的目标 slot")
+)
 
 func main() {
 	defer func() {
@@ -40,6 +48,23 @@ func main() {
 	logger.InitLogger(c.LogConf.ToLogOption())
 	logx.SetWriter(logger.ZapWriter{})
 
+	// 子命令：重放死信
+	if flag.Arg(0) == "replay-dlq" {
+		runReplayDeadLetter(&c)
+		return
+	}
+
+	// 命令行指定的起始位置优先于配置
+	if *startFrom != "" {
+		c.KafkaConsumer.Start = mq.KafkaStartConf{Mode: *startFrom, Time: *startTime, Slot: *startSlot}
+	}
+	if err := c.KafkaConsumer.Start.Validate(); err != nil {
+		panic(fmt.Errorf("配置错误: %w", err))
+	}
+	if err := ingest.ValidateUnknownVersion(c.Worker.UnknownVersion); err != nil {
+		panic(fmt.Errorf("配置错误: %w", err))
+	}
+
 	// 初始化依赖注入上下文
 	svcCtx := svc.NewIngestServiceContext(&c)
 
@@ -49,27 +74,44 @@ func main() {
 		logger.Errorf("无效的 ingest_type: %s", c.IngestType)
 		panic(fmt.Errorf("配置错误: %w", err))
 	}
-	partitionRouter := ingest.NewPartitionRouter(svcCtx.DB, svcCtx.Redis, &c.Worker, routerType)
+	deps := newSinkDeps(&c, routerType, svcCtx.DB, svcCtx.Dialect, svcCtx.Redis)
+	holders, tokenStats := deps.Holders, deps.TokenStats
+	sink, err := ingest.NewSink(c.Sinks, deps)
+	if err != nil {
+		panic(fmt.Errorf("配置错误: %w", err))
+	}
+	partitionRouter := ingest.NewPartitionRouter(sink, svcCtx.DeadLetter, &c.Worker, routerType)
+	partitionRouter.SetSlotBlockLoader(ingest.NewSlotBlockLoader(svcCtx.DB, routerType))
+	if tokenStats != nil {
+		partitionRouter.SetTokenStats(tokenStats)
+	}
 
 	// 构建 Kafka 消费核心组件
 	consumerRunner, err := ingest.NewConsumerRunner(&c.KafkaConsumer, partitionRouter)
 	if err != nil {
 		panic(err)
 	}
+	consumerRunner.Checkpoints = ingest.NewCheckpointLoader(svcCtx.DB, routerType)
+	consumerRunner.StartMarker = ingest.NewStartMarker(svcCtx.DB, routerType)
 
 	// 构造 go-zero ServiceGroup 管理服务
+	// partitionRouter 由 consumerRunner 启动和关闭，保证关闭顺序
 	sg := zerosvc.NewServiceGroup()
-	sg.Add(partitionRouter)
 	sg.Add(consumerRunner)
+	if holders != nil {
+		sg.Add(holders)
+	}
 
 	if c.Monitor.Port > 0 {
+		monitor.RegisterHealthCheck("partition_router", partitionRouter.Health)
+		monitor.RegisterHandler("/slots", http.HandlerFunc(partitionRouter.ServeSlots))
 		monitorServer := monitor.NewMonitorServer(c.Monitor.Port)
 		sg.Add(monitorServer)
 	}
 
 	// 启动服务
-	logger.Infof("落库服务启动成功, ingest_type=%s, topic=%s",
-		c.IngestType, c.KafkaConsumer.Topic)
+	logger.Infof("落库服务启动成功, ingest_type=%s, topic=%s, start_from=%s",
+		c.IngestType, c.KafkaConsumer.Topic, c.KafkaConsumer.Start.Mode)
 	sg.Start()
 
 	// 等待退出
@@ -79,4 +121,14 @@ func main() {
 
 	logger.Info("Shutting down services...")
 	sg.Stop()
+
+	if err := svcCtx.DB.Close(); err != nil {
+		logger.Errorf("close database failed: %v", err)
+	}
+	if svcCtx.Redis != nil {
+		if err := svcCtx.Redis.Close(); err != nil {
+			logger.Errorf("close redis failed: %v", err)
+		}
+	}
+	logger.Info("shutdown complete")
 }
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"dex-ingest-sol/internal/config"
+	"dex-ingest-sol/internal/ingest"
+	"dex-ingest-sol/internal/pkg/dlq"
+	"dex-ingest-sol/internal/pkg/logger"
+	"dex-ingest-sol/internal/svc"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// runReplayDeadLetter 重放死信：ingest -f <config> replay-dlq
+func runReplayDeadLetter(c *config.IngestConfig) {
+	routerType, err := ingest.ParseRouterType(c.IngestType)
+	if err != nil {
+		panic(fmt.Errorf("配置错误: %w", err))
+	}
+
+	groupID := *replayGroup
+	if groupID == "" {
+		groupID = c.KafkaConsumer.GroupID + "-dlq-replay"
+	}
+	reader, err := dlq.NewReader(&c.DeadLetter, c.KafkaConsumer.Brokers, groupID)
+	if err != nil {
+		panic(fmt.Sprintf("死信读取初始化失败: %v", err))
+	}
+	defer reader.Close()
+
+	db, dialect := svc.MustInitStorage(c.Storage, c.Lindorm, c.Postgres)
+	defer db.Close()
+
+	// 与线上消费使用相同的依赖，重放的数据同样更新持有人数、token 统计和热点池子缓存
+	deps := newSinkDeps(c, routerType, db, dialect, svc.MustInitRedis(&c.Redis))
+	sink, err := ingest.NewSink(c.Sinks, deps)
+	if err != nil {
+		panic(fmt.Errorf("配置错误: %w", err))
+	}
+	defer sink.Close()
+	// 对账和统计写入在重放期间运行，重放结束后停止（token 统计停止时做最后一次写入）
+	if deps.Holders != nil {
+		deps.Holders.Start()
+		defer deps.Holders.Stop()
+	}
+	if deps.TokenStats != nil {
+		deps.TokenStats.Start()
+		defer deps.TokenStats.Stop()
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	logger.Infof("开始重放死信, ingest_type=%s, dead_letter=%s", c.IngestType, c.DeadLetter.Type)
+	stats, err := ingest.ReplayDeadLetters(ctx, reader, sink, routerType)
+	logger.Infof("死信重放结束: total=%d, replayed=%d, skipped=%d, failed=%d",
+		stats.Total, stats.Replayed, stats.Skipped, stats.Failed)
+	if err != nil {
+		logger.Errorf("死信重放中断: %v", err)
+	}
+}
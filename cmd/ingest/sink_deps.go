@@ -0,0 +1,29 @@
+package main
+
+import (
+	"database/sql"
+	"dex-ingest-sol/internal/config"
+	"dex-ingest-sol/internal/ingest"
+	"dex-ingest-sol/internal/pkg/db"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// newSinkDeps 构建写入依赖，线上消费和死信重放共用，保证两条路径维护相同的派生数据
+// （持有人数、token 统计、热点池子缓存）
+func newSinkDeps(c *config.IngestConfig, routerType ingest.RouterType, conn *sql.DB, dialect db.DBType, rdb redis.UniversalClient) ingest.SinkDeps {
+	deps := ingest.SinkDeps{
+		DB:         conn,
+		Dialect:    dialect,
+		Redis:      rdb,
+		PoolCache:  c.PoolCache,
+		ClickHouse: c.ClickHouse,
+	}
+	if routerType == ingest.RouterBalance {
+		deps.Holders = ingest.NewHolderStats(conn, c.HolderStats)
+	}
+	if c.TokenStats.Enabled && routerType == ingest.RouterEvent {
+		deps.TokenStats = ingest.NewTokenStatsTracker(conn, c.TokenStats.Interval)
+	}
+	return deps
+}
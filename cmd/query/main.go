@@ -57,7 +57,7 @@ func main() {
 	}
 
 	// ========== 5. 构建并注册 gRPC 服务 ==========
-	queryService := query.NewQueryService(svcCtx.DB)
+	queryService := query.NewQueryService(svcCtx.DB, svcCtx.Redis)
 	rpcServer := zrpc.MustNewServer(c.Grpc.ToRpcServerConf(), func(grpcServer *grpc.Server) {
 		pb.RegisterIngestQueryServiceServer(grpcServer, queryService)
 	})
@@ -53,6 +53,7 @@ func main() {
 	//testQueryPoolsByAddresses(client)
 	//testQueryPoolsByToken(client)
 	//testQueryTransferEvents(client)
+	//testQueryTokensByAddresses(client)
 }
 
 func testQueryHolderCountByToken(client pb.IngestQueryServiceClient) {
@@ -307,3 +308,25 @@ func testQueryTransferEvents(client pb.IngestQueryServiceClient) {
 			ev.UserWallet, ev.ToWallet, ev.Token, ev.TokenAmount, ev.BlockTime)
 	}
 }
+
+func testQueryTokensByAddresses(client pb.IngestQueryServiceClient) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.QueryTokensByAddresses(ctx, &pb.TokenAddressesReq{
+		TokenAddresses: []string{testToken}, // 替换成真实 token 地址
+	})
+	if err != nil {
+		log.Printf("QueryTokensByAddresses error: %v", err)
+		return
+	}
+	log.Printf("TokensByAddresses result:")
+	for _, tr := range resp.Results {
+		if tr.Token == nil {
+			log.Printf("token=%s not found", tr.TokenAddress)
+			continue
+		}
+		log.Printf("token=%s symbol=%s name=%s decimals=%d total_supply=%d",
+			tr.TokenAddress, tr.Token.Symbol, tr.Token.Name, tr.Token.Decimals, tr.Token.TotalSupply)
+	}
+}
@@ -1,6 +1,9 @@
 package config
 
-import "dex-ingest-sol/internal/pkg/logger"
+import (
+	"dex-ingest-sol/internal/pkg/logger"
+	"time"
+)
 
 type MonitorConfig struct {
 	Port int `json:"port"` // 监控端口，0 表示关闭
@@ -33,3 +36,28 @@ type LindormConf struct {
 	MaxIdleConns    int    `yaml:"max_idle_conns"`     // 最大空闲连接数
 	ConnMaxIdleTime string `yaml:"conn_max_idle_time"` // 空闲连接最大保持时间（如 "5m"）
 }
+
+type PostgresConf struct {
+	User            string `yaml:"user"`               // 用户名
+	Password        string `yaml:"password"`           // 密码
+	Host            string `yaml:"host"`               // 主机名或 IP
+	Port            int    `yaml:"port"`               // 端口，默认 5432
+	Database        string `yaml:"database"`           // 数据库名
+	SSLMode         string `yaml:"sslmode"`            // disable / require / verify-full，留空为 disable
+	ConnectTimeout  int    `yaml:"connect_timeout"`    // 连接超时时间（秒）
+	MaxOpenConns    int    `yaml:"max_open_conns"`     // 最大连接数
+	MaxIdleConns    int    `yaml:"max_idle_conns"`     // 最大空闲连接数
+	ConnMaxIdleTime string `yaml:"conn_max_idle_time"` // 空闲连接最大保持时间（如 "5m"）
+}
+
+type ClickHouseConf struct {
+	Addr          string        `yaml:"addr"`           // HTTP 地址，如 http://127.0.0.1:8123
+	User          string        `yaml:"user"`           // 用户名
+	Password      string        `yaml:"password"`       // 密码
+	Database      string        `yaml:"database"`       // 数据库名
+	Timeout       time.Duration `yaml:"timeout"`        // 单次请求超时时间（如 "10s"）
+	BatchRows     int           `yaml:"batch_rows"`     // 缓冲行数达到 N 条即写入
+	FlushInterval time.Duration `yaml:"flush_interval"` // 缓冲最长保留时间（如 "5s"）
+	QueueSize     int           `yaml:"queue_size"`     // 待写入 flush 队列长度，队列满时转存到积压文件
+	SpoolPath     string        `yaml:"spool_path"`     // 队列满或退出时未写入数据的本地积压文件，队列写空后或下次启动时补写
+}
@@ -1,21 +1,63 @@
 package config
 
 import (
+	"dex-ingest-sol/internal/pkg/dlq"
 	"dex-ingest-sol/internal/pkg/mq"
+	"dex-ingest-sol/internal/pkg/xredis"
 	"time"
 )
 
 type WorkerConfig struct {
-	MaxBlockHold  int           `yaml:"max_block_hold"`  // 缓冲区区块数达到 N 条即触发 flush
-	MaxBatchFlush int           `yaml:"max_batch_flush"` // 每批最大 flush 的区块数量
-	FlushInterval time.Duration `yaml:"flush_interval"`  // 超时时间间隔（如 "3s"）
+	MaxBlockHold    int           `yaml:"max_block_hold"`   // 缓冲区区块数达到 N 条即触发 flush
+	MaxBatchFlush   int           `yaml:"max_batch_flush"`  // 每批最大 flush 的区块数量
+	FlushInterval   time.Duration `yaml:"flush_interval"`   // 超时时间间隔（如 "3s"）
+	HighWater       int           `yaml:"high_water"`       // 分区通道积压达到该值时暂停拉取该分区（默认 800）
+	LowWater        int           `yaml:"low_water"`        // 暂停后积压降到该值以下时恢复拉取（默认 200）
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"` // 关闭时写完缓冲数据并提交 offset 的超时时间（默认 30s）
+	UnknownVersion  string        `yaml:"unknown_version"`  // 未知 Events 版本的处理方式：dead_letter（默认）/ skip / halt
+}
+
+// TokenStatsConf token 滚动窗口交易统计配置（仅 event 类型生效）
+type TokenStatsConf struct {
+	Enabled  bool          `yaml:"enabled"`  // 是否启用，需在所有 event 实例上同时开启（分钟汇总由各实例按分区写入）
+	Interval time.Duration `yaml:"interval"` // 写入 token_stats 的间隔（默认 15s）
+}
+
+// HolderStatsConf token 持有人数对账配置（仅 balance 类型生效）
+type HolderStatsConf struct {
+	ReconcileInterval time.Duration `yaml:"reconcile_interval"` // 对账间隔（默认 1m）
+	ReconcileBatch    int           `yaml:"reconcile_batch"`    // 每轮最多对账的 token 数（默认 200）
+	CountTimeout      time.Duration `yaml:"count_timeout"`      // 分段统计时单次查询的超时时间（默认 30s）
+	CountPageSize     int           `yaml:"count_page_size"`    // 分段统计时每次读取的余额账户数（默认 5000）
+	Seed              bool          `yaml:"seed"`               // 是否遍历 balance 表中的全部 token，补齐从未对账过的计数
+}
+
+// PoolCacheConf Redis 热点池子缓存配置（仅 event 类型且配置了 redis 时生效）
+type PoolCacheConf struct {
+	Size int           `yaml:"size"` // 每个池子缓存的最近事件数（默认 200）
+	TTL  time.Duration `yaml:"ttl"`  // 事件缓存从建立起的保留时间，到期后由新事件重建；池子状态无新交易时的过期时间（默认 10m）
+}
+
+// SinkConf 定义一个写入目标及其失败策略
+type SinkConf struct {
+	Type    string `yaml:"type"`     // lindorm / postgres / clickhouse
+	OnError string `yaml:"on_error"` // fail（主存储默认，失败时不提交 offset 并重试）/ ignore（clickhouse 默认，只记录日志和指标）
 }
 
 type IngestConfig struct {
-	Monitor       MonitorConfig        `yaml:"monitor"`     // 监控配置
-	LogConf       LogConfig            `yaml:"logger"`      // 日志配置
-	KafkaConsumer mq.KafkaConsumerConf `yaml:"kafka"`       // Kafka 消费者配置
-	Lindorm       LindormConf          `yaml:"lindorm"`     // Lindorm 配置
-	Worker        WorkerConfig         `yaml:"worker"`      // Worker 批处理配置
-	IngestType    string               `yaml:"ingest_type"` // balance或者event
+	Monitor       MonitorConfig        `yaml:"monitor"`      // 监控配置
+	LogConf       LogConfig            `yaml:"logger"`       // 日志配置
+	KafkaConsumer mq.KafkaConsumerConf `yaml:"kafka"`        // Kafka 消费者配置
+	Storage       string               `yaml:"storage"`      // 存储方言：lindorm（默认）/ pg
+	Lindorm       LindormConf          `yaml:"lindorm"`      // Lindorm 配置
+	Postgres      PostgresConf         `yaml:"postgres"`     // PostgreSQL 配置（storage=pg 时使用）
+	ClickHouse    ClickHouseConf       `yaml:"clickhouse"`   // ClickHouse 配置（启用 clickhouse sink 时使用）
+	Worker        WorkerConfig         `yaml:"worker"`       // Worker 批处理配置
+	DeadLetter    dlq.DeadLetterConf   `yaml:"dead_letter"`  // 死信配置
+	TokenStats    TokenStatsConf       `yaml:"token_stats"`  // token 滚动窗口交易统计
+	HolderStats   HolderStatsConf      `yaml:"holder_stats"` // token 持有人数对账
+	Redis         xredis.RedisConfig   `yaml:"redis"`        // Redis 配置，addr 留空表示不启用
+	PoolCache     PoolCacheConf        `yaml:"pool_cache"`   // Redis 热点池子缓存
+	Sinks         []SinkConf           `yaml:"sinks"`        // 写入目标，留空默认只写 Lindorm
+	IngestType    string               `yaml:"ingest_type"`  // balance或者event
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"dex-ingest-sol/internal/pkg/xredis"
 	"fmt"
 	"github.com/zeromicro/go-zero/zrpc"
 	"time"
@@ -64,9 +65,12 @@ type NacosConfig struct {
 }
 
 type QueryConfig struct {
-	Grpc    GrpcConfig    `yaml:"grpc"`    // gRPC 服务配置（支持 timeout、method_timeouts 等）
-	Monitor MonitorConfig `yaml:"monitor"` // 监控配置
-	LogConf LogConfig     `yaml:"logger"`  // 日志配置
-	Lindorm LindormConf   `yaml:"lindorm"` // Lindorm 配置
-	Nacos   NacosConfig   `yaml:"nacos"`   // Nacos 配置
+	Grpc     GrpcConfig         `yaml:"grpc"`     // gRPC 服务配置（支持 timeout、method_timeouts 等）
+	Monitor  MonitorConfig      `yaml:"monitor"`  // 监控配置
+	LogConf  LogConfig          `yaml:"logger"`   // 日志配置
+	Storage  string             `yaml:"storage"`  // 存储方言：lindorm（默认）/ pg
+	Lindorm  LindormConf        `yaml:"lindorm"`  // Lindorm 配置
+	Postgres PostgresConf       `yaml:"postgres"` // PostgreSQL 配置（storage=pg 时使用）
+	Nacos    NacosConfig        `yaml:"nacos"`    // Nacos 配置
+	Redis    xredis.RedisConfig `yaml:"redis"`    // Redis 配置，addr 留空表示不读取热点池子缓存
 }
@@ -0,0 +1,104 @@
+package ingest
+
+import (
+	"context"
+	"database/sql"
+	"dex-ingest-sol/internal/ingest/handler"
+	"dex-ingest-sol/internal/pkg/logger"
+	"sync"
+	"time"
+)
+
+const (
+	candleSweepDelay        = 30 * time.Second // K 线最后一次重算后再次重算的延迟
+	candleSweepInterval     = 10 * time.Second // 检查到期 K 线的间隔
+	candleSweepFinalTimeout = 10 * time.Second // 关闭时重算剩余 K 线的超时时间
+)
+
+// candleSweeper 在 K 线最后一次重算 candleSweepDelay 之后按库中数据再重算一次。
+//
+// RecomputeCandles 先读后写，多个实例同时重算同一根 K 线时，先读后写的一方可能用较旧的数据覆盖较新的结果，
+// 之后该 K 线若不再有成交就一直是错的。每个实例都会在自己最后一次重算之后再重算一次，
+// 此时其它实例的写入已经完成，K 线收敛到库中数据对应的结果。
+type candleSweeper struct {
+	db *sql.DB
+
+	mu  sync.Mutex
+	due map[handler.CandleMinute]time.Time // 最小周期 K 线 → 再次重算的时间
+
+	stopCh   chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func newCandleSweeper(db *sql.DB) *candleSweeper {
+	w := &candleSweeper{
+		db:     db,
+		due:    make(map[handler.CandleMinute]time.Time),
+		stopCh: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// mark 记录刚重算过的 K 线，已记录的推迟到本次重算之后
+func (w *candleSweeper) mark(minutes []handler.CandleMinute) {
+	if len(minutes) == 0 {
+		return
+	}
+	at := time.Now().Add(candleSweepDelay)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, m := range minutes {
+		w.due[m] = at
+	}
+}
+
+// stop 停止定时重算，并立即重算尚未到期的 K 线，需在 worker 全部退出后调用
+func (w *candleSweeper) stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		<-w.done
+	})
+}
+
+func (w *candleSweeper) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(candleSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopCh:
+			ctx, cancel := context.WithTimeout(context.Background(), candleSweepFinalTimeout)
+			w.sweep(ctx, time.Time{})
+			cancel()
+			return
+		case now := <-ticker.C:
+			w.sweep(context.Background(), now)
+		}
+	}
+}
+
+// sweep 重算到期的 K 线，now 为零值时重算全部；失败时重新记录，下一轮重试
+func (w *candleSweeper) sweep(ctx context.Context, now time.Time) {
+	w.mu.Lock()
+	var minutes []handler.CandleMinute
+	for m, at := range w.due {
+		if now.IsZero() || !at.After(now) {
+			minutes = append(minutes, m)
+			delete(w.due, m)
+		}
+	}
+	w.mu.Unlock()
+	if len(minutes) == 0 {
+		return
+	}
+
+	if err := handler.RecomputeCandles(ctx, w.db, minutes); err != nil {
+		logger.Errorf("candle sweep for %d minutes failed, retry later: %v", len(minutes), err)
+		w.mark(minutes)
+	}
+}
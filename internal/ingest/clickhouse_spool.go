@@ -0,0 +1,132 @@
+package ingest
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// chSpoolRecord 是 chFlush 在本地积压文件中的一行（JSON Lines），行数据保持 JSONEachRow 编码
+type chSpoolRecord struct {
+	Router    string            `json:"router"`
+	Partition int32             `json:"partition"`
+	MaxSlot   uint64            `json:"max_slot"`
+	Rollbacks []*SlotRollback   `json:"rollbacks,omitempty"`
+	Events    []json.RawMessage `json:"events,omitempty"`
+	Transfers []json.RawMessage `json:"transfers,omitempty"`
+	Balances  []json.RawMessage `json:"balances,omitempty"`
+}
+
+// appendSpool 将未写入 ClickHouse 的 flush 按顺序追加到积压文件并落盘
+func appendSpool(path string, flushes []*chFlush) error {
+	if len(flushes) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create clickhouse spool dir failed: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("open clickhouse spool failed: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for _, cf := range flushes {
+		line, err := json.Marshal(&chSpoolRecord{
+			Router:    cf.key.router,
+			Partition: cf.key.partition,
+			MaxSlot:   cf.maxSlot,
+			Rollbacks: cf.rollbacks,
+			Events:    toRawRows(cf.events),
+			Transfers: toRawRows(cf.transfers),
+			Balances:  toRawRows(cf.balances),
+		})
+		if err != nil {
+			return fmt.Errorf("marshal clickhouse spool record: %w", err)
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// prependSpool 将 flushes 插入到积压文件已有数据之前：先写临时文件再替换，替换前原文件保持不变
+func prependSpool(path string, flushes []*chFlush) error {
+	existing, err := loadSpool(path)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove clickhouse spool tmp failed: %w", err)
+	}
+	if err := appendSpool(tmp, append(flushes, existing...)); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replace clickhouse spool failed: %w", err)
+	}
+	return nil
+}
+
+// loadSpool 读取积压文件，文件不存在时返回空
+func loadSpool(path string) ([]*chFlush, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open clickhouse spool failed: %w", err)
+	}
+	defer f.Close()
+
+	var flushes []*chFlush
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 1<<20), 256<<20) // 一行是一次 flush 的全部行数据
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var rec chSpoolRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, fmt.Errorf("decode clickhouse spool record: %w", err)
+		}
+		flushes = append(flushes, &chFlush{
+			key:       chPartitionKey{router: rec.Router, partition: rec.Partition},
+			maxSlot:   rec.MaxSlot,
+			rollbacks: rec.Rollbacks,
+			events:    fromRawRows(rec.Events),
+			transfers: fromRawRows(rec.Transfers),
+			balances:  fromRawRows(rec.Balances),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read clickhouse spool failed: %w", err)
+	}
+	return flushes, nil
+}
+
+func toRawRows(rows [][]byte) []json.RawMessage {
+	raw := make([]json.RawMessage, len(rows))
+	for i, row := range rows {
+		raw[i] = row
+	}
+	return raw
+}
+
+func fromRawRows(raw []json.RawMessage) [][]byte {
+	rows := make([][]byte, len(raw))
+	for i, row := range raw {
+		rows[i] = row
+	}
+	return rows
+}
@@ -2,16 +2,24 @@ package ingest
 
 import (
 	"context"
+	"database/sql"
+	"dex-ingest-sol/internal/ingest/handler"
+	"dex-ingest-sol/internal/ingest/model"
 	"dex-ingest-sol/internal/pkg/logger"
 	"dex-ingest-sol/internal/pkg/mq"
+	"github.com/cespare/xxhash/v2"
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"time"
 )
 
 // ConsumerRunner 结构体封装 consumer 启动逻辑
 type ConsumerRunner struct {
-	Kafka   *mq.KafkaConsumer
-	Context context.Context
-	Cancel  context.CancelFunc
+	Kafka       *mq.KafkaConsumer
+	Router      *PartitionRouter
+	Checkpoints mq.CheckpointLoader // start.mode=checkpoint 时读取存储中的 checkpoint（可为 nil）
+	StartMarker mq.StartMarker      // 记录回放类起始位置已执行，避免重启时重复回放（可为 nil）
+	Context     context.Context
+	Cancel      context.CancelFunc
 }
 
 // NewConsumerRunner 构造函数
@@ -27,15 +35,25 @@ func NewConsumerRunner(conf *mq.KafkaConsumerConf, router *PartitionRouter) (*Co
 	}
 
 	router.SetKafkaConsumer(kc.Consumer)
+	kc.Rebalance = router.Rebalance
 	return &ConsumerRunner{
 		Kafka:   kc,
+		Router:  router,
 		Context: ctx,
 		Cancel:  cancel,
 	}, nil
 }
 
-// Start 启动消费主循环（非阻塞）
+// Start 启动 PartitionRouter 和消费主循环（非阻塞）
 func (cr *ConsumerRunner) Start() {
+	cr.Router.Start()
+
+	// 按配置重置起始 offset（committed 模式下无操作）
+	if err := cr.Kafka.ResetOffsets(&cr.Kafka.Conf.Start, decodeSlot, cr.Checkpoints, cr.StartMarker); err != nil {
+		logger.Errorf("Kafka reset start offsets failed: %v", err)
+		panic(err)
+	}
+
 	if err := cr.Kafka.Start(cr.Context); err != nil {
 		// 记录错误日志
 		logger.Errorf("Kafka consumer start failed: %v", err)
@@ -45,9 +63,17 @@ func (cr *ConsumerRunner) Start() {
 	}
 }
 
-// Stop 优雅退出
+// Stop 按顺序优雅退出：停止拉取 → worker 写完缓冲数据并提交 offset → 关闭 sink → 离开消费者组。
+// PartitionRouter 由这里统一关闭，不要再单独加入 ServiceGroup（ServiceGroup 并发执行 Stop，无法保证顺序）。
 func (cr *ConsumerRunner) Stop() {
+	logger.Infof("shutdown: stop fetching from kafka")
+	cr.Kafka.StopFetching()
 	cr.Cancel()
+
+	logger.Infof("shutdown: draining partition workers")
+	cr.Router.Stop()
+
+	logger.Infof("shutdown: closing kafka consumer")
 	cr.Kafka.Close()
 }
 
@@ -55,3 +81,82 @@ func (cr *ConsumerRunner) Stop() {
 func (cr *ConsumerRunner) CommitOffset(messages ...*kafka.Message) error {
 	return cr.Kafka.Commit(messages...)
 }
+
+// decodeSlot 解析消息中的 slot（event / balance 两种 topic 的消息格式均为 pb.Events），
+// 与消费时相同按 version 选择解码器，未知版本返回错误
+func decodeSlot(msg *kafka.Message) (uint64, error) {
+	version, err := peekEventsVersion(msg.Value)
+	if err != nil {
+		return 0, err
+	}
+	events, err := decodeEventsVersion(version, msg.Value)
+	if err != nil {
+		return 0, err
+	}
+	return events.Slot, nil
+}
+
+// NewCheckpointLoader 从 ingest_checkpoint 表读取各分区下一条待消费消息的 offset
+func NewCheckpointLoader(dbConn *sql.DB, routerType RouterType) mq.CheckpointLoader {
+	return func(topic string) (map[int32]int64, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		checkpoints, err := handler.LoadCheckpoints(ctx, dbConn, routerType.String(), topic)
+		if err != nil {
+			return nil, err
+		}
+		offsets := make(map[int32]int64, len(checkpoints))
+		for _, cp := range checkpoints {
+			if cp.Partition == handler.StartMarkerPartition {
+				continue
+			}
+			offsets[cp.Partition] = cp.Offset + 1
+		}
+		return offsets, nil
+	}
+}
+
+// checkpointStartMarker 将已执行的起始位置记录在 ingest_checkpoint 中 partition_id = -1 的行，
+// kafka_offset 保存起始位置标识的哈希。只保留最近一次，需重新执行相同回放时删除该行即可。
+type checkpointStartMarker struct {
+	db     *sql.DB
+	router string
+}
+
+func NewStartMarker(dbConn *sql.DB, routerType RouterType) mq.StartMarker {
+	return &checkpointStartMarker{db: dbConn, router: routerType.String()}
+}
+
+func (m *checkpointStartMarker) Applied(topic, spec string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	checkpoints, err := handler.LoadCheckpoints(ctx, m.db, m.router, topic)
+	if err != nil {
+		return false, err
+	}
+	for _, cp := range checkpoints {
+		if cp.Partition == handler.StartMarkerPartition {
+			return cp.Offset == startSpecHash(spec), nil
+		}
+	}
+	return false, nil
+}
+
+func (m *checkpointStartMarker) MarkApplied(topic, spec string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return handler.UpsertCheckpoint(ctx, m.db, &model.Checkpoint{
+		Router:    m.router,
+		Topic:     topic,
+		Partition: handler.StartMarkerPartition,
+		Offset:    startSpecHash(spec),
+		UpdateAt:  int32(time.Now().Unix()),
+	})
+}
+
+func startSpecHash(spec string) int64 {
+	return int64(xxhash.Sum64String(spec) & 0x7FFFFFFFFFFFFFFF)
+}
@@ -0,0 +1,323 @@
+package ingest
+
+import (
+	"context"
+	"database/sql"
+	"dex-ingest-sol/internal/ingest/handler"
+	"dex-ingest-sol/internal/ingest/model"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// 每个分区在内存中记录最近多少个 slot 的区块哈希和写入主键。
+// 窗口之外（重启、rebalance 后或较旧的 slot）通过 slot_block 表中持久化的哈希和主键检测分叉。
+const forkWindowSlots = 256
+
+// SlotBlockLoader 批量读取已持久化的 slot_block 记录（slot → 区块哈希和写入主键）
+type SlotBlockLoader func(ctx context.Context, slots []uint64) (map[uint64]*model.SlotBlock, error)
+
+// NewSlotBlockLoader 从 slot_block 表读取当前 router 的记录
+func NewSlotBlockLoader(dbConn *sql.DB, routerType RouterType) SlotBlockLoader {
+	return func(ctx context.Context, slots []uint64) (map[uint64]*model.SlotBlock, error) {
+		return handler.LoadSlotBlocks(ctx, dbConn, routerType.String(), slots)
+	}
+}
+
+// SlotRollback 描述一次分叉回滚：flush 新区块前需要先删除旧区块写入的行
+type SlotRollback struct {
+	Slot      uint64
+	OldHash   string
+	NewHash   string
+	Events    []model.EventKey            // chain_event 主键
+	Transfers []model.EventKey            // transfer_event 主键
+	Migrates  []model.EventKey            // migration 主键
+	Pools     []string                    // pool_state 主键
+	Accounts  []string                    // balance 主键，同时用于删除 balance_history
+	BlockTime int64                       // 旧区块时间，回滚后据此重算 Pools 的 K 线和 Tokens 的分钟交易汇总
+	Tokens    []string                    // 旧区块成交的 token
+	Positions []handler.WalletPositionKey // 旧区块买卖涉及的钱包持仓，回滚后重算
+}
+
+// forkRecord 记录某个 slot 已消费区块的哈希和写入主键（只保留主键，不持有 batch 本身）
+type forkRecord struct {
+	slot      uint64
+	blockHash string
+	keys      *SlotRollback
+}
+
+// ForkTracker 按 slot 记录 block_hash，同一 slot 以不同哈希再次出现时生成回滚任务。
+// 使用 slot % forkWindowSlots 的环形数组，内存占用固定；环形数组未命中的 slot 在 flush 前查询 slot_block。
+type ForkTracker struct {
+	records [forkWindowSlots]*forkRecord
+	load    SlotBlockLoader // 为 nil 时只做内存窗口内的检测
+}
+
+func NewForkTracker(load SlotBlockLoader) *ForkTracker {
+	return &ForkTracker{load: load}
+}
+
+// Observe 记录 batch 的区块哈希，同一 slot 哈希变化时返回需要删除的旧区块主键。
+// 环形数组中没有该 slot 的记录时标记 batch，由 CheckPersisted 查询持久化记录。
+func (t *ForkTracker) Observe(batch *BlockBatch) *SlotRollback {
+	if batch.BlockHash == "" {
+		return nil
+	}
+
+	idx := batch.Slot % forkWindowSlots
+	rec := t.records[idx]
+	t.records[idx] = &forkRecord{slot: batch.Slot, blockHash: batch.BlockHash, keys: collectKeys(batch)}
+
+	// 内存中没有该 slot：新 slot，或重启 / rebalance / 超出窗口前已写入过
+	if rec == nil || rec.slot != batch.Slot {
+		batch.forkUnchecked = t.load != nil
+		return nil
+	}
+	// 同一区块重复消费
+	if rec.blockHash == batch.BlockHash {
+		return nil
+	}
+
+	rollback := rec.keys
+	rollback.OldHash = rec.blockHash
+	rollback.NewHash = batch.BlockHash
+	return rollback
+}
+
+// CheckPersisted 对环形数组未命中的 batch 查询 slot_block，已写入的区块哈希不同时返回对应的回滚任务
+func (t *ForkTracker) CheckPersisted(ctx context.Context, batches []*BlockBatch) (map[*BlockBatch]*SlotRollback, error) {
+	var slots []uint64
+	for _, b := range batches {
+		if b.forkUnchecked && !b.Superseded && b.Rollback == nil {
+			slots = append(slots, b.Slot)
+		}
+	}
+	if len(slots) == 0 {
+		return nil, nil
+	}
+
+	persisted, err := t.load(ctx, slots)
+	if err != nil {
+		return nil, err
+	}
+
+	var rollbacks map[*BlockBatch]*SlotRollback
+	for _, b := range batches {
+		if !b.forkUnchecked {
+			continue
+		}
+		b.forkUnchecked = false
+		if b.Superseded || b.Rollback != nil {
+			continue
+		}
+		rec, ok := persisted[b.Slot]
+		if !ok || rec.BlockHash == b.BlockHash {
+			continue
+		}
+		rollback, err := decodeRollbackKeys(b.Slot, rec.RollbackKeys)
+		if err != nil {
+			return nil, fmt.Errorf("decode rollback keys of slot %d: %w", b.Slot, err)
+		}
+		rollback.OldHash = rec.BlockHash
+		rollback.NewHash = b.BlockHash
+		if rollbacks == nil {
+			rollbacks = make(map[*BlockBatch]*SlotRollback)
+		}
+		rollbacks[b] = rollback
+	}
+	return rollbacks, nil
+}
+
+func collectKeys(batch *BlockBatch) *SlotRollback {
+	keys := &SlotRollback{
+		Slot:      batch.Slot,
+		BlockTime: batch.BlockTime,
+		Tokens:    handler.TradeTokens(batch.Events),
+		Positions: handler.TouchedWalletPositions(batch.Trades),
+	}
+	if n := len(batch.Events); n > 0 {
+		keys.Events = make([]model.EventKey, 0, n)
+		for _, e := range batch.Events {
+			keys.Events = append(keys.Events, model.EventKey{EventIDHash: e.EventIDHash, EventID: e.EventID})
+		}
+	}
+	if n := len(batch.Transfers); n > 0 {
+		keys.Transfers = make([]model.EventKey, 0, n)
+		for _, e := range batch.Transfers {
+			keys.Transfers = append(keys.Transfers, model.EventKey{EventIDHash: e.EventIDHash, EventID: e.EventID})
+		}
+	}
+	if n := len(batch.Migrates); n > 0 {
+		keys.Migrates = make([]model.EventKey, 0, n)
+		for _, m := range batch.Migrates {
+			keys.Migrates = append(keys.Migrates, model.EventKey{EventIDHash: m.EventIDHash, EventID: m.EventID})
+		}
+	}
+	if n := len(batch.States); n > 0 {
+		keys.Pools = make([]string, 0, n)
+		for _, s := range batch.States {
+			keys.Pools = append(keys.Pools, s.PoolAddress)
+		}
+	}
+	if n := len(batch.Balances); n > 0 {
+		keys.Accounts = make([]string, 0, n)
+		for _, b := range batch.Balances {
+			keys.Accounts = append(keys.Accounts, b.AccountAddress)
+		}
+	}
+	return keys
+}
+
+// encodeRollbackKeys 将区块写入的主键编码为紧凑的二进制，随 slot_block 持久化
+func encodeRollbackKeys(keys *SlotRollback) []byte {
+	size := 6 * binary.MaxVarintLen64
+	size += (len(keys.Events) + len(keys.Transfers) + len(keys.Migrates)) * 12
+	for _, p := range keys.Pools {
+		size += len(p) + 1
+	}
+	for _, a := range keys.Accounts {
+		size += len(a) + 1
+	}
+	for _, t := range keys.Tokens {
+		size += len(t) + 1
+	}
+	for _, p := range keys.Positions {
+		size += len(p.Owner) + len(p.Token) + 2
+	}
+
+	buf := make([]byte, 0, size)
+	for _, list := range [][]model.EventKey{keys.Events, keys.Transfers, keys.Migrates} {
+		buf = binary.AppendUvarint(buf, uint64(len(list)))
+		for _, k := range list {
+			buf = binary.BigEndian.AppendUint32(buf, uint32(k.EventIDHash))
+			buf = binary.BigEndian.AppendUint64(buf, uint64(k.EventID))
+		}
+	}
+	for _, list := range [][]string{keys.Pools, keys.Accounts} {
+		buf = appendStrings(buf, list)
+	}
+	buf = binary.AppendVarint(buf, keys.BlockTime)
+	buf = appendStrings(buf, keys.Tokens)
+	buf = binary.AppendUvarint(buf, uint64(len(keys.Positions)))
+	for _, p := range keys.Positions {
+		buf = binary.AppendUvarint(buf, uint64(len(p.Owner)))
+		buf = append(buf, p.Owner...)
+		buf = binary.AppendUvarint(buf, uint64(len(p.Token)))
+		buf = append(buf, p.Token...)
+	}
+	return buf
+}
+
+func appendStrings(buf []byte, list []string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(list)))
+	for _, v := range list {
+		buf = binary.AppendUvarint(buf, uint64(len(v)))
+		buf = append(buf, v...)
+	}
+	return buf
+}
+
+var errRollbackKeysCorrupted = errors.New("rollback keys corrupted")
+
+// decodeRollbackKeys 解码 encodeRollbackKeys 的结果，空数据表示该区块没有写入主键（旧版本未记录）
+func decodeRollbackKeys(slot uint64, data []byte) (*SlotRollback, error) {
+	keys := &SlotRollback{Slot: slot}
+	if len(data) == 0 {
+		return keys, nil
+	}
+
+	readLen := func() (int, error) {
+		n, size := binary.Uvarint(data)
+		if size <= 0 || n > uint64(len(data)) {
+			return 0, errRollbackKeysCorrupted
+		}
+		data = data[size:]
+		return int(n), nil
+	}
+
+	readString := func() (string, error) {
+		l, err := readLen()
+		if err != nil {
+			return "", err
+		}
+		if len(data) < l {
+			return "", errRollbackKeysCorrupted
+		}
+		v := string(data[:l])
+		data = data[l:]
+		return v, nil
+	}
+
+	eventLists := []*[]model.EventKey{&keys.Events, &keys.Transfers, &keys.Migrates}
+	for _, list := range eventLists {
+		n, err := readLen()
+		if err != nil {
+			return nil, err
+		}
+		if len(data) < n*12 {
+			return nil, errRollbackKeysCorrupted
+		}
+		for i := 0; i < n; i++ {
+			*list = append(*list, model.EventKey{
+				EventIDHash: int32(binary.BigEndian.Uint32(data)),
+				EventID:     int64(binary.BigEndian.Uint64(data[4:])),
+			})
+			data = data[12:]
+		}
+	}
+	for _, list := range []*[]string{&keys.Pools, &keys.Accounts} {
+		n, err := readLen()
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < n; i++ {
+			v, err := readString()
+			if err != nil {
+				return nil, err
+			}
+			*list = append(*list, v)
+		}
+	}
+	// 区块时间、成交 token 和钱包持仓在后续版本依次追加，早期记录没有这些字段
+	if len(data) == 0 {
+		return keys, nil
+	}
+	t, size := binary.Varint(data)
+	if size <= 0 {
+		return nil, errRollbackKeysCorrupted
+	}
+	keys.BlockTime = t
+	data = data[size:]
+	if len(data) == 0 {
+		return keys, nil
+	}
+	n, err := readLen()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		v, err := readString()
+		if err != nil {
+			return nil, err
+		}
+		keys.Tokens = append(keys.Tokens, v)
+	}
+	if len(data) == 0 {
+		return keys, nil
+	}
+	if n, err = readLen(); err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		owner, err := readString()
+		if err != nil {
+			return nil, err
+		}
+		token, err := readString()
+		if err != nil {
+			return nil, err
+		}
+		keys.Positions = append(keys.Positions, handler.WalletPositionKey{Owner: owner, Token: token})
+	}
+	return keys, nil
+}
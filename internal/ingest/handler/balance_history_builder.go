@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/utils"
+	"dex-ingest-sol/pb"
+	"github.com/hashicorp/golang-lru"
+)
+
+// BuildBalanceHistoryModels 为每个余额变更事件生成一条历史记录
+func BuildBalanceHistoryModels(events *pb.Events, cache *lru.Cache) []*model.BalanceHistory {
+	var result = make([]*model.BalanceHistory, 0, len(events.Events))
+	for _, e := range events.Events {
+		event, ok := e.Event.(*pb.Event_Balance)
+		if !ok || event.Balance == nil {
+			continue
+		}
+		result = append(result, buildBalanceHistoryModel(event.Balance, cache))
+	}
+	return result
+}
+
+func buildBalanceHistoryModel(event *pb.BalanceUpdateEvent, cache *lru.Cache) *model.BalanceHistory {
+	return &model.BalanceHistory{
+		AccountAddress: utils.EncodeBase58Strict(cache, event.Account),
+		EventID:        int64(event.EventId),
+		OwnerAddress:   utils.EncodeBase58Strict(cache, event.Owner),
+		TokenAddress:   utils.EncodeBase58Strict(cache, event.Token),
+		PreBalance:     utils.Uint64ToString(event.PreBalance),
+		PostBalance:    utils.Uint64ToString(event.PostBalance),
+		Delta:          balanceDelta(event.PreBalance, event.PostBalance),
+		BlockTime:      int32(event.BlockTime),
+	}
+}
+
+// balanceDelta 计算 post - pre，避免 uint64 相减溢出
+func balanceDelta(pre, post uint64) string {
+	if post >= pre {
+		return utils.Uint64ToString(post - pre)
+	}
+	return "-" + utils.Uint64ToString(pre-post)
+}
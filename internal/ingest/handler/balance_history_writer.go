@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/logger"
+	"fmt"
+	"strings"
+)
+
+const (
+	balanceHistoryBatchSize        = 2000
+	balanceHistoryUpsertFieldCount = 8
+)
+
+const balanceHistoryColumns = "account_address,event_id,owner_address,token_address," +
+	"pre_balance,post_balance,delta,block_time"
+
+var balanceHistoryValuePlaceholder = genPlaceholders(balanceHistoryUpsertFieldCount)
+
+// InsertBalanceHistory 追加写入余额变更记录，主键为 (account_address, event_id)，重复写入幂等
+func InsertBalanceHistory(ctx context.Context, dbConn *sql.DB, history []*model.BalanceHistory) error {
+	for i := 0; i < len(history); i += balanceHistoryBatchSize {
+		end := min(i+balanceHistoryBatchSize, len(history))
+		batch := history[i:end]
+
+		var builder strings.Builder
+		builder.Grow(256 + len(batch)*(len(balanceHistoryValuePlaceholder)+1))
+		builder.WriteString("INSERT INTO balance_history(" + balanceHistoryColumns + ") VALUES")
+
+		args := make([]any, 0, len(batch)*balanceHistoryUpsertFieldCount)
+		for j, h := range batch {
+			if j > 0 {
+				builder.WriteByte(',')
+			}
+			builder.WriteString(balanceHistoryValuePlaceholder)
+			args = append(args,
+				h.AccountAddress, h.EventID, h.OwnerAddress, h.TokenAddress,
+				h.PreBalance, h.PostBalance, h.Delta, h.BlockTime,
+			)
+		}
+
+		builder.WriteString(upsertClause(balanceHistoryColumns, "account_address", "event_id"))
+
+		query := builder.String()
+		retryRange := fmt.Sprintf("[%d:%d]", i, end)
+		err := db.RetryWithBackoff(ctx, func() error {
+			_, execErr := dbConn.ExecContext(ctx, query, args...)
+			if execErr != nil {
+				logger.Warnf("retrying balance_history insert %s: %v", retryRange, execErr)
+			}
+			return execErr
+		})
+		if err != nil {
+			return fmt.Errorf("insert balance_history %s failed after retries: %w (first account: %s)", retryRange, err, batch[0].AccountAddress)
+		}
+	}
+	return nil
+}
@@ -18,6 +18,8 @@ const (
 	balanceUpsertFieldCount = 5    // account_address, owner_address, token_address, balance, last_event_id
 )
 
+const balanceColumns = "account_address,owner_address,token_address,balance,last_event_id"
+
 var balanceValuePlaceholder = genPlaceholders(balanceUpsertFieldCount)
 
 func InsertBalances(ctx context.Context, db *sql.DB, balances []*model.Balance, isRealTime bool) error {
@@ -136,7 +138,7 @@ func upsertBalancesRealtime(ctx context.Context, dbConn *sql.DB, balances []*mod
 
 		var builder strings.Builder
 		builder.Grow(512 + len(batch)*estimatedSqlLengthPerRow)
-		builder.WriteString("INSERT INTO balance(account_address,owner_address,token_address,balance,last_event_id) VALUES")
+		builder.WriteString("INSERT INTO balance(" + balanceColumns + ") VALUES")
 
 		args := make([]any, 0, len(batch)*balanceUpsertFieldCount)
 		for j, b := range batch {
@@ -153,6 +155,8 @@ func upsertBalancesRealtime(ctx context.Context, dbConn *sql.DB, balances []*mod
 			)
 		}
 
+		builder.WriteString(upsertClause(balanceColumns, "account_address"))
+
 		query := builder.String()
 
 		retryRange := fmt.Sprintf("[%d:%d]", i, end)
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/pb"
+	"math/big"
+)
+
+// CandleIntervals 聚合的 K 线周期（秒）：1m / 5m / 15m / 1h / 4h / 1d。
+// 第一个周期由 chain_event 聚合，其余周期由上一个周期的 K 线合并，因此每个周期必须是上一个周期的整数倍。
+var CandleIntervals = []int32{60, 300, 900, 3600, 14400, 86400}
+
+type candleKey struct {
+	pool     string
+	interval int32
+	openTime int32
+}
+
+// CandleMinute 标识一根需要重算的最小周期 K 线
+type CandleMinute struct {
+	Pool     string
+	OpenTime int32
+}
+
+// TouchedCandleMinutes 返回买卖事件所在的 (池子, 最小周期开始时间)，按首次出现顺序去重。
+// 没有价格或区块时间的事件不参与聚合。
+func TouchedCandleMinutes(events []*model.ChainEvent) []CandleMinute {
+	var result []CandleMinute
+	seen := make(map[CandleMinute]struct{})
+	for _, e := range events {
+		if !isCandleTrade(e) {
+			continue
+		}
+		m := CandleMinute{Pool: e.PoolAddress, OpenTime: alignCandle(e.BlockTime, CandleIntervals[0])}
+		if _, ok := seen[m]; ok {
+			continue
+		}
+		seen[m] = struct{}{}
+		result = append(result, m)
+	}
+	return result
+}
+
+// CandleMinutesAt 返回各池子在 blockTime 所在的最小周期 K 线，用于分叉回滚后按旧区块时间重算
+func CandleMinutesAt(pools []string, blockTime int64) []CandleMinute {
+	if blockTime <= 0 {
+		return nil
+	}
+	openTime := alignCandle(int32(blockTime), CandleIntervals[0])
+	result := make([]CandleMinute, 0, len(pools))
+	for _, p := range pools {
+		result = append(result, CandleMinute{Pool: p, OpenTime: openTime})
+	}
+	return result
+}
+
+func alignCandle(t, interval int32) int32 {
+	return t - t%interval
+}
+
+// aggregateTrades 将一根 K 线周期内的全部买卖事件聚合为 K 线，没有有效成交时返回 nil
+func aggregateTrades(key candleKey, events []*model.ChainEvent) *model.Candle {
+	var c *model.Candle
+	for _, e := range events {
+		if !isCandleTrade(e) {
+			continue
+		}
+		if c == nil {
+			c = newCandle(key, e)
+			continue
+		}
+		MergeCandle(c, newCandle(key, e))
+	}
+	return c
+}
+
+// aggregateCandles 将下一级周期的全部 K 线合并为 key 对应的 K 线，没有下级 K 线时返回 nil
+func aggregateCandles(key candleKey, children []*model.Candle) *model.Candle {
+	var c *model.Candle
+	for _, child := range children {
+		if c == nil {
+			c = &model.Candle{}
+			*c = *child
+			c.IntervalSec = key.interval
+			c.OpenTime = key.openTime
+			continue
+		}
+		MergeCandle(c, child)
+	}
+	return c
+}
+
+func isCandleTrade(e *model.ChainEvent) bool {
+	if e.EventType != int16(pb.EventType_TRADE_BUY) && e.EventType != int16(pb.EventType_TRADE_SELL) {
+		return false
+	}
+	return e.PoolAddress != "" && e.PriceUsd > 0 && e.BlockTime > 0
+}
+
+func newCandle(key candleKey, e *model.ChainEvent) *model.Candle {
+	c := &model.Candle{
+		PoolAddress:  key.pool,
+		IntervalSec:  key.interval,
+		OpenTime:     key.openTime,
+		Token:        e.Token,
+		QuoteToken:   e.QuoteToken,
+		Open:         e.PriceUsd,
+		High:         e.PriceUsd,
+		Low:          e.PriceUsd,
+		Close:        e.PriceUsd,
+		VolumeUsd:    e.VolumeUsd,
+		BaseVolume:   e.TokenAmount,
+		QuoteVolume:  e.QuoteAmount,
+		OpenEventID:  e.EventID,
+		CloseEventID: e.EventID,
+	}
+	if e.EventType == int16(pb.EventType_TRADE_BUY) {
+		c.BuyCount = 1
+	} else {
+		c.SellCount = 1
+	}
+	return c
+}
+
+// MergeCandle 将 d 合并进 c：开盘 / 收盘价按 event_id 取最早 / 最晚，成交量和笔数累加
+func MergeCandle(c, d *model.Candle) {
+	if d.OpenEventID < c.OpenEventID {
+		c.Open = d.Open
+		c.OpenEventID = d.OpenEventID
+	}
+	if d.CloseEventID > c.CloseEventID {
+		c.Close = d.Close
+		c.CloseEventID = d.CloseEventID
+	}
+	c.High = max(c.High, d.High)
+	c.Low = min(c.Low, d.Low)
+	c.VolumeUsd += d.VolumeUsd
+	c.BaseVolume = addDecimal(c.BaseVolume, d.BaseVolume)
+	c.QuoteVolume = addDecimal(c.QuoteVolume, d.QuoteVolume)
+	c.BuyCount += d.BuyCount
+	c.SellCount += d.SellCount
+}
+
+// addDecimal 累加两个十进制整数字符串，超出 uint64 范围时也不会溢出
+func addDecimal(a, b string) string {
+	x, ok := new(big.Int).SetString(a, 10)
+	if !ok {
+		x = new(big.Int)
+	}
+	y, ok := new(big.Int).SetString(b, 10)
+	if !ok {
+		y = new(big.Int)
+	}
+	return x.Add(x, y).String()
+}
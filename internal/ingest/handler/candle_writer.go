@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/logger"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	candleBatchSize        = 1000
+	candleUpsertFieldCount = 17
+)
+
+const candleColumns = "pool_address,interval_sec,open_time,token,quote_token," +
+	"open_price,high_price,low_price,close_price,volume_usd,base_volume,quote_volume,buy_count,sell_count," +
+	"open_event_id,close_event_id,update_at"
+
+var candleValuePlaceholder = genPlaceholders(candleUpsertFieldCount)
+
+// candleMu 串行化本进程内各分区的 K 线重算，减少并发重算同一根 K 线时的读写交错
+var candleMu sync.Mutex
+
+// RecomputeCandles 按已写入的 chain_event 重算受影响的 K 线：最小周期由该分钟内的全部买卖事件聚合，
+// 其余周期由上一个周期的 K 线逐级合并。结果只取决于库中的数据，重复投递、flush 重试和回放不会重复计数；
+// 分叉回滚删除事件后重算即可扣除旧区块的成交。没有成交的 K 线会被删除。
+// 需在本次事件写入（或回滚删除）之后调用。多实例同时重算同一根 K 线时，先读后写的一方可能覆盖较新的结果，
+// 调用方需在最后一次重算之后延迟再重算一次修正。
+func RecomputeCandles(ctx context.Context, dbConn *sql.DB, minutes []CandleMinute) error {
+	if len(minutes) == 0 {
+		return nil
+	}
+
+	candleMu.Lock()
+	defer candleMu.Unlock()
+
+	interval := CandleIntervals[0]
+	keys := make([]candleKey, 0, len(minutes))
+	seen := make(map[candleKey]struct{}, len(minutes))
+	for _, m := range minutes {
+		key := candleKey{pool: m.Pool, interval: interval, openTime: alignCandle(m.OpenTime, interval)}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+
+	trades, err := loadCandleTrades(ctx, dbConn, keys)
+	if err != nil {
+		return err
+	}
+	candles := make(map[candleKey]*model.Candle, len(keys))
+	for _, key := range keys {
+		candles[key] = aggregateTrades(key, trades[key])
+	}
+	if err := saveCandles(ctx, dbConn, keys, candles); err != nil {
+		return err
+	}
+
+	for _, parentInterval := range CandleIntervals[1:] {
+		parents := make([]candleKey, 0, len(keys))
+		seen := make(map[candleKey]struct{}, len(keys))
+		for _, key := range keys {
+			parent := candleKey{pool: key.pool, interval: parentInterval, openTime: alignCandle(key.openTime, parentInterval)}
+			if _, ok := seen[parent]; ok {
+				continue
+			}
+			seen[parent] = struct{}{}
+			parents = append(parents, parent)
+		}
+
+		children, err := loadChildCandles(ctx, dbConn, parents, interval)
+		if err != nil {
+			return err
+		}
+		candles = make(map[candleKey]*model.Candle, len(parents))
+		for _, parent := range parents {
+			candles[parent] = aggregateCandles(parent, children[parent])
+		}
+		if err := saveCandles(ctx, dbConn, parents, candles); err != nil {
+			return err
+		}
+		keys, interval = parents, parentInterval
+	}
+	return nil
+}
+
+// groupCandlePools 按 (周期, 开始时间) 分组池子地址，查询时用池子地址 IN，避免依赖多列 IN 语法
+func groupCandlePools(keys []candleKey) map[candleKey][]string {
+	groups := make(map[candleKey][]string)
+	for _, key := range keys {
+		g := candleKey{interval: key.interval, openTime: key.openTime}
+		groups[g] = append(groups[g], key.pool)
+	}
+	return groups
+}
+
+// loadCandleTrades 查询每根最小周期 K 线时间范围内的池子事件（按 pool_address + block_time 索引），
+// 事件类型在聚合时过滤
+func loadCandleTrades(ctx context.Context, dbConn *sql.DB, keys []candleKey) (map[candleKey][]*model.ChainEvent, error) {
+	const queryFmt = "SELECT event_id, event_type, pool_address, token, quote_token, token_amount, quote_amount, " +
+		"volume_usd, price_usd, block_time FROM chain_event WHERE pool_address IN (%s) AND block_time >= ? AND block_time < ?"
+
+	trades := make(map[candleKey][]*model.ChainEvent, len(keys))
+	for g, pools := range groupCandlePools(keys) {
+		events, err := queryInBatches(ctx, dbConn, pools, queryFmt, []any{g.openTime, g.openTime + g.interval},
+			func(rows *sql.Rows) (*model.ChainEvent, error) {
+				e := &model.ChainEvent{}
+				if err := rows.Scan(&e.EventID, &e.EventType, &e.PoolAddress, &e.Token, &e.QuoteToken,
+					&e.TokenAmount, &e.QuoteAmount, &e.VolumeUsd, &e.PriceUsd, &e.BlockTime); err != nil {
+					return nil, fmt.Errorf("scan chain_event error: %w", err)
+				}
+				return e, nil
+			})
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range events {
+			key := candleKey{pool: e.PoolAddress, interval: g.interval, openTime: g.openTime}
+			trades[key] = append(trades[key], e)
+		}
+	}
+	return trades, nil
+}
+
+// loadChildCandles 查询每根父 K 线时间范围内的下一级周期 K 线
+func loadChildCandles(ctx context.Context, dbConn *sql.DB, parents []candleKey, childInterval int32) (map[candleKey][]*model.Candle, error) {
+	const queryFmt = "SELECT " + candleColumns + " FROM candle WHERE pool_address IN (%s) " +
+		"AND interval_sec = ? AND open_time >= ? AND open_time < ?"
+
+	children := make(map[candleKey][]*model.Candle, len(parents))
+	for g, pools := range groupCandlePools(parents) {
+		candles, err := queryInBatches(ctx, dbConn, pools, queryFmt, []any{childInterval, g.openTime, g.openTime + g.interval},
+			func(rows *sql.Rows) (*model.Candle, error) {
+				c := &model.Candle{}
+				if err := rows.Scan(
+					&c.PoolAddress, &c.IntervalSec, &c.OpenTime, &c.Token, &c.QuoteToken,
+					&c.Open, &c.High, &c.Low, &c.Close, &c.VolumeUsd, &c.BaseVolume, &c.QuoteVolume,
+					&c.BuyCount, &c.SellCount, &c.OpenEventID, &c.CloseEventID, &c.UpdateAt,
+				); err != nil {
+					return nil, fmt.Errorf("scan candle error: %w", err)
+				}
+				return c, nil
+			})
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range candles {
+			key := candleKey{pool: c.PoolAddress, interval: g.interval, openTime: g.openTime}
+			children[key] = append(children[key], c)
+		}
+	}
+	return children, nil
+}
+
+// saveCandles 写入重算结果，结果为 nil（周期内已没有成交）的 K 线删除
+func saveCandles(ctx context.Context, dbConn *sql.DB, keys []candleKey, candles map[candleKey]*model.Candle) error {
+	updateAt := int32(time.Now().Unix())
+	upserts := make([]*model.Candle, 0, len(keys))
+	var deletes []candleKey
+	for _, key := range keys {
+		c := candles[key]
+		if c == nil {
+			deletes = append(deletes, key)
+			continue
+		}
+		c.UpdateAt = updateAt
+		upserts = append(upserts, c)
+	}
+
+	if err := writeCandles(ctx, dbConn, upserts); err != nil {
+		return err
+	}
+	return deleteCandles(ctx, dbConn, deletes)
+}
+
+// deleteCandles 删除已没有成交的 K 线（只在分叉回滚后出现），逐行按主键删除
+func deleteCandles(ctx context.Context, dbConn *sql.DB, keys []candleKey) error {
+	const query = "DELETE FROM candle WHERE pool_address = ? AND interval_sec = ? AND open_time = ?"
+	for _, key := range keys {
+		err := db.RetryWithBackoff(ctx, func() error {
+			_, execErr := dbConn.ExecContext(ctx, query, key.pool, key.interval, key.openTime)
+			if execErr != nil {
+				logger.Warnf("retrying candle delete (pool=%s, interval=%d, open_time=%d): %v",
+					key.pool, key.interval, key.openTime, execErr)
+			}
+			return execErr
+		})
+		if err != nil {
+			return fmt.Errorf("delete candle (pool=%s, interval=%d, open_time=%d) failed after retries: %w",
+				key.pool, key.interval, key.openTime, err)
+		}
+	}
+	return nil
+}
+
+func writeCandles(ctx context.Context, dbConn *sql.DB, candles []*model.Candle) error {
+	for i := 0; i < len(candles); i += candleBatchSize {
+		end := min(i+candleBatchSize, len(candles))
+		batch := candles[i:end]
+
+		var builder strings.Builder
+		builder.Grow(256 + len(batch)*(len(candleValuePlaceholder)+1))
+		builder.WriteString("INSERT INTO candle(" + candleColumns + ") VALUES")
+
+		args := make([]any, 0, len(batch)*candleUpsertFieldCount)
+		for j, c := range batch {
+			if j > 0 {
+				builder.WriteByte(',')
+			}
+			builder.WriteString(candleValuePlaceholder)
+			args = append(args,
+				c.PoolAddress, c.IntervalSec, c.OpenTime, c.Token, c.QuoteToken,
+				c.Open, c.High, c.Low, c.Close, c.VolumeUsd, c.BaseVolume, c.QuoteVolume,
+				c.BuyCount, c.SellCount, c.OpenEventID, c.CloseEventID, c.UpdateAt,
+			)
+		}
+
+		builder.WriteString(upsertClause(candleColumns, "pool_address", "interval_sec", "open_time"))
+
+		query := builder.String()
+		retryRange := fmt.Sprintf("[%d:%d]", i, end)
+		err := db.RetryWithBackoff(ctx, func() error {
+			_, execErr := dbConn.ExecContext(ctx, query, args...)
+			if execErr != nil {
+				logger.Warnf("retrying candle upsert %s: %v", retryRange, execErr)
+			}
+			return execErr
+		})
+		if err != nil {
+			return fmt.Errorf("upsert candle %s failed after retries: %w (first pool: %s)", retryRange, err, batch[0].PoolAddress)
+		}
+	}
+	return nil
+}
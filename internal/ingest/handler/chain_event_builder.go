@@ -7,7 +7,7 @@ import (
 	"github.com/hashicorp/golang-lru"
 )
 
-// BuildChainEventModels 从 pb.Events 中提取标准 ChainEvent 模型（支持 Trade / Liquidity / Mint / Burn）
+// BuildChainEventModels 从 pb.Events 中提取标准 ChainEvent 模型（支持 Trade / Liquidity / Mint / Burn / Migrate）
 func BuildChainEventModels(events *pb.Events, cache *lru.Cache) []*model.ChainEvent {
 	var result = make([]*model.ChainEvent, 0, len(events.Events))
 	for _, e := range events.Events {
@@ -21,6 +21,10 @@ func BuildChainEventModels(events *pb.Events, cache *lru.Cache) []*model.ChainEv
 			ev = buildMintEvent(inner.Mint, cache)
 		case *pb.Event_Burn:
 			ev = buildBurnEvent(inner.Burn, cache)
+		case *pb.Event_Migrate:
+			// 迁移事件落两行：目标池为主记录，来源池为镜像记录，保证两个池子都能查到
+			result = append(result, buildMigrateEvent(inner.Migrate, cache, false))
+			ev = buildMigrateEvent(inner.Migrate, cache, true)
 		default:
 			continue
 		}
@@ -138,3 +142,38 @@ func buildBurnEvent(event *pb.BurnEvent, cache *lru.Cache) *model.ChainEvent {
 		CreateAt:  0,
 	}
 }
+
+// buildMigrateEvent 构建 MIGRATE 事件；isSrc=true 时生成来源池的镜像行（event_id_hash 取镜像值）
+func buildMigrateEvent(event *pb.MigrateEvent, cache *lru.Cache, isSrc bool) *model.ChainEvent {
+	ev := &model.ChainEvent{
+		EventIDHash: utils.EventIdHash(event.EventId),
+		EventID:     int64(event.EventId),
+		EventType:   int16(event.Type),
+		Dex:         int16(event.DestDex),
+
+		UserWallet: utils.EncodeBase58Strict(cache, event.UserWallet),
+		ToWallet:   "",
+
+		PoolAddress: utils.EncodeBase58Strict(cache, event.DestPairAddress),
+		Token:       utils.EncodeBase58Strict(cache, event.Token),
+		QuoteToken:  utils.EncodeBase58Strict(cache, event.DestQuoteToken),
+
+		TokenAmount: utils.Uint64ToString(event.TokenAmount),
+		QuoteAmount: utils.Uint64ToString(event.QuoteTokenAmount),
+		VolumeUsd:   0,
+		PriceUsd:    0,
+
+		TxHash: utils.TxHashToString(event.TxHash),
+		Signer: utils.EncodeBase58Optional(cache, utils.SelectSigner(event.Signers, event.UserWallet)),
+
+		BlockTime: int32(event.BlockTime),
+		CreateAt:  0,
+	}
+	if isSrc {
+		ev.EventIDHash = utils.MirrorEventIdHash(event.EventId)
+		ev.Dex = int16(event.SrcDex)
+		ev.PoolAddress = utils.EncodeBase58Strict(cache, event.SrcPairAddress)
+		ev.QuoteToken = utils.EncodeBase58Strict(cache, event.SrcQuoteToken)
+	}
+	return ev
+}
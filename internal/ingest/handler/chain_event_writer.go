@@ -6,9 +6,13 @@ import (
 	"dex-ingest-sol/internal/ingest/model"
 	"dex-ingest-sol/internal/pkg/db"
 	"dex-ingest-sol/internal/pkg/logger"
+	"dex-ingest-sol/internal/pkg/poolcache"
+	"dex-ingest-sol/internal/pkg/utils"
+	"dex-ingest-sol/pb"
 	"fmt"
 	"github.com/redis/go-redis/v9"
 	"runtime/debug"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -19,6 +23,11 @@ const (
 	chainEventsUpsertFieldCount = 17
 )
 
+const chainEventsColumns = "event_id_hash,event_id,event_type,dex," +
+	"user_wallet,to_wallet,pool_address,token,quote_token," +
+	"token_amount,quote_amount,volume_usd,price_usd," +
+	"tx_hash,signer,block_time,create_at"
+
 var chainEventsValuePlaceholder = genPlaceholders(chainEventsUpsertFieldCount)
 
 func InsertChainEvents(ctx context.Context, db *sql.DB, events []*model.ChainEvent) error {
@@ -82,11 +91,7 @@ func insertChainEventsSerial(ctx context.Context, dbConn *sql.DB, events []*mode
 		var builder strings.Builder
 		builder.Grow(512 + len(batch)*estimatedSqlLengthPerRow)
 
-		builder.WriteString("INSERT INTO chain_event(" +
-			"event_id_hash,event_id,event_type,dex," +
-			"user_wallet,to_wallet,pool_address,token,quote_token," +
-			"token_amount,quote_amount,volume_usd,price_usd," +
-			"tx_hash,signer,block_time,create_at) VALUES")
+		builder.WriteString("INSERT INTO chain_event(" + chainEventsColumns + ") VALUES")
 
 		args := make([]any, 0, len(batch)*chainEventsUpsertFieldCount)
 		createAt := int32(time.Now().Unix())
@@ -103,6 +108,8 @@ func insertChainEventsSerial(ctx context.Context, dbConn *sql.DB, events []*mode
 			)
 		}
 
+		builder.WriteString(upsertClause(chainEventsColumns, "event_id_hash", "event_id"))
+
 		query := builder.String()
 		retryRange := fmt.Sprintf("[%d:%d]", i, end)
 
@@ -120,7 +127,142 @@ func insertChainEventsSerial(ctx context.Context, dbConn *sql.DB, events []*mode
 	return nil
 }
 
-// SyncPoolCache 根据事件同步 Redis 中的 Pool 缓存（用于前端查询）
-func SyncPoolCache(ctx context.Context, redisClient *redis.Client, events []*model.ChainEvent) error {
-	return nil
+// poolStateScript 仅当事件比缓存中的更新时覆盖池子状态，event_id 按定长字符串比较
+var poolStateScript = redis.NewScript(`
+local cur = redis.call('HGET', KEYS[1], 'last_event_id')
+if cur and cur >= ARGV[1] then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'last_event_id', ARGV[1], 'price_usd', ARGV[2], 'last_trade_time', ARGV[3])
+redis.call('EXPIRE', KEYS[1], ARGV[4])
+return 1
+`)
+
+// poolEventsExpireScript 仅在事件集合没有过期时间时设置：集合从建立起最多保留 ttl，不随写入续期，
+// 即使清除失败，缺口或分叉残留也会随 key 到期消失，之后由新的实时事件重新建立
+var poolEventsExpireScript = redis.NewScript(`
+if redis.call('TTL', KEYS[1]) == -1 then
+	redis.call('EXPIRE', KEYS[1], ARGV[1])
+end
+return 0
+`)
+
+// CachedPools 返回事件中会写入缓存的池子（去重）
+func CachedPools(events []*model.ChainEvent) []string {
+	var pools []string
+	seen := make(map[string]struct{})
+	for _, e := range events {
+		if e.PoolAddress == "" || !poolcache.IsCachedType(uint32(e.EventType)) {
+			continue
+		}
+		if _, ok := seen[e.PoolAddress]; !ok {
+			seen[e.PoolAddress] = struct{}{}
+			pools = append(pools, e.PoolAddress)
+		}
+	}
+	return pools
+}
+
+// SyncPoolCache 根据事件同步 Redis 中的 Pool 缓存（用于前端查询）：
+// 每个池子保留最近 size 条连续事件（建立 ttl 后过期）和最近一笔交易的价格、时间（ttl 内无新交易则过期）。
+// 调用方只应传入实时区块的事件；写入失败时需调用 InvalidatePoolCache 清除涉及的池子，否则缓存中会留下缺口。
+func SyncPoolCache(ctx context.Context, redisClient redis.UniversalClient, events []*model.ChainEvent, size int, ttl time.Duration) error {
+	members := make(map[string][]redis.Z)
+	latestTrades := make(map[string]*model.ChainEvent)
+	for _, e := range events {
+		if e.PoolAddress == "" || !poolcache.IsCachedType(uint32(e.EventType)) {
+			continue
+		}
+		member, err := poolcache.EncodeEvent(toCachedEvent(e))
+		if err != nil {
+			return fmt.Errorf("encode event %d: %w", e.EventID, err)
+		}
+		members[e.PoolAddress] = append(members[e.PoolAddress], redis.Z{
+			Score:  poolcache.Score(uint64(e.EventID)),
+			Member: member,
+		})
+
+		if pb.EventType(e.EventType) != pb.EventType_TRADE_BUY && pb.EventType(e.EventType) != pb.EventType_TRADE_SELL {
+			continue
+		}
+		if e.PriceUsd <= 0 {
+			continue
+		}
+		if prev, ok := latestTrades[e.PoolAddress]; !ok || e.EventID > prev.EventID {
+			latestTrades[e.PoolAddress] = e
+		}
+	}
+	if len(members) == 0 {
+		return nil
+	}
+
+	ttlSeconds := int64(ttl / time.Second)
+	_, err := redisClient.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for pool, zs := range members {
+			key := poolcache.EventsKey(pool)
+			p.ZAdd(ctx, key, zs...)
+			p.ZRemRangeByRank(ctx, key, 0, int64(-size-1))
+			poolEventsExpireScript.Eval(ctx, p, []string{key}, ttlSeconds)
+		}
+		for pool, e := range latestTrades {
+			poolStateScript.Eval(ctx, p, []string{poolcache.StateKey(pool)},
+				poolcache.EncodeEventID(uint64(e.EventID)), e.PriceUsd, e.BlockTime, ttlSeconds)
+		}
+		return nil
+	})
+	return err
+}
+
+// RollbackPoolCache 删除被回滚 slot 写入缓存的事件，池子状态直接删除，由后续交易重新写入。
+// 失败时需调用 InvalidatePoolCache 清除涉及的池子
+func RollbackPoolCache(ctx context.Context, redisClient redis.UniversalClient, pools []string, slot uint64) error {
+	if len(pools) == 0 {
+		return nil
+	}
+	score := strconv.FormatUint(slot, 10)
+	_, err := redisClient.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for _, pool := range pools {
+			p.ZRemRangeByScore(ctx, poolcache.EventsKey(pool), score, score)
+			p.Del(ctx, poolcache.StateKey(pool))
+		}
+		return nil
+	})
+	return err
+}
+
+// InvalidatePoolCache 删除池子的事件缓存和状态，在缓存可能不连续或残留分叉数据（写入或回滚失败、补块）时调用，
+// 由后续实时事件重新建立
+func InvalidatePoolCache(ctx context.Context, redisClient redis.UniversalClient, pools []string) error {
+	if len(pools) == 0 {
+		return nil
+	}
+	_, err := redisClient.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for _, pool := range pools {
+			p.Del(ctx, poolcache.EventsKey(pool), poolcache.StateKey(pool))
+		}
+		return nil
+	})
+	return err
+}
+
+// toCachedEvent 转换为查询接口返回的格式（token 地址解码），不包含 create_at
+func toCachedEvent(e *model.ChainEvent) *pb.ChainEvent {
+	return &pb.ChainEvent{
+		EventIdHash: uint32(e.EventIDHash),
+		EventId:     uint64(e.EventID),
+		EventType:   uint32(e.EventType),
+		Dex:         uint32(e.Dex),
+		UserWallet:  e.UserWallet,
+		ToWallet:    e.ToWallet,
+		PoolAddress: e.PoolAddress,
+		Token:       utils.DecodeTokenAddress(e.Token),
+		QuoteToken:  utils.DecodeTokenAddress(e.QuoteToken),
+		TokenAmount: utils.ParseUint64(e.TokenAmount),
+		QuoteAmount: utils.ParseUint64(e.QuoteAmount),
+		VolumeUsd:   e.VolumeUsd,
+		PriceUsd:    e.PriceUsd,
+		TxHash:      e.TxHash,
+		Signer:      e.Signer,
+		BlockTime:   uint32(e.BlockTime),
+	}
 }
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/logger"
+	"fmt"
+)
+
+const checkpointColumns = "router,topic,partition_id,kafka_offset,max_slot,update_at"
+
+// StartMarkerPartition 记录已执行的回放起始位置的特殊分区号，不对应真实分区
+const StartMarkerPartition = -1
+
+// UpsertCheckpoint 写入分区 checkpoint，需在该次 flush 的数据全部写入成功后调用
+func UpsertCheckpoint(ctx context.Context, dbConn *sql.DB, cp *model.Checkpoint) error {
+	query := "INSERT INTO ingest_checkpoint(" + checkpointColumns + ") VALUES" + genPlaceholders(6) +
+		upsertClause(checkpointColumns, "router", "topic", "partition_id")
+
+	err := db.RetryWithBackoff(ctx, func() error {
+		_, execErr := dbConn.ExecContext(ctx, query,
+			cp.Router, cp.Topic, cp.Partition, cp.Offset, cp.MaxSlot, cp.UpdateAt)
+		if execErr != nil {
+			logger.Warnf("retrying ingest_checkpoint upsert: %v", execErr)
+		}
+		return execErr
+	})
+	if err != nil {
+		return fmt.Errorf("upsert ingest_checkpoint failed after retries: %w (partition: %d, offset: %d)",
+			err, cp.Partition, cp.Offset)
+	}
+	return nil
+}
+
+// LoadCheckpoints 读取 router + topic 下所有分区的 checkpoint
+func LoadCheckpoints(ctx context.Context, dbConn *sql.DB, router, topic string) ([]*model.Checkpoint, error) {
+	rows, err := dbConn.QueryContext(ctx,
+		"SELECT "+checkpointColumns+" FROM ingest_checkpoint WHERE router = ? AND topic = ?", router, topic)
+	if err != nil {
+		return nil, fmt.Errorf("query ingest_checkpoint failed: %w", err)
+	}
+	defer rows.Close()
+
+	var checkpoints []*model.Checkpoint
+	for rows.Next() {
+		cp := &model.Checkpoint{}
+		if err := rows.Scan(&cp.Router, &cp.Topic, &cp.Partition, &cp.Offset, &cp.MaxSlot, &cp.UpdateAt); err != nil {
+			return nil, fmt.Errorf("scan ingest_checkpoint failed: %w", err)
+		}
+		checkpoints = append(checkpoints, cp)
+	}
+	return checkpoints, rows.Err()
+}
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/logger"
+	"dex-ingest-sol/internal/pkg/utils"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	holderStatsBatchSize        = 1000
+	holderStatsUpsertFieldCount = 4
+)
+
+const holderStatsColumns = "token,holder_count,reconciled_at,update_at"
+
+var holderStatsValuePlaceholder = genPlaceholders(holderStatsUpsertFieldCount)
+
+// LoadHolders 查询 balance 表，返回 pairs 中当前持有该 token 的钱包（balance 表只保留非零余额）
+func LoadHolders(ctx context.Context, dbConn *sql.DB, pairs []model.HolderKey) (map[model.HolderKey]struct{}, error) {
+	owners := make(map[string][]string)
+	for _, p := range pairs {
+		owners[p.Token] = append(owners[p.Token], p.Owner)
+	}
+
+	holders := make(map[model.HolderKey]struct{}, len(pairs))
+	for token, list := range owners {
+		for i := 0; i < len(list); i += balanceBatchSize {
+			end := min(i+balanceBatchSize, len(list))
+			batch := list[i:end]
+
+			args := make([]any, 0, len(batch)+1)
+			args = append(args, token)
+			for _, owner := range batch {
+				args = append(args, owner)
+			}
+
+			placeholders := strings.Repeat("?,", len(batch))
+			placeholders = placeholders[:len(placeholders)-1] // 去掉最后一个逗号
+			query := "SELECT owner_address FROM balance WHERE token_address = ? AND owner_address IN (" + placeholders + ")"
+
+			err := db.RetryWithBackoff(ctx, func() error {
+				rows, queryErr := dbConn.QueryContext(ctx, query, args...)
+				if queryErr != nil {
+					logger.Warnf("retrying select holders (token=%s) [%d:%d]: %v", token, i, end, queryErr)
+					return queryErr
+				}
+				defer rows.Close()
+
+				var owner string
+				for rows.Next() {
+					if scanErr := rows.Scan(&owner); scanErr != nil {
+						return fmt.Errorf("scan holder error (token=%s): %w", token, scanErr)
+					}
+					holders[model.HolderKey{Owner: owner, Token: token}] = struct{}{}
+				}
+				return rows.Err()
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return holders, nil
+}
+
+// ApplyHolderDeltas 将持有人数增量累加到 token_holder_stats，计数不低于 0，返回尚未对账过的 token。
+// 尚无记录的 token 以增量作为初始值，reconciled_at 为 0，需由对账任务分段统计后修正。
+func ApplyHolderDeltas(ctx context.Context, dbConn *sql.DB, deltas map[string]int64) ([]string, error) {
+	if len(deltas) == 0 {
+		return nil, nil
+	}
+
+	tokens := make([]string, 0, len(deltas))
+	for token := range deltas {
+		tokens = append(tokens, token)
+	}
+	existing, err := LoadHolderStats(ctx, dbConn, tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	var unreconciled []string
+	updateAt := int32(time.Now().Unix())
+	stats := make([]*model.TokenHolderStats, 0, len(tokens))
+	for _, token := range tokens {
+		s, ok := existing[token]
+		if !ok {
+			s = &model.TokenHolderStats{Token: token}
+		}
+		s.HolderCount = max(0, s.HolderCount+deltas[token])
+		s.UpdateAt = updateAt
+		stats = append(stats, s)
+		if s.ReconciledAt == 0 {
+			unreconciled = append(unreconciled, token)
+		}
+	}
+	return unreconciled, UpsertHolderStats(ctx, dbConn, stats)
+}
+
+// LoadHolderOwnersPage 按 owner_address 升序读取 token 在 after 之后的最多 limit 个余额账户的钱包，用于分段统计持有人数。
+// 同一钱包的多个账户相邻返回，下一页从本页最后一个钱包之后开始。
+func LoadHolderOwnersPage(ctx context.Context, dbConn *sql.DB, token, after string, limit int) ([]string, error) {
+	const query = "SELECT owner_address FROM balance WHERE token_address = ? AND owner_address > ? ORDER BY owner_address LIMIT ?"
+	var owners []string
+	err := db.RetryWithBackoff(ctx, func() error {
+		owners = owners[:0]
+		rows, queryErr := dbConn.QueryContext(ctx, query, token, after, limit)
+		if queryErr != nil {
+			logger.Warnf("retrying select holder owners (token=%s, after=%s): %v", token, after, queryErr)
+			return queryErr
+		}
+		defer rows.Close()
+
+		var owner string
+		for rows.Next() {
+			if scanErr := rows.Scan(&owner); scanErr != nil {
+				return fmt.Errorf("scan holder owner error (token=%s): %w", token, scanErr)
+			}
+			owners = append(owners, owner)
+		}
+		return rows.Err()
+	})
+	return owners, err
+}
+
+// NextHolderToken 返回 balance 表中 after 之后的下一个 token（按 idx_balance_token_owner 逐个跳过），没有时返回空
+func NextHolderToken(ctx context.Context, dbConn *sql.DB, after string) (string, error) {
+	const query = "SELECT token_address FROM balance WHERE token_address > ? ORDER BY token_address LIMIT 1"
+	var token string
+	err := db.RetryWithBackoff(ctx, func() error {
+		token = ""
+		queryErr := dbConn.QueryRowContext(ctx, query, after).Scan(&token)
+		if errors.Is(queryErr, sql.ErrNoRows) {
+			return nil
+		}
+		if queryErr != nil {
+			logger.Warnf("retrying select next holder token (after=%s): %v", after, queryErr)
+		}
+		return queryErr
+	})
+	return token, err
+}
+
+// LoadHolderStats 批量读取 token_holder_stats，不存在的 token 不在结果中。
+// 表中的 token 按 EncodeTokenAddress 编码（与查询服务一致），参数和结果均为 balance 表中的地址
+func LoadHolderStats(ctx context.Context, dbConn *sql.DB, tokens []string) (map[string]*model.TokenHolderStats, error) {
+	existing := make(map[string]*model.TokenHolderStats, len(tokens))
+	for i := 0; i < len(tokens); i += holderStatsBatchSize {
+		end := min(i+holderStatsBatchSize, len(tokens))
+		batch := tokens[i:end]
+
+		args := make([]any, len(batch))
+		for j, token := range batch {
+			args[j] = utils.EncodeTokenAddress(token)
+		}
+
+		placeholders := strings.Repeat("?,", len(batch))
+		placeholders = placeholders[:len(placeholders)-1] // 去掉最后一个逗号
+		query := "SELECT " + holderStatsColumns + " FROM token_holder_stats WHERE token IN (" + placeholders + ")"
+
+		err := db.RetryWithBackoff(ctx, func() error {
+			rows, queryErr := dbConn.QueryContext(ctx, query, args...)
+			if queryErr != nil {
+				logger.Warnf("retrying select token_holder_stats [%d:%d]: %v", i, end, queryErr)
+				return queryErr
+			}
+			defer rows.Close()
+
+			for rows.Next() {
+				s := &model.TokenHolderStats{}
+				if scanErr := rows.Scan(&s.Token, &s.HolderCount, &s.ReconciledAt, &s.UpdateAt); scanErr != nil {
+					return fmt.Errorf("scan error in [%d:%d]: %w", i, end, scanErr)
+				}
+				s.Token = utils.DecodeTokenAddress(s.Token)
+				existing[s.Token] = s
+			}
+			return rows.Err()
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return existing, nil
+}
+
+// UpsertHolderStats 覆盖写入持有人数，token 编码方式同 LoadHolderStats
+func UpsertHolderStats(ctx context.Context, dbConn *sql.DB, stats []*model.TokenHolderStats) error {
+	for i := 0; i < len(stats); i += holderStatsBatchSize {
+		end := min(i+holderStatsBatchSize, len(stats))
+		batch := stats[i:end]
+
+		var builder strings.Builder
+		builder.Grow(256 + len(batch)*(len(holderStatsValuePlaceholder)+1))
+		builder.WriteString("INSERT INTO token_holder_stats(" + holderStatsColumns + ") VALUES")
+
+		args := make([]any, 0, len(batch)*holderStatsUpsertFieldCount)
+		for j, s := range batch {
+			if j > 0 {
+				builder.WriteByte(',')
+			}
+			builder.WriteString(holderStatsValuePlaceholder)
+			args = append(args, utils.EncodeTokenAddress(s.Token), s.HolderCount, s.ReconciledAt, s.UpdateAt)
+		}
+
+		builder.WriteString(upsertClause(holderStatsColumns, "token"))
+
+		query := builder.String()
+		retryRange := fmt.Sprintf("[%d:%d]", i, end)
+		err := db.RetryWithBackoff(ctx, func() error {
+			_, execErr := dbConn.ExecContext(ctx, query, args...)
+			if execErr != nil {
+				logger.Warnf("retrying token_holder_stats upsert %s: %v", retryRange, execErr)
+			}
+			return execErr
+		})
+		if err != nil {
+			return fmt.Errorf("upsert token_holder_stats %s failed after retries: %w (first token: %s)", retryRange, err, batch[0].Token)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/utils"
+	"dex-ingest-sol/pb"
+	"github.com/hashicorp/golang-lru"
+)
+
+func BuildMigrationModels(events *pb.Events, cache *lru.Cache) []*model.Migration {
+	var result []*model.Migration
+	for _, e := range events.Events {
+		event, ok := e.Event.(*pb.Event_Migrate)
+		if !ok || event.Migrate == nil {
+			continue
+		}
+		result = append(result, buildMigrationModel(event.Migrate, cache))
+	}
+	return result
+}
+
+func buildMigrationModel(event *pb.MigrateEvent, cache *lru.Cache) *model.Migration {
+	return &model.Migration{
+		EventIDHash: utils.EventIdHash(event.EventId),
+		EventID:     int64(event.EventId),
+
+		Token:           utils.EncodeBase58Strict(cache, event.Token),
+		SrcPoolAddress:  utils.EncodeBase58Strict(cache, event.SrcPairAddress),
+		DestPoolAddress: utils.EncodeBase58Strict(cache, event.DestPairAddress),
+		SrcDex:          int16(event.SrcDex),
+		DestDex:         int16(event.DestDex),
+		SrcQuoteToken:   utils.EncodeBase58Strict(cache, event.SrcQuoteToken),
+		DestQuoteToken:  utils.EncodeBase58Strict(cache, event.DestQuoteToken),
+
+		TokenAmount:   utils.Uint64ToString(event.TokenAmount),
+		QuoteAmount:   utils.Uint64ToString(event.QuoteTokenAmount),
+		MigrationFee:  utils.Uint64ToString(event.MigrationFee),
+		TokenDecimals: int16(event.TokenDecimals),
+		QuoteDecimals: int16(event.QuoteDecimals),
+
+		Creator:    utils.EncodeBase58Optional(cache, event.DestPoolCreator),
+		UserWallet: utils.EncodeBase58Strict(cache, event.UserWallet),
+
+		TxHash: utils.TxHashToString(event.TxHash),
+		Signer: utils.EncodeBase58Optional(cache, utils.SelectSigner(event.Signers, event.UserWallet)),
+
+		BlockTime: int32(event.BlockTime),
+		CreateAt:  0,
+	}
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/logger"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+const (
+	migrationBatchSize        = 500
+	migrationUpsertFieldCount = 20
+)
+
+const migrationColumns = "event_id_hash,event_id," +
+	"token,src_pool_address,dest_pool_address,src_dex,dest_dex,src_quote_token,dest_quote_token," +
+	"token_amount,quote_amount,migration_fee,token_decimals,quote_decimals," +
+	"creator,user_wallet,tx_hash,signer,block_time,create_at"
+
+var migrationValuePlaceholder = genPlaceholders(migrationUpsertFieldCount)
+
+// InsertMigrations 写入 migration 表（迁移事件量很小，串行写入即可）
+func InsertMigrations(ctx context.Context, dbConn *sql.DB, migrations []*model.Migration) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("InsertMigrations panic: %v\n%s", r, debug.Stack())
+			err = fmt.Errorf("InsertMigrations panic: %v", r)
+		}
+	}()
+
+	estimatedSqlLengthPerRow := len(migrationValuePlaceholder) + 32
+	total := len(migrations)
+
+	for i := 0; i < total; i += migrationBatchSize {
+		end := i + migrationBatchSize
+		if end > total {
+			end = total
+		}
+		batch := migrations[i:end]
+
+		var builder strings.Builder
+		builder.Grow(512 + len(batch)*estimatedSqlLengthPerRow)
+
+		builder.WriteString("INSERT INTO migration(" + migrationColumns + ") VALUES")
+
+		args := make([]any, 0, len(batch)*migrationUpsertFieldCount)
+		createAt := int32(time.Now().Unix())
+		for j, m := range batch {
+			if j > 0 {
+				builder.WriteByte(',')
+			}
+			builder.WriteString(migrationValuePlaceholder)
+			args = append(args,
+				m.EventIDHash, m.EventID,
+				m.Token, m.SrcPoolAddress, m.DestPoolAddress, m.SrcDex, m.DestDex, m.SrcQuoteToken, m.DestQuoteToken,
+				m.TokenAmount, m.QuoteAmount, m.MigrationFee, m.TokenDecimals, m.QuoteDecimals,
+				m.Creator, m.UserWallet, m.TxHash, m.Signer, m.BlockTime, createAt,
+			)
+		}
+
+		builder.WriteString(upsertClause(migrationColumns, "event_id_hash", "event_id"))
+
+		query := builder.String()
+		retryRange := fmt.Sprintf("[%d:%d]", i, end)
+
+		err = db.RetryWithBackoff(ctx, func() error {
+			_, execErr := dbConn.ExecContext(ctx, query, args...)
+			if execErr != nil {
+				logger.Warnf("retrying migration insert %s: %v", retryRange, execErr)
+			}
+			return execErr
+		})
+		if err != nil {
+			return fmt.Errorf("insert migration %s failed after retries: %w", retryRange, err)
+		}
+	}
+	return nil
+}
@@ -17,6 +17,8 @@ func BuildPoolModels(events *pb.Events, tokenCache *lru.Cache, poolCache *PoolCa
 			p = buildPoolModel(inner.Trade, tokenCache, poolCache)
 		case *pb.Event_Liquidity:
 			p = buildPoolModelFromLiquidity(inner.Liquidity, tokenCache, poolCache)
+		case *pb.Event_Migrate:
+			p = buildPoolModelFromMigrate(inner.Migrate, tokenCache, poolCache)
 
 		default:
 			continue
@@ -64,6 +66,25 @@ func buildPoolModelFromLiquidity(event *pb.LiquidityEvent, tokenCache *lru.Cache
 	}
 }
 
+// buildPoolModelFromMigrate 迁移事件创建目标池（如 Pump.fun 内盘毕业到 Pump.fun AMM）
+func buildPoolModelFromMigrate(event *pb.MigrateEvent, tokenCache *lru.Cache, poolCache *PoolCache) *model.Pool {
+	accountKey, exists := poolCache.CheckAndInsert(event.DestPairAddress, event.DestTokenAccount, event.DestQuoteTokenAccount, event.DestDex)
+	if exists {
+		return nil
+	}
+	return &model.Pool{
+		PoolAddress:  utils.EncodeBase58Strict(tokenCache, event.DestPairAddress),
+		AccountKey:   accountKey,
+		Dex:          int16(event.DestDex),
+		TokenAddress: utils.EncodeBase58Strict(tokenCache, event.Token),
+		QuoteAddress: utils.EncodeBase58Strict(tokenCache, event.DestQuoteToken),
+		TokenAccount: utils.EncodeBase58Strict(tokenCache, event.DestTokenAccount),
+		QuoteAccount: utils.EncodeBase58Strict(tokenCache, event.DestQuoteTokenAccount),
+		CreateAt:     int32(event.BlockTime),
+		UpdateAt:     int32(event.BlockTime),
+	}
+}
+
 func ifThen(cond bool, val int32) int32 {
 	if cond {
 		return val
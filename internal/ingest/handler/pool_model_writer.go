@@ -20,6 +20,11 @@ const (
 	poolFieldCount8 = 8 // 不包含 create_at
 )
 
+const (
+	poolColumns9 = "pool_address,account_key,dex,token_address,quote_address,token_account,quote_account,create_at,update_at"
+	poolColumns8 = "pool_address,account_key,dex,token_address,quote_address,token_account,quote_account,update_at"
+)
+
 var (
 	poolPlaceholders9 = genPlaceholders(poolFieldCount9)
 	poolPlaceholders8 = genPlaceholders(poolFieldCount8)
@@ -91,9 +96,7 @@ func insertPoolsSerial(ctx context.Context, dbConn *sql.DB, pools []*model.Pool,
 
 		var args []any
 		if fullField {
-			builder.WriteString("INSERT INTO pool(" +
-				"pool_address,account_key,dex,token_address,quote_address," +
-				"token_account,quote_account,create_at,update_at) VALUES")
+			builder.WriteString("INSERT INTO pool(" + poolColumns9 + ") VALUES")
 			args = make([]any, 0, len(batch)*poolFieldCount9)
 
 			for j, p := range batch {
@@ -107,9 +110,7 @@ func insertPoolsSerial(ctx context.Context, dbConn *sql.DB, pools []*model.Pool,
 				)
 			}
 		} else {
-			builder.WriteString("INSERT INTO pool(" +
-				"pool_address,account_key,dex,token_address,quote_address," +
-				"token_account,quote_account,update_at) VALUES")
+			builder.WriteString("INSERT INTO pool(" + poolColumns8 + ") VALUES")
 			args = make([]any, 0, len(batch)*poolFieldCount8)
 
 			for j, p := range batch {
@@ -124,6 +125,13 @@ func insertPoolsSerial(ctx context.Context, dbConn *sql.DB, pools []*model.Pool,
 			}
 		}
 
+		// 不带 create_at 的写入只更新其余列，保留已有的 create_at
+		if fullField {
+			builder.WriteString(upsertClause(poolColumns9, "pool_address", "account_key"))
+		} else {
+			builder.WriteString(upsertClause(poolColumns8, "pool_address", "account_key"))
+		}
+
 		query := builder.String()
 		retryRange := fmt.Sprintf("[%d:%d]", startIndex+i, startIndex+end)
 
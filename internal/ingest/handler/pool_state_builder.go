@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/utils"
+	"dex-ingest-sol/pb"
+	"github.com/hashicorp/golang-lru"
+)
+
+// BuildPoolStateModels 从交易和流动性事件中提取池子储备量，同一池子只保留 event_id 最大的一条
+func BuildPoolStateModels(events *pb.Events, cache *lru.Cache) []*model.PoolState {
+	var quotePrices map[string]float64
+	if len(events.QuotePrices) > 0 {
+		quotePrices = make(map[string]float64, len(events.QuotePrices))
+		for _, p := range events.QuotePrices {
+			if p != nil && len(p.Token) > 0 {
+				quotePrices[utils.EncodeBase58Strict(cache, p.Token)] = p.Price
+			}
+		}
+	}
+
+	var result []*model.PoolState
+	index := make(map[string]int)
+	for _, e := range events.Events {
+		var state *model.PoolState
+		switch ev := e.Event.(type) {
+		case *pb.Event_Trade:
+			if ev.Trade != nil && len(ev.Trade.PairAddress) > 0 {
+				state = buildTradePoolState(ev.Trade, cache, quotePrices)
+			}
+		case *pb.Event_Liquidity:
+			if ev.Liquidity != nil && len(ev.Liquidity.PairAddress) > 0 {
+				state = buildLiquidityPoolState(ev.Liquidity, cache, quotePrices)
+			}
+		}
+		if state == nil {
+			continue
+		}
+
+		i, ok := index[state.PoolAddress]
+		if !ok {
+			index[state.PoolAddress] = len(result)
+			result = append(result, state)
+			continue
+		}
+		if state.LastEventID > result[i].LastEventID {
+			MergePoolState(state, result[i])
+			result[i] = state
+		} else {
+			MergePoolState(result[i], state)
+		}
+	}
+	return result
+}
+
+func buildTradePoolState(event *pb.TradeEvent, cache *lru.Cache, quotePrices map[string]float64) *model.PoolState {
+	s := &model.PoolState{
+		PoolAddress:   utils.EncodeBase58Strict(cache, event.PairAddress),
+		TokenReserve:  utils.Uint64ToString(event.PairTokenBalance),
+		QuoteReserve:  utils.Uint64ToString(event.PairQuoteBalance),
+		TokenDecimals: int16(event.TokenDecimals),
+		QuoteDecimals: int16(event.QuoteDecimals),
+		PriceUsd:      event.PriceUsd,
+		QuotePriceUsd: quotePrices[utils.EncodeBase58Strict(cache, event.QuoteToken)],
+		LastEventID:   int64(event.EventId),
+	}
+	if event.PriceUsd > 0 {
+		s.LastTradeTime = int32(event.BlockTime)
+	}
+	s.LiquidityUsd = poolLiquidityUsd(s)
+	return s
+}
+
+func buildLiquidityPoolState(event *pb.LiquidityEvent, cache *lru.Cache, quotePrices map[string]float64) *model.PoolState {
+	s := &model.PoolState{
+		PoolAddress:   utils.EncodeBase58Strict(cache, event.PairAddress),
+		TokenReserve:  utils.Uint64ToString(event.PairTokenBalance),
+		QuoteReserve:  utils.Uint64ToString(event.PairQuoteBalance),
+		TokenDecimals: int16(event.TokenDecimals),
+		QuoteDecimals: int16(event.QuoteDecimals),
+		QuotePriceUsd: quotePrices[utils.EncodeBase58Strict(cache, event.QuoteToken)],
+		LastEventID:   int64(event.EventId),
+	}
+	s.LiquidityUsd = poolLiquidityUsd(s)
+	return s
+}
+
+// MergePoolState 用较早的状态 prev 补齐较新状态 s 中缺失的价格和交易时间，并重新计算流动性
+func MergePoolState(s, prev *model.PoolState) {
+	if s.PriceUsd <= 0 {
+		s.PriceUsd = prev.PriceUsd
+	}
+	if s.QuotePriceUsd <= 0 {
+		s.QuotePriceUsd = prev.QuotePriceUsd
+	}
+	if s.LastTradeTime == 0 {
+		s.LastTradeTime = prev.LastTradeTime
+	}
+	s.LiquidityUsd = poolLiquidityUsd(s)
+}
+
+// poolLiquidityUsd 估算池子流动性：两侧储备按各自 USD 价格折算后相加，
+// quote 不是报价币（无价格）时按 base 侧价值的两倍估算
+func poolLiquidityUsd(s *model.PoolState) float64 {
+	if s.PriceUsd <= 0 {
+		return 0
+	}
+	baseValue := float64(utils.ParseUint64(s.TokenReserve)) / utils.Pow10(uint32(s.TokenDecimals)) * s.PriceUsd
+	if s.QuotePriceUsd <= 0 {
+		return baseValue * 2
+	}
+	return baseValue + float64(utils.ParseUint64(s.QuoteReserve))/utils.Pow10(uint32(s.QuoteDecimals))*s.QuotePriceUsd
+}
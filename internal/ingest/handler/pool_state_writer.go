@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/logger"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	poolStateBatchSize        = 1000
+	poolStateUpsertFieldCount = 11
+)
+
+const poolStateColumns = "pool_address,token_reserve,quote_reserve,token_decimals,quote_decimals," +
+	"price_usd,quote_price_usd,liquidity_usd,last_event_id,last_trade_time,update_at"
+
+var poolStateValuePlaceholder = genPlaceholders(poolStateUpsertFieldCount)
+
+// poolStateMu 串行化本进程内各分区对 pool_state 的读改写，避免较旧的状态覆盖较新的状态
+var poolStateMu sync.Mutex
+
+// UpsertPoolStates 与 balance 一样按 last_event_id 单调更新：只写入比库中更新的状态，
+// 并用库中已有的价格 / 交易时间补齐流动性事件缺失的字段。调用方需保证同一批次内池子不重复。
+func UpsertPoolStates(ctx context.Context, dbConn *sql.DB, states []*model.PoolState) error {
+	if len(states) == 0 {
+		return nil
+	}
+
+	poolStateMu.Lock()
+	defer poolStateMu.Unlock()
+
+	existing, err := loadPoolStates(ctx, dbConn, states)
+	if err != nil {
+		return err
+	}
+
+	updateAt := int32(time.Now().Unix())
+	toUpsert := make([]*model.PoolState, 0, len(states))
+	for _, s := range states {
+		if old, ok := existing[s.PoolAddress]; ok {
+			if s.LastEventID <= old.LastEventID {
+				continue
+			}
+			MergePoolState(s, old)
+		}
+		s.UpdateAt = updateAt
+		toUpsert = append(toUpsert, s)
+	}
+	return writePoolStates(ctx, dbConn, toUpsert)
+}
+
+func loadPoolStates(ctx context.Context, dbConn *sql.DB, states []*model.PoolState) (map[string]*model.PoolState, error) {
+	existing := make(map[string]*model.PoolState, len(states))
+	for i := 0; i < len(states); i += poolStateBatchSize {
+		end := min(i+poolStateBatchSize, len(states))
+		batch := states[i:end]
+
+		args := make([]any, len(batch))
+		for j, s := range batch {
+			args[j] = s.PoolAddress
+		}
+
+		placeholders := strings.Repeat("?,", len(batch))
+		placeholders = placeholders[:len(placeholders)-1] // 去掉最后一个逗号
+		query := "SELECT " + poolStateColumns + " FROM pool_state WHERE pool_address IN (" + placeholders + ")"
+
+		err := db.RetryWithBackoff(ctx, func() error {
+			rows, queryErr := dbConn.QueryContext(ctx, query, args...)
+			if queryErr != nil {
+				logger.Warnf("retrying select pool_state [%d:%d]: %v", i, end, queryErr)
+				return queryErr
+			}
+			defer rows.Close()
+
+			for rows.Next() {
+				s := &model.PoolState{}
+				if scanErr := rows.Scan(
+					&s.PoolAddress, &s.TokenReserve, &s.QuoteReserve, &s.TokenDecimals, &s.QuoteDecimals,
+					&s.PriceUsd, &s.QuotePriceUsd, &s.LiquidityUsd, &s.LastEventID, &s.LastTradeTime, &s.UpdateAt,
+				); scanErr != nil {
+					return fmt.Errorf("scan error in [%d:%d]: %w", i, end, scanErr)
+				}
+				existing[s.PoolAddress] = s
+			}
+			return rows.Err()
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return existing, nil
+}
+
+func writePoolStates(ctx context.Context, dbConn *sql.DB, states []*model.PoolState) error {
+	for i := 0; i < len(states); i += poolStateBatchSize {
+		end := min(i+poolStateBatchSize, len(states))
+		batch := states[i:end]
+
+		var builder strings.Builder
+		builder.Grow(256 + len(batch)*(len(poolStateValuePlaceholder)+1))
+		builder.WriteString("INSERT INTO pool_state(" + poolStateColumns + ") VALUES")
+
+		args := make([]any, 0, len(batch)*poolStateUpsertFieldCount)
+		for j, s := range batch {
+			if j > 0 {
+				builder.WriteByte(',')
+			}
+			builder.WriteString(poolStateValuePlaceholder)
+			args = append(args,
+				s.PoolAddress, s.TokenReserve, s.QuoteReserve, s.TokenDecimals, s.QuoteDecimals,
+				s.PriceUsd, s.QuotePriceUsd, s.LiquidityUsd, s.LastEventID, s.LastTradeTime, s.UpdateAt,
+			)
+		}
+
+		builder.WriteString(upsertClause(poolStateColumns, "pool_address"))
+
+		query := builder.String()
+		retryRange := fmt.Sprintf("[%d:%d]", i, end)
+		err := db.RetryWithBackoff(ctx, func() error {
+			_, execErr := dbConn.ExecContext(ctx, query, args...)
+			if execErr != nil {
+				logger.Warnf("retrying pool_state upsert %s: %v", retryRange, execErr)
+			}
+			return execErr
+		})
+		if err != nil {
+			return fmt.Errorf("upsert pool_state %s failed after retries: %w (first pool: %s)", retryRange, err, batch[0].PoolAddress)
+		}
+	}
+	return nil
+}
+
+// DeletePoolStatesByPools 删除被回滚区块写入的池子状态，只删除 last_event_id 仍属于该 slot 的记录，
+// 之后由该池子的下一笔交易或流动性事件重新写入。
+func DeletePoolStatesByPools(ctx context.Context, dbConn *sql.DB, pools []string, slot uint64) error {
+	minEventID := int64(slot << 32)
+	maxEventID := int64((slot + 1) << 32)
+
+	for i := 0; i < len(pools); i += poolStateBatchSize {
+		end := min(i+poolStateBatchSize, len(pools))
+		batch := pools[i:end]
+
+		args := make([]any, 0, len(batch)+2)
+		for _, p := range batch {
+			args = append(args, p)
+		}
+		args = append(args, minEventID, maxEventID)
+
+		placeholders := strings.Repeat("?,", len(batch))
+		placeholders = placeholders[:len(placeholders)-1] // 去掉最后一个逗号
+		query := "DELETE FROM pool_state WHERE pool_address IN (" + placeholders + ") AND last_event_id >= ? AND last_event_id < ?"
+
+		retryRange := fmt.Sprintf("[%d:%d]", i, end)
+		err := db.RetryWithBackoff(ctx, func() error {
+			_, execErr := dbConn.ExecContext(ctx, query, args...)
+			if execErr != nil {
+				logger.Warnf("retrying pool_state rollback delete %s: %v", retryRange, execErr)
+			}
+			return execErr
+		})
+		if err != nil {
+			return fmt.Errorf("rollback pool_state %s failed after retries: %w (first pool: %s)", retryRange, err, batch[0])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/utils"
+	"dex-ingest-sol/pb"
+	"github.com/hashicorp/golang-lru"
+)
+
+// BuildQuotePriceModels 提取消息中的报价币价格，同一 token 只保留最后一条
+func BuildQuotePriceModels(events *pb.Events, cache *lru.Cache, blockTime int64) []*model.QuotePrice {
+	if len(events.QuotePrices) == 0 {
+		return nil
+	}
+
+	result := make([]*model.QuotePrice, 0, len(events.QuotePrices))
+	index := make(map[string]int, len(events.QuotePrices))
+	for _, p := range events.QuotePrices {
+		if p == nil || len(p.Token) == 0 || p.Price <= 0 {
+			continue
+		}
+		qp := &model.QuotePrice{
+			Token:     utils.EncodeTokenAddress(utils.EncodeBase58Strict(cache, p.Token)),
+			Slot:      int64(events.Slot),
+			BlockTime: int32(blockTime),
+			Price:     p.Price,
+			Decimals:  int16(p.Decimals),
+		}
+		if i, ok := index[qp.Token]; ok {
+			result[i] = qp
+			continue
+		}
+		index[qp.Token] = len(result)
+		result = append(result, qp)
+	}
+	return result
+}
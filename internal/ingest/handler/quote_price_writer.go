@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/logger"
+	"fmt"
+	"strings"
+)
+
+const (
+	quotePriceColumns          = "token,slot,block_time,price,decimals"
+	quotePriceUpsertFieldCount = 5
+)
+
+var quotePriceValuePlaceholder = genPlaceholders(quotePriceUpsertFieldCount)
+
+// InsertQuotePrices 写入报价币价格，(token, slot) 重复时覆盖。
+// 调用方需保证同一批次内 (token, slot) 不重复。
+func InsertQuotePrices(ctx context.Context, dbConn *sql.DB, prices []*model.QuotePrice) error {
+	if len(prices) == 0 {
+		return nil
+	}
+
+	var builder strings.Builder
+	builder.Grow(128 + len(prices)*(len(quotePriceValuePlaceholder)+1))
+	builder.WriteString("INSERT INTO quote_price(" + quotePriceColumns + ") VALUES")
+
+	args := make([]any, 0, len(prices)*quotePriceUpsertFieldCount)
+	for i, p := range prices {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteString(quotePriceValuePlaceholder)
+		args = append(args, p.Token, p.Slot, p.BlockTime, p.Price, p.Decimals)
+	}
+
+	builder.WriteString(upsertClause(quotePriceColumns, "token", "slot"))
+
+	query := builder.String()
+	err := db.RetryWithBackoff(ctx, func() error {
+		_, execErr := dbConn.ExecContext(ctx, query, args...)
+		if execErr != nil {
+			logger.Warnf("retrying quote_price insert: %v", execErr)
+		}
+		return execErr
+	})
+	if err != nil {
+		return fmt.Errorf("insert quote_price failed after retries: %w (first slot: %d)", err, prices[0].Slot)
+	}
+	return nil
+}
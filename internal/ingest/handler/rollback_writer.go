@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/logger"
+	"fmt"
+	"strings"
+)
+
+// 分叉回滚涉及的事件类表，主键均为 (event_id_hash, event_id)
+const (
+	TableChainEvent    = "chain_event"
+	TableTransferEvent = "transfer_event"
+	TableMigration     = "migration"
+)
+
+// DeleteEventsByKeys 按主键删除被回滚区块写入的事件行。
+// event_id_hash 由 event_id 计算得到，按 event_id_hash IN 和 event_id IN 分批删除只会命中给定的主键。
+func DeleteEventsByKeys(ctx context.Context, dbConn *sql.DB, table string, keys []model.EventKey) error {
+	switch table {
+	case TableChainEvent, TableTransferEvent, TableMigration:
+	default:
+		return fmt.Errorf("rollback not supported for table %s", table)
+	}
+
+	for i := 0; i < len(keys); i += inQueryBatchSize {
+		end := min(i+inQueryBatchSize, len(keys))
+		batch := keys[i:end]
+
+		hashes := make(map[int32]struct{}, len(batch))
+		args := make([]any, 0, len(batch)*2)
+		for _, k := range batch {
+			if _, ok := hashes[k.EventIDHash]; !ok {
+				hashes[k.EventIDHash] = struct{}{}
+				args = append(args, k.EventIDHash)
+			}
+		}
+		for _, k := range batch {
+			args = append(args, k.EventID)
+		}
+
+		hashPlaceholders := strings.Repeat("?,", len(hashes))
+		hashPlaceholders = hashPlaceholders[:len(hashPlaceholders)-1] // 去掉最后一个逗号
+		idPlaceholders := strings.Repeat("?,", len(batch))
+		idPlaceholders = idPlaceholders[:len(idPlaceholders)-1]
+		query := "DELETE FROM " + table + " WHERE event_id_hash IN (" + hashPlaceholders + ") AND event_id IN (" + idPlaceholders + ")"
+
+		retryRange := fmt.Sprintf("[%d:%d]", i, end)
+		err := db.RetryWithBackoff(ctx, func() error {
+			_, execErr := dbConn.ExecContext(ctx, query, args...)
+			if execErr != nil {
+				logger.Warnf("retrying %s rollback delete %s: %v", table, retryRange, execErr)
+			}
+			return execErr
+		})
+		if err != nil {
+			return fmt.Errorf("delete %s %s failed after retries: %w (first event_id: %d)", table, retryRange, err, batch[0].EventID)
+		}
+	}
+	return nil
+}
+
+// RestoreBalancesByAccounts 回滚被分叉区块写入的余额：按 balance_history 中该 slot 之外最近一次变更的 post_balance 还原。
+// 该 slot 之后已有变更的账户余额保持不变；没有历史记录的账户（balance_history 启用前的数据）只能删除该 slot 写入的余额行，
+// 由新区块或该账户后续的余额事件重新写入。需在删除该 slot 的 balance_history 之前或之后调用均可（查询排除了该 slot）。
+func RestoreBalancesByAccounts(ctx context.Context, dbConn *sql.DB, accounts []string, slot uint64) error {
+	minEventID := int64(slot << 32)
+	maxEventID := int64((slot + 1) << 32)
+
+	// 该 slot 之后已有变更：当前余额不属于被回滚的区块
+	later, err := latestHistoryEventIDs(ctx, dbConn, accounts, "event_id >= ?", maxEventID)
+	if err != nil {
+		return err
+	}
+	candidates := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		if _, ok := later[account]; !ok {
+			candidates = append(candidates, account)
+		}
+	}
+
+	prevIDs, err := latestHistoryEventIDs(ctx, dbConn, candidates, "event_id < ?", minEventID)
+	if err != nil {
+		return err
+	}
+	prev, err := balanceHistoryAt(ctx, dbConn, prevIDs)
+	if err != nil {
+		return err
+	}
+
+	var (
+		toRestore []*model.Balance
+		toDelete  []*model.Balance
+		noHistory []string
+	)
+	for _, account := range candidates {
+		b, ok := prev[account]
+		switch {
+		case !ok:
+			noHistory = append(noHistory, account)
+		case b.Balance == "0":
+			toDelete = append(toDelete, b)
+		default:
+			toRestore = append(toRestore, b)
+		}
+	}
+
+	if err := upsertBalancesRealtime(ctx, dbConn, toRestore); err != nil {
+		return err
+	}
+	if err := deleteBalancesRealtime(ctx, dbConn, toDelete); err != nil {
+		return err
+	}
+	if len(toRestore)+len(toDelete) > 0 {
+		logger.Infof("slot %d rollback: restored %d balances, deleted %d zero balances from balance_history",
+			slot, len(toRestore), len(toDelete))
+	}
+	return DeleteBalancesByAccounts(ctx, dbConn, noHistory, slot)
+}
+
+// latestHistoryEventIDs 分批查询各账户满足条件的最近一次余额变更的 event_id，没有记录的账户不在结果中
+func latestHistoryEventIDs(ctx context.Context, dbConn *sql.DB, accounts []string, cond string, eventID int64) (map[string]int64, error) {
+	type latest struct {
+		account string
+		eventID int64
+	}
+	queryFmt := "SELECT account_address, MAX(event_id) FROM balance_history WHERE account_address IN (%s) AND " +
+		cond + " GROUP BY account_address"
+	rows, err := queryInBatches(ctx, dbConn, accounts, queryFmt, []any{eventID}, func(rows *sql.Rows) (latest, error) {
+		var l latest
+		err := rows.Scan(&l.account, &l.eventID)
+		return l, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("select latest balance_history failed after retries: %w", err)
+	}
+
+	result := make(map[string]int64, len(rows))
+	for _, l := range rows {
+		result[l.account] = l.eventID
+	}
+	return result, nil
+}
+
+// balanceHistoryAt 分批读取指定 (account, event_id) 的余额变更，转换为该变更后的余额。
+// 用 account IN 和 event_id IN 查询后按 (account, event_id) 过滤。
+func balanceHistoryAt(ctx context.Context, dbConn *sql.DB, eventIDs map[string]int64) (map[string]*model.Balance, error) {
+	accounts := make([]string, 0, len(eventIDs))
+	for account := range eventIDs {
+		accounts = append(accounts, account)
+	}
+
+	result := make(map[string]*model.Balance, len(eventIDs))
+	for i := 0; i < len(accounts); i += inQueryBatchSize {
+		end := min(i+inQueryBatchSize, len(accounts))
+		batch := accounts[i:end]
+
+		args := make([]any, 0, len(batch)*2)
+		for _, account := range batch {
+			args = append(args, account)
+		}
+		for _, account := range batch {
+			args = append(args, eventIDs[account])
+		}
+		placeholders := strings.Repeat("?,", len(batch))
+		placeholders = placeholders[:len(placeholders)-1] // 去掉最后一个逗号
+		query := "SELECT account_address, owner_address, token_address, post_balance, event_id FROM balance_history " +
+			"WHERE account_address IN (" + placeholders + ") AND event_id IN (" + placeholders + ")"
+
+		retryRange := fmt.Sprintf("[%d:%d]", i, end)
+		var items []*model.Balance
+		err := db.RetryWithBackoff(ctx, func() error {
+			items = items[:0] // 重试时丢弃上一次读到的部分结果
+			rows, queryErr := dbConn.QueryContext(ctx, query, args...)
+			if queryErr != nil {
+				logger.Warnf("retrying select balance_history %s: %v", retryRange, queryErr)
+				return queryErr
+			}
+			defer rows.Close()
+
+			for rows.Next() {
+				b := &model.Balance{}
+				if scanErr := rows.Scan(&b.AccountAddress, &b.OwnerAddress, &b.TokenAddress, &b.Balance, &b.LastEventID); scanErr != nil {
+					return scanErr
+				}
+				items = append(items, b)
+			}
+			return rows.Err()
+		})
+		if err != nil {
+			return nil, fmt.Errorf("select balance_history %s failed after retries: %w (first account: %s)", retryRange, err, batch[0])
+		}
+		for _, b := range items {
+			if eventIDs[b.AccountAddress] == b.LastEventID {
+				result[b.AccountAddress] = b
+			}
+		}
+	}
+	return result, nil
+}
+
+// DeleteBalancesByAccounts 删除被回滚区块写入的余额行，只删除 last_event_id 仍属于该 slot 的记录，
+// 已被后续 slot 覆盖的余额保持不变。用于无法从 balance_history 还原的账户。
+func DeleteBalancesByAccounts(ctx context.Context, dbConn *sql.DB, accounts []string, slot uint64) error {
+	minEventID := int64(slot << 32)
+	maxEventID := int64((slot + 1) << 32)
+
+	for i := 0; i < len(accounts); i += balanceBatchSize {
+		end := min(i+balanceBatchSize, len(accounts))
+		batch := accounts[i:end]
+
+		args := make([]any, 0, len(batch)+2)
+		for _, addr := range batch {
+			args = append(args, addr)
+		}
+		args = append(args, minEventID, maxEventID)
+
+		placeholders := strings.Repeat("?,", len(batch))
+		placeholders = placeholders[:len(placeholders)-1] // 去掉最后一个逗号
+		query := "DELETE FROM balance WHERE account_address IN (" + placeholders + ") AND last_event_id >= ? AND last_event_id < ?"
+
+		retryRange := fmt.Sprintf("[%d:%d]", i, end)
+		err := db.RetryWithBackoff(ctx, func() error {
+			_, execErr := dbConn.ExecContext(ctx, query, args...)
+			if execErr != nil {
+				logger.Warnf("retrying balance rollback delete %s: %v", retryRange, execErr)
+			}
+			return execErr
+		})
+		if err != nil {
+			return fmt.Errorf("rollback balance %s failed after retries: %w (first account: %s)", retryRange, err, batch[0])
+		}
+	}
+	return nil
+}
+
+// DeleteBalanceHistoryByAccounts 删除被回滚区块写入的余额变更记录（event_id 属于该 slot 的行）
+func DeleteBalanceHistoryByAccounts(ctx context.Context, dbConn *sql.DB, accounts []string, slot uint64) error {
+	minEventID := int64(slot << 32)
+	maxEventID := int64((slot + 1) << 32)
+
+	for i := 0; i < len(accounts); i += balanceHistoryBatchSize {
+		end := min(i+balanceHistoryBatchSize, len(accounts))
+		batch := accounts[i:end]
+
+		args := make([]any, 0, len(batch)+2)
+		for _, addr := range batch {
+			args = append(args, addr)
+		}
+		args = append(args, minEventID, maxEventID)
+
+		placeholders := strings.Repeat("?,", len(batch))
+		placeholders = placeholders[:len(placeholders)-1] // 去掉最后一个逗号
+		query := "DELETE FROM balance_history WHERE account_address IN (" + placeholders + ") AND event_id >= ? AND event_id < ?"
+
+		retryRange := fmt.Sprintf("[%d:%d]", i, end)
+		err := db.RetryWithBackoff(ctx, func() error {
+			_, execErr := dbConn.ExecContext(ctx, query, args...)
+			if execErr != nil {
+				logger.Warnf("retrying balance_history rollback delete %s: %v", retryRange, execErr)
+			}
+			return execErr
+		})
+		if err != nil {
+			return fmt.Errorf("rollback balance_history %s failed after retries: %w (first account: %s)", retryRange, err, batch[0])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/logger"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	slotBlockColumns          = "router,slot,block_hash,source,rollback_keys,update_at"
+	slotBlockUpsertFieldCount = 6
+)
+
+const slotBlockBatchSize = 500
+
+var slotBlockValuePlaceholder = genPlaceholders(slotBlockUpsertFieldCount)
+
+// InsertSlotBlocks 写入 slot → block_hash 及写入主键（每个 slot 一行，单条 SQL 即可）
+func InsertSlotBlocks(ctx context.Context, dbConn *sql.DB, blocks []*model.SlotBlock) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	var builder strings.Builder
+	builder.Grow(128 + len(blocks)*(len(slotBlockValuePlaceholder)+1))
+	builder.WriteString("INSERT INTO slot_block(" + slotBlockColumns + ") VALUES")
+
+	args := make([]any, 0, len(blocks)*slotBlockUpsertFieldCount)
+	updateAt := int32(time.Now().Unix())
+	for i, b := range blocks {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteString(slotBlockValuePlaceholder)
+		args = append(args, b.Router, b.Slot, b.BlockHash, b.Source, b.RollbackKeys, updateAt)
+	}
+
+	builder.WriteString(upsertClause(slotBlockColumns, "router", "slot"))
+
+	query := builder.String()
+	err := db.RetryWithBackoff(ctx, func() error {
+		_, execErr := dbConn.ExecContext(ctx, query, args...)
+		if execErr != nil {
+			logger.Warnf("retrying slot_block insert: %v", execErr)
+		}
+		return execErr
+	})
+	if err != nil {
+		return fmt.Errorf("insert slot_block failed after retries: %w (first slot: %d)", err, blocks[0].Slot)
+	}
+	return nil
+}
+
+// LoadSlotBlocks 批量读取 router 下指定 slot 的区块记录，不存在的 slot 不在结果中
+func LoadSlotBlocks(ctx context.Context, dbConn *sql.DB, router string, slots []uint64) (map[uint64]*model.SlotBlock, error) {
+	blocks := make(map[uint64]*model.SlotBlock, len(slots))
+	for i := 0; i < len(slots); i += slotBlockBatchSize {
+		end := min(i+slotBlockBatchSize, len(slots))
+		batch := slots[i:end]
+
+		args := make([]any, 0, len(batch)+1)
+		args = append(args, router)
+		for _, slot := range batch {
+			args = append(args, int64(slot))
+		}
+
+		placeholders := strings.Repeat("?,", len(batch))
+		placeholders = placeholders[:len(placeholders)-1] // 去掉最后一个逗号
+		query := "SELECT slot, block_hash, source, rollback_keys FROM slot_block WHERE router = ? AND slot IN (" + placeholders + ")"
+
+		err := db.RetryWithBackoff(ctx, func() error {
+			rows, queryErr := dbConn.QueryContext(ctx, query, args...)
+			if queryErr != nil {
+				logger.Warnf("retrying select slot_block [%d:%d]: %v", i, end, queryErr)
+				return queryErr
+			}
+			defer rows.Close()
+
+			for rows.Next() {
+				b := &model.SlotBlock{Router: router}
+				if scanErr := rows.Scan(&b.Slot, &b.BlockHash, &b.Source, &b.RollbackKeys); scanErr != nil {
+					return fmt.Errorf("scan slot_block error in [%d:%d]: %w", i, end, scanErr)
+				}
+				blocks[uint64(b.Slot)] = b
+			}
+			return rows.Err()
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return blocks, nil
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+)
+
+const tokenCacheSize = 100000
+
+// TokenKey 表示缓存中的唯一键：32字节 token mint 地址
+type TokenKey [32]byte
+
+// TokenCache 是一个 LRU 缓存，value 表示该 token 是否已写入过完整的创建信息
+type TokenCache struct {
+	cache *simplelru.LRU[TokenKey, bool]
+}
+
+// NewTokenCache 初始化 TokenCache
+func NewTokenCache() *TokenCache {
+	c, err := simplelru.NewLRU[TokenKey, bool](tokenCacheSize, nil)
+	if err != nil {
+		panic(fmt.Sprintf("failed to create LRU cache: %v", err))
+	}
+	return &TokenCache{cache: c}
+}
+
+// CheckAndInsert 检查 token 是否已处理过：
+//   - 创建事件（isCreating=true）只跳过已写过创建信息的 token，允许覆盖之前仅含 decimals 的记录
+//   - 补全事件（isCreating=false）只要出现过就跳过
+func (tc *TokenCache) CheckAndInsert(token []byte, isCreating bool) (exists bool) {
+	var key TokenKey
+	copy(key[:], token)
+
+	created, ok := tc.cache.Get(key)
+	if ok && (created || !isCreating) {
+		return true
+	}
+	tc.cache.Add(key, isCreating)
+	return false
+}
@@ -8,7 +8,7 @@ import (
 	"github.com/hashicorp/golang-lru"
 )
 
-func BuildTokenModels(events *pb.Events, cache *lru.Cache) []*model.Token {
+func BuildTokenModels(events *pb.Events, cache *lru.Cache, tokenCache *TokenCache) []*model.Token {
 	const (
 		maxNameLen   = 128
 		maxSymbolLen = 64
@@ -33,6 +33,9 @@ func BuildTokenModels(events *pb.Events, cache *lru.Cache) []*model.Token {
 			if ev.Liquidity == nil || len(ev.Liquidity.Token) == 0 {
 				continue
 			}
+			if tokenCache.CheckAndInsert(ev.Liquidity.Token, false) {
+				continue
+			}
 			result = append(result, &model.Token{
 				TokenAddress: utils.EncodeBase58Strict(cache, ev.Liquidity.Token),
 				Decimals:     int16(ev.Liquidity.TokenDecimals),
@@ -52,6 +55,9 @@ func BuildTokenModels(events *pb.Events, cache *lru.Cache) []*model.Token {
 			if t == nil || len(t.Token) == 0 {
 				continue
 			}
+			if tokenCache.CheckAndInsert(t.Token, true) {
+				continue
+			}
 
 			tokenStr := utils.EncodeBase58Strict(cache, t.Token)
 			creator := ""
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/db"
 	"dex-ingest-sol/internal/pkg/logger"
 	"errors"
 	"fmt"
@@ -18,9 +19,11 @@ const (
 	tokenUpsertFieldCount = 10
 )
 
+const tokenColumns = "token_address,decimals,source,total_supply,name,symbol,uri,creator,create_at,update_at"
+
 var tokenValuePlaceholder = genPlaceholders(tokenUpsertFieldCount)
 
-func InsertTokens(ctx context.Context, db *sql.DB, tokens []*model.Token) error {
+func InsertTokens(ctx context.Context, dbConn *sql.DB, tokens []*model.Token) error {
 	insertList, updateList := splitTokensForInsertAndUpdate(tokens)
 	if len(insertList) == 0 && len(updateList) == 0 {
 		return nil
@@ -33,7 +36,7 @@ func InsertTokens(ctx context.Context, db *sql.DB, tokens []*model.Token) error
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			errInsert = execTokenBatch(ctx, db, insertList, false)
+			errInsert = execTokenBatch(ctx, dbConn, insertList, false)
 		}()
 	}
 
@@ -41,7 +44,7 @@ func InsertTokens(ctx context.Context, db *sql.DB, tokens []*model.Token) error
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			errUpdate = execTokenBatch(ctx, db, updateList, true)
+			errUpdate = execTokenBatch(ctx, dbConn, updateList, true)
 		}()
 	}
 
@@ -103,7 +106,7 @@ func splitTokensForInsertAndUpdate(tokens []*model.Token) (insertList []*model.T
 	return insertList, updateList
 }
 
-func execTokenBatch(ctx context.Context, db *sql.DB, tokens []*model.Token, isCreating bool) (err error) {
+func execTokenBatch(ctx context.Context, dbConn *sql.DB, tokens []*model.Token, isCreating bool) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			logger.Errorf("execTokenBatch panic: %v\n%s", r, debug.Stack())
@@ -121,8 +124,7 @@ func execTokenBatch(ctx context.Context, db *sql.DB, tokens []*model.Token, isCr
 
 		var builder strings.Builder
 		builder.Grow(512 + len(batch)*estimatedRowSQLSize)
-		builder.WriteString("INSERT INTO token(" +
-			"token_address,decimals,source,total_supply,name,symbol,uri,creator,create_at,update_at) VALUES")
+		builder.WriteString("INSERT INTO token(" + tokenColumns + ") VALUES")
 
 		args := make([]any, 0, len(batch)*tokenUpsertFieldCount)
 		for j, t := range batch {
@@ -137,17 +139,29 @@ func execTokenBatch(ctx context.Context, db *sql.DB, tokens []*model.Token, isCr
 			)
 		}
 
-		if !isCreating {
-			builder.WriteString(" ON DUPLICATE KEY IGNORE")
+		if isCreating {
+			builder.WriteString(upsertClause(tokenColumns, "token_address"))
+		} else {
+			builder.WriteString(db.IgnoreClause(dialect))
+		}
+
+		action := "insert"
+		if isCreating {
+			action = "create"
 		}
 
 		query := builder.String()
-		if _, err := db.ExecContext(ctx, query, args...); err != nil {
-			action := "insert"
-			if isCreating {
-				action = "create"
+		retryRange := fmt.Sprintf("[%d:%d]", i, end)
+
+		err = db.RetryWithBackoff(ctx, func() error {
+			_, execErr := dbConn.ExecContext(ctx, query, args...)
+			if execErr != nil {
+				logger.Warnf("retrying token %s %s: %v", action, retryRange, execErr)
 			}
-			return fmt.Errorf("%s token [%d:%d] failed: %w", action, i, end, err)
+			return execErr
+		})
+		if err != nil {
+			return fmt.Errorf("%s token %s failed after retries: %w", action, retryRange, err)
 		}
 	}
 	return nil
@@ -0,0 +1,451 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/logger"
+	"dex-ingest-sol/pb"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	tokenStatsBatchSize         = 1000
+	tokenStatsUpsertFieldCount  = 12
+	tokenMinuteUpsertFieldCount = 10
+	tokenTraderUpsertFieldCount = 4
+)
+
+// TokenStatsBucketSec token 分钟交易汇总的时间粒度
+const TokenStatsBucketSec = 60
+
+const tokenStatsColumns = "token,window_sec,volume_usd,trade_count,buy_count,sell_count,unique_traders," +
+	"price_open,price_close,price_change,tracked_since,update_at"
+
+const tokenMinuteColumns = "token,minute_time,volume_usd,buy_count,sell_count," +
+	"open_price,open_event_id,close_price,close_event_id,update_at"
+
+const tokenTraderColumns = "token,trader,last_trade_time,update_at"
+
+var (
+	tokenStatsValuePlaceholder  = genPlaceholders(tokenStatsUpsertFieldCount)
+	tokenMinuteValuePlaceholder = genPlaceholders(tokenMinuteUpsertFieldCount)
+	tokenTraderValuePlaceholder = genPlaceholders(tokenTraderUpsertFieldCount)
+)
+
+// TokenMinuteKey 标识一个 token 一分钟的交易汇总
+type TokenMinuteKey struct {
+	Token      string
+	MinuteTime int32
+}
+
+func isTokenStatsTrade(e *model.ChainEvent) bool {
+	if e.EventType != int16(pb.EventType_TRADE_BUY) && e.EventType != int16(pb.EventType_TRADE_SELL) {
+		return false
+	}
+	return e.Token != "" && e.BlockTime > 0
+}
+
+// TouchedTokenMinutes 返回买卖事件所在的 (token, 分钟)，按首次出现顺序去重
+func TouchedTokenMinutes(events []*model.ChainEvent) []TokenMinuteKey {
+	var result []TokenMinuteKey
+	seen := make(map[TokenMinuteKey]struct{})
+	for _, e := range events {
+		if !isTokenStatsTrade(e) {
+			continue
+		}
+		key := TokenMinuteKey{Token: e.Token, MinuteTime: e.BlockTime - e.BlockTime%TokenStatsBucketSec}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		result = append(result, key)
+	}
+	return result
+}
+
+// TradeTokens 返回买卖事件涉及的 token（去重），随分叉回滚主键记录
+func TradeTokens(events []*model.ChainEvent) []string {
+	var tokens []string
+	seen := make(map[string]struct{})
+	for _, e := range events {
+		if !isTokenStatsTrade(e) {
+			continue
+		}
+		if _, ok := seen[e.Token]; ok {
+			continue
+		}
+		seen[e.Token] = struct{}{}
+		tokens = append(tokens, e.Token)
+	}
+	return tokens
+}
+
+// TokenMinutesAt 返回各 token 在 blockTime 所在的分钟，用于分叉回滚后按旧区块时间重算
+func TokenMinutesAt(tokens []string, blockTime int64) []TokenMinuteKey {
+	if blockTime <= 0 {
+		return nil
+	}
+	minute := int32(blockTime) - int32(blockTime)%TokenStatsBucketSec
+	result := make([]TokenMinuteKey, 0, len(tokens))
+	for _, t := range tokens {
+		result = append(result, TokenMinuteKey{Token: t, MinuteTime: minute})
+	}
+	return result
+}
+
+// RecomputeTokenMinutes 按已写入的 chain_event 重算 token 分钟交易汇总，并更新交易地址的最近交易时间。
+// 结果只取决于库中的数据，重复投递、flush 重试、多实例消费和回放都不会重复计数；已没有成交的分钟汇总会被删除。
+// 返回每个 key 的重算结果，没有成交时为 nil。
+func RecomputeTokenMinutes(ctx context.Context, dbConn *sql.DB, keys []TokenMinuteKey) (map[TokenMinuteKey]*model.TokenMinute, error) {
+	const queryFmt = "SELECT event_id, event_type, token, user_wallet, volume_usd, price_usd, block_time " +
+		"FROM chain_event WHERE token IN (%s) AND block_time >= ? AND block_time < ?"
+
+	groups := make(map[int32][]string)
+	for _, k := range keys {
+		groups[k.MinuteTime] = append(groups[k.MinuteTime], k.Token)
+	}
+
+	updateAt := int32(time.Now().Unix())
+	minutes := make(map[TokenMinuteKey]*model.TokenMinute, len(keys))
+	traders := make(map[[2]string]*model.TokenTrader)
+	for minuteTime, tokens := range groups {
+		events, err := queryInBatches(ctx, dbConn, tokens, queryFmt, []any{minuteTime, minuteTime + TokenStatsBucketSec},
+			func(rows *sql.Rows) (*model.ChainEvent, error) {
+				e := &model.ChainEvent{}
+				if err := rows.Scan(&e.EventID, &e.EventType, &e.Token, &e.UserWallet, &e.VolumeUsd, &e.PriceUsd, &e.BlockTime); err != nil {
+					return nil, fmt.Errorf("scan chain_event error: %w", err)
+				}
+				return e, nil
+			})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range events {
+			if !isTokenStatsTrade(e) {
+				continue
+			}
+			key := TokenMinuteKey{Token: e.Token, MinuteTime: minuteTime}
+			m := minutes[key]
+			if m == nil {
+				m = &model.TokenMinute{Token: e.Token, MinuteTime: minuteTime, UpdateAt: updateAt}
+				minutes[key] = m
+			}
+			addTokenMinuteTrade(m, e)
+
+			if e.UserWallet != "" {
+				tk := [2]string{e.Token, e.UserWallet}
+				if t := traders[tk]; t == nil || t.LastTradeTime < e.BlockTime {
+					traders[tk] = &model.TokenTrader{Token: e.Token, Trader: e.UserWallet, LastTradeTime: e.BlockTime, UpdateAt: updateAt}
+				}
+			}
+		}
+	}
+
+	upserts := make([]*model.TokenMinute, 0, len(minutes))
+	var deletes []TokenMinuteKey
+	for _, k := range keys {
+		if m := minutes[k]; m != nil {
+			upserts = append(upserts, m)
+		} else {
+			deletes = append(deletes, k)
+			minutes[k] = nil
+		}
+	}
+	if err := upsertTokenMinutes(ctx, dbConn, upserts); err != nil {
+		return nil, err
+	}
+	if err := deleteTokenMinutes(ctx, dbConn, deletes); err != nil {
+		return nil, err
+	}
+
+	traderList := make([]*model.TokenTrader, 0, len(traders))
+	for _, t := range traders {
+		traderList = append(traderList, t)
+	}
+	if err := mergeTokenTraders(ctx, dbConn, traderList); err != nil {
+		return nil, err
+	}
+	return minutes, nil
+}
+
+func addTokenMinuteTrade(m *model.TokenMinute, e *model.ChainEvent) {
+	m.VolumeUsd += e.VolumeUsd
+	if e.EventType == int16(pb.EventType_TRADE_BUY) {
+		m.BuyCount++
+	} else {
+		m.SellCount++
+	}
+	if e.PriceUsd > 0 {
+		if m.OpenPrice <= 0 || e.EventID < m.OpenEventID {
+			m.OpenEventID, m.OpenPrice = e.EventID, e.PriceUsd
+		}
+		if m.ClosePrice <= 0 || e.EventID > m.CloseEventID {
+			m.CloseEventID, m.ClosePrice = e.EventID, e.PriceUsd
+		}
+	}
+}
+
+func upsertTokenMinutes(ctx context.Context, dbConn *sql.DB, minutes []*model.TokenMinute) error {
+	for i := 0; i < len(minutes); i += tokenStatsBatchSize {
+		end := min(i+tokenStatsBatchSize, len(minutes))
+		batch := minutes[i:end]
+
+		var builder strings.Builder
+		builder.Grow(256 + len(batch)*(len(tokenMinuteValuePlaceholder)+1))
+		builder.WriteString("INSERT INTO token_stats_minute(" + tokenMinuteColumns + ") VALUES")
+
+		args := make([]any, 0, len(batch)*tokenMinuteUpsertFieldCount)
+		for j, m := range batch {
+			if j > 0 {
+				builder.WriteByte(',')
+			}
+			builder.WriteString(tokenMinuteValuePlaceholder)
+			args = append(args,
+				m.Token, m.MinuteTime, m.VolumeUsd, m.BuyCount, m.SellCount,
+				m.OpenPrice, m.OpenEventID, m.ClosePrice, m.CloseEventID, m.UpdateAt,
+			)
+		}
+
+		builder.WriteString(upsertClause(tokenMinuteColumns, "token", "minute_time"))
+
+		query := builder.String()
+		retryRange := fmt.Sprintf("[%d:%d]", i, end)
+		err := db.RetryWithBackoff(ctx, func() error {
+			_, execErr := dbConn.ExecContext(ctx, query, args...)
+			if execErr != nil {
+				logger.Warnf("retrying token_stats_minute upsert %s: %v", retryRange, execErr)
+			}
+			return execErr
+		})
+		if err != nil {
+			return fmt.Errorf("upsert token_stats_minute %s failed after retries: %w (first token: %s)", retryRange, err, batch[0].Token)
+		}
+	}
+	return nil
+}
+
+// deleteTokenMinutes 删除已没有成交的分钟汇总（只在分叉回滚后出现），逐行按主键删除
+func deleteTokenMinutes(ctx context.Context, dbConn *sql.DB, keys []TokenMinuteKey) error {
+	const query = "DELETE FROM token_stats_minute WHERE token = ? AND minute_time = ?"
+	for _, k := range keys {
+		err := db.RetryWithBackoff(ctx, func() error {
+			_, execErr := dbConn.ExecContext(ctx, query, k.Token, k.MinuteTime)
+			if execErr != nil {
+				logger.Warnf("retrying token_stats_minute delete (token=%s, minute=%d): %v", k.Token, k.MinuteTime, execErr)
+			}
+			return execErr
+		})
+		if err != nil {
+			return fmt.Errorf("delete token_stats_minute (token=%s, minute=%d) failed after retries: %w", k.Token, k.MinuteTime, err)
+		}
+	}
+	return nil
+}
+
+// mergeTokenTraders 按较大值合并交易地址的最近交易时间：只写入比库中更新的记录，重复写入结果不变。
+// 多实例同时合并同一地址时较新的时间可能被覆盖，该地址下一次交易时修正。
+func mergeTokenTraders(ctx context.Context, dbConn *sql.DB, traders []*model.TokenTrader) error {
+	const queryFmt = "SELECT trader, last_trade_time FROM token_trader WHERE trader IN (%s) AND token = ?"
+
+	byToken := make(map[string][]*model.TokenTrader)
+	for _, t := range traders {
+		byToken[t.Token] = append(byToken[t.Token], t)
+	}
+
+	var newer []*model.TokenTrader
+	for token, list := range byToken {
+		wallets := make([]string, 0, len(list))
+		for _, t := range list {
+			wallets = append(wallets, t.Trader)
+		}
+		existing, err := queryInBatches(ctx, dbConn, wallets, queryFmt, []any{token},
+			func(rows *sql.Rows) (*model.TokenTrader, error) {
+				t := &model.TokenTrader{Token: token}
+				if err := rows.Scan(&t.Trader, &t.LastTradeTime); err != nil {
+					return nil, fmt.Errorf("scan token_trader error: %w", err)
+				}
+				return t, nil
+			})
+		if err != nil {
+			return err
+		}
+		last := make(map[string]int32, len(existing))
+		for _, t := range existing {
+			last[t.Trader] = t.LastTradeTime
+		}
+		for _, t := range list {
+			if t.LastTradeTime > last[t.Trader] {
+				newer = append(newer, t)
+			}
+		}
+	}
+	return upsertTokenTraders(ctx, dbConn, newer)
+}
+
+func upsertTokenTraders(ctx context.Context, dbConn *sql.DB, traders []*model.TokenTrader) error {
+	for i := 0; i < len(traders); i += tokenStatsBatchSize {
+		end := min(i+tokenStatsBatchSize, len(traders))
+		batch := traders[i:end]
+
+		var builder strings.Builder
+		builder.Grow(128 + len(batch)*(len(tokenTraderValuePlaceholder)+1))
+		builder.WriteString("INSERT INTO token_trader(" + tokenTraderColumns + ") VALUES")
+
+		args := make([]any, 0, len(batch)*tokenTraderUpsertFieldCount)
+		for j, t := range batch {
+			if j > 0 {
+				builder.WriteByte(',')
+			}
+			builder.WriteString(tokenTraderValuePlaceholder)
+			args = append(args, t.Token, t.Trader, t.LastTradeTime, t.UpdateAt)
+		}
+
+		builder.WriteString(upsertClause(tokenTraderColumns, "token", "trader"))
+
+		query := builder.String()
+		retryRange := fmt.Sprintf("[%d:%d]", i, end)
+		err := db.RetryWithBackoff(ctx, func() error {
+			_, execErr := dbConn.ExecContext(ctx, query, args...)
+			if execErr != nil {
+				logger.Warnf("retrying token_trader upsert %s: %v", retryRange, execErr)
+			}
+			return execErr
+		})
+		if err != nil {
+			return fmt.Errorf("upsert token_trader %s failed after retries: %w (first token: %s)", retryRange, err, batch[0].Token)
+		}
+	}
+	return nil
+}
+
+func scanTokenMinute(rows *sql.Rows) (*model.TokenMinute, error) {
+	m := &model.TokenMinute{}
+	if err := rows.Scan(&m.Token, &m.MinuteTime, &m.VolumeUsd, &m.BuyCount, &m.SellCount,
+		&m.OpenPrice, &m.OpenEventID, &m.ClosePrice, &m.CloseEventID, &m.UpdateAt); err != nil {
+		return nil, fmt.Errorf("scan token_stats_minute error: %w", err)
+	}
+	return m, nil
+}
+
+// LoadTokenMinutes 查询 token 在 minute_time > after 的分钟汇总
+func LoadTokenMinutes(ctx context.Context, dbConn *sql.DB, tokens []string, after int32) ([]*model.TokenMinute, error) {
+	const queryFmt = "SELECT " + tokenMinuteColumns + " FROM token_stats_minute WHERE token IN (%s) AND minute_time > ?"
+	return queryInBatches(ctx, dbConn, tokens, queryFmt, []any{after}, scanTokenMinute)
+}
+
+// LoadRecentTokenMinutes 查询全部 token 在 minute_time > after 的分钟汇总（启动时加载）
+func LoadRecentTokenMinutes(ctx context.Context, dbConn *sql.DB, after int32) ([]*model.TokenMinute, error) {
+	query := "SELECT " + tokenMinuteColumns + " FROM token_stats_minute WHERE minute_time > ?"
+
+	var minutes []*model.TokenMinute
+	err := db.RetryWithBackoff(ctx, func() error {
+		minutes = minutes[:0]
+		rows, queryErr := dbConn.QueryContext(ctx, query, after)
+		if queryErr != nil {
+			logger.Warnf("retrying select recent token_stats_minute: %v", queryErr)
+			return queryErr
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			m, scanErr := scanTokenMinute(rows)
+			if scanErr != nil {
+				return scanErr
+			}
+			minutes = append(minutes, m)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("select recent token_stats_minute failed after retries: %w", err)
+	}
+	return minutes, nil
+}
+
+// LoadTokenTraderTimes 查询 token 在 last_trade_time > after 的交易地址最近交易时间
+func LoadTokenTraderTimes(ctx context.Context, dbConn *sql.DB, tokens []string, after int32) (map[string][]int32, error) {
+	const queryFmt = "SELECT token, last_trade_time FROM token_trader WHERE token IN (%s) AND last_trade_time > ?"
+	traders, err := queryInBatches(ctx, dbConn, tokens, queryFmt, []any{after},
+		func(rows *sql.Rows) (*model.TokenTrader, error) {
+			t := &model.TokenTrader{}
+			if err := rows.Scan(&t.Token, &t.LastTradeTime); err != nil {
+				return nil, fmt.Errorf("scan token_trader error: %w", err)
+			}
+			return t, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string][]int32, len(tokens))
+	for _, t := range traders {
+		result[t.Token] = append(result[t.Token], t.LastTradeTime)
+	}
+	return result, nil
+}
+
+// PruneTokenStats 删除 token 已移出最大窗口（<= before）的分钟汇总和交易地址
+func PruneTokenStats(ctx context.Context, dbConn *sql.DB, tokens []string, before int32) error {
+	for _, query := range []string{
+		"DELETE FROM token_stats_minute WHERE token = ? AND minute_time <= ?",
+		"DELETE FROM token_trader WHERE token = ? AND last_trade_time <= ?",
+	} {
+		for _, token := range tokens {
+			err := db.RetryWithBackoff(ctx, func() error {
+				_, execErr := dbConn.ExecContext(ctx, query, token, before)
+				if execErr != nil {
+					logger.Warnf("retrying token stats prune (token=%s): %v", token, execErr)
+				}
+				return execErr
+			})
+			if err != nil {
+				return fmt.Errorf("prune token stats (token=%s) failed after retries: %w", token, err)
+			}
+		}
+	}
+	return nil
+}
+
+// UpsertTokenStats 覆盖写入 token 滚动窗口统计
+func UpsertTokenStats(ctx context.Context, dbConn *sql.DB, stats []*model.TokenStats) error {
+	for i := 0; i < len(stats); i += tokenStatsBatchSize {
+		end := min(i+tokenStatsBatchSize, len(stats))
+		batch := stats[i:end]
+
+		var builder strings.Builder
+		builder.Grow(256 + len(batch)*(len(tokenStatsValuePlaceholder)+1))
+		builder.WriteString("INSERT INTO token_stats(" + tokenStatsColumns + ") VALUES")
+
+		args := make([]any, 0, len(batch)*tokenStatsUpsertFieldCount)
+		for j, s := range batch {
+			if j > 0 {
+				builder.WriteByte(',')
+			}
+			builder.WriteString(tokenStatsValuePlaceholder)
+			args = append(args,
+				s.Token, s.WindowSec, s.VolumeUsd, s.TradeCount, s.BuyCount, s.SellCount, s.UniqueTraders,
+				s.PriceOpen, s.PriceClose, s.PriceChange, s.TrackedSince, s.UpdateAt,
+			)
+		}
+
+		builder.WriteString(upsertClause(tokenStatsColumns, "token", "window_sec"))
+
+		query := builder.String()
+		retryRange := fmt.Sprintf("[%d:%d]", i, end)
+		err := db.RetryWithBackoff(ctx, func() error {
+			_, execErr := dbConn.ExecContext(ctx, query, args...)
+			if execErr != nil {
+				logger.Warnf("retrying token_stats upsert %s: %v", retryRange, execErr)
+			}
+			return execErr
+		})
+		if err != nil {
+			return fmt.Errorf("upsert token_stats %s failed after retries: %w (first token: %s)", retryRange, err, batch[0].Token)
+		}
+	}
+	return nil
+}
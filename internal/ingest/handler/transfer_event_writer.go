@@ -18,6 +18,10 @@ const (
 	transferEventsUpsertFieldCount = 11
 )
 
+const transferEventsColumns = "event_id_hash,event_id," +
+	"from_wallet,to_wallet,token,amount,decimals," +
+	"tx_hash,signer,block_time,create_at"
+
 var transferEventsValuePlaceholder = genPlaceholders(transferEventsUpsertFieldCount)
 
 func InsertTransferEvents(ctx context.Context, dbConn *sql.DB, events []*model.TransferEvent) error {
@@ -83,10 +87,7 @@ func insertTransferEventsSerial(ctx context.Context, dbConn *sql.DB, events []*m
 		var builder strings.Builder
 		builder.Grow(512 + len(batch)*estimatedSqlLengthPerRow)
 
-		builder.WriteString("INSERT INTO transfer_event(" +
-			"event_id_hash,event_id," +
-			"from_wallet,to_wallet,token,amount,decimals," +
-			"tx_hash,signer,block_time,create_at) VALUES")
+		builder.WriteString("INSERT INTO transfer_event(" + transferEventsColumns + ") VALUES")
 
 		args := make([]any, 0, len(batch)*transferEventsUpsertFieldCount)
 		createAt := int32(time.Now().Unix())
@@ -102,6 +103,8 @@ func insertTransferEventsSerial(ctx context.Context, dbConn *sql.DB, events []*m
 			)
 		}
 
+		builder.WriteString(upsertClause(transferEventsColumns, "event_id_hash", "event_id"))
+
 		query := builder.String()
 		retryRange := fmt.Sprintf("[%d:%d sub=%d:%d]", startIndex, endIndex, i, end)
 
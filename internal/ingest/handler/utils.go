@@ -1,6 +1,45 @@
 package handler
 
-import "strings"
+import (
+	"context"
+	"database/sql"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/logger"
+	"fmt"
+	"strings"
+)
+
+// inQueryBatchSize 单条查询 IN 条件中的最大值个数
+const inQueryBatchSize = 1000
+
+// dialect 写入 SQL 方言，进程启动时由 SetDialect 设置一次
+var dialect = db.Lindorm
+
+// SetDialect 设置写入使用的 SQL 方言（lindorm / pg）
+func SetDialect(d db.DBType) {
+	dialect = d
+}
+
+// upsertClause 生成主键冲突时覆盖其余列的子句，columns 为 INSERT 的列清单（逗号分隔）
+func upsertClause(columns string, conflict ...string) string {
+	if dialect != db.PG {
+		return ""
+	}
+	update := make([]string, 0, strings.Count(columns, ",")+1)
+	for _, col := range strings.Split(columns, ",") {
+		isKey := false
+		for _, k := range conflict {
+			if col == k {
+				isKey = true
+				break
+			}
+		}
+		if !isKey {
+			update = append(update, col)
+		}
+	}
+	return db.UpsertClause(dialect, conflict, update)
+}
 
 func genPlaceholders(n int) string {
 	if n <= 0 {
@@ -8,3 +47,48 @@ func genPlaceholders(n int) string {
 	}
 	return "(" + strings.Repeat("?,", n-1) + "?)"
 }
+
+// queryInBatches 将 values 分批拼入 queryFmt 中的 IN (%s) 执行查询，tail 为 IN 之后的参数
+func queryInBatches[T any](ctx context.Context, dbConn *sql.DB, values []string, queryFmt string, tail []any,
+	scan func(rows *sql.Rows) (T, error)) ([]T, error) {
+	var result []T
+	for i := 0; i < len(values); i += inQueryBatchSize {
+		end := min(i+inQueryBatchSize, len(values))
+		batch := values[i:end]
+
+		args := make([]any, 0, len(batch)+len(tail))
+		for _, v := range batch {
+			args = append(args, v)
+		}
+		args = append(args, tail...)
+
+		placeholders := strings.Repeat("?,", len(batch))
+		placeholders = placeholders[:len(placeholders)-1] // 去掉最后一个逗号
+		query := fmt.Sprintf(queryFmt, placeholders)
+
+		var items []T
+		err := db.RetryWithBackoff(ctx, func() error {
+			items = items[:0] // 重试时丢弃上一次读到的部分结果
+			rows, queryErr := dbConn.QueryContext(ctx, query, args...)
+			if queryErr != nil {
+				logger.Warnf("retrying query %q %v: %v", queryFmt, tail, queryErr)
+				return queryErr
+			}
+			defer rows.Close()
+
+			for rows.Next() {
+				item, scanErr := scan(rows)
+				if scanErr != nil {
+					return scanErr
+				}
+				items = append(items, item)
+			}
+			return rows.Err()
+		})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, items...)
+	}
+	return result, nil
+}
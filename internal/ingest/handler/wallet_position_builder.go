@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/utils"
+	"dex-ingest-sol/pb"
+	"github.com/hashicorp/golang-lru"
+	"math/big"
+)
+
+// BuildWalletTrades 提取消息中钱包的买卖，缺少钱包、token 或成交数量的事件不参与持仓计算
+func BuildWalletTrades(events *pb.Events, cache *lru.Cache) []*model.WalletTrade {
+	var result []*model.WalletTrade
+	for _, e := range events.Events {
+		ev, ok := e.Event.(*pb.Event_Trade)
+		if !ok || ev.Trade == nil {
+			continue
+		}
+		t := ev.Trade
+		if t.Type != pb.EventType_TRADE_BUY && t.Type != pb.EventType_TRADE_SELL {
+			continue
+		}
+		if len(t.UserWallet) == 0 || len(t.Token) == 0 || t.TokenAmount == 0 {
+			continue
+		}
+		result = append(result, &model.WalletTrade{
+			EventID:     int64(t.EventId),
+			EventType:   int16(t.Type),
+			Owner:       utils.EncodeBase58Strict(cache, t.UserWallet),
+			Token:       utils.EncodeTokenAddress(utils.EncodeBase58Strict(cache, t.Token)),
+			Decimals:    int16(t.TokenDecimals),
+			TokenAmount: utils.Uint64ToString(t.TokenAmount),
+			VolumeUsd:   t.AmountUsd,
+			BlockTime:   int32(t.BlockTime),
+		})
+	}
+	return result
+}
+
+// NewWalletPosition 创建空持仓
+func NewWalletPosition(owner, token string) *model.WalletPosition {
+	return &model.WalletPosition{
+		Owner:        owner,
+		Token:        token,
+		Position:     "0",
+		BoughtAmount: "0",
+		SoldAmount:   "0",
+	}
+}
+
+// ApplyWalletTrade 按平均成本法将一笔买卖应用到持仓上，调用方需按 event_id 升序依次应用。
+// 卖出数量超过持仓时（token 通过转账等非交易方式获得），只有持仓内的部分计入已实现盈亏。
+func ApplyWalletTrade(p *model.WalletPosition, t *model.WalletTrade) {
+	amount := parseDecimal(t.TokenAmount)
+	position := parseDecimal(p.Position)
+	if t.Decimals > 0 || p.Decimals == 0 {
+		p.Decimals = t.Decimals
+	}
+	scale := utils.Pow10(uint32(p.Decimals))
+
+	if t.EventType == int16(pb.EventType_TRADE_BUY) {
+		cost := p.AvgCostUsd*decimalFloat(position)/scale + t.VolumeUsd
+		position.Add(position, amount)
+		p.AvgCostUsd = cost / (decimalFloat(position) / scale)
+		p.BoughtAmount = addDecimal(p.BoughtAmount, t.TokenAmount)
+		p.BoughtUsd += t.VolumeUsd
+		p.BuyCount++
+	} else {
+		matched := amount
+		if matched.Cmp(position) > 0 {
+			matched = new(big.Int).Set(position)
+		}
+		if matched.Sign() > 0 {
+			matchedUi := decimalFloat(matched) / scale
+			proceeds := t.VolumeUsd * decimalFloat(matched) / decimalFloat(amount)
+			p.RealizedPnl += proceeds - p.AvgCostUsd*matchedUi
+			position.Sub(position, matched)
+		}
+		if position.Sign() == 0 {
+			p.AvgCostUsd = 0
+		}
+		p.SoldAmount = addDecimal(p.SoldAmount, t.TokenAmount)
+		p.SoldUsd += t.VolumeUsd
+		p.SellCount++
+	}
+
+	p.Position = position.String()
+	p.LastEventID = t.EventID
+	p.LastTradeTime = t.BlockTime
+}
+
+func parseDecimal(s string) *big.Int {
+	x, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return new(big.Int)
+	}
+	return x
+}
+
+func decimalFloat(x *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(x).Float64()
+	return f
+}
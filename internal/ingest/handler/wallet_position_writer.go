@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/logger"
+	"dex-ingest-sol/internal/pkg/utils"
+	"dex-ingest-sol/pb"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	walletPositionBatchSize        = 1000
+	walletTradeHistoryBatchSize    = 200 // 每个持仓可能有大量历史买卖，按较小的批次查询
+	walletPositionUpsertFieldCount = 16
+)
+
+const walletPositionColumns = "owner,token,decimals,position,avg_cost_usd," +
+	"bought_amount,sold_amount,bought_usd,sold_usd,realized_pnl,buy_count,sell_count," +
+	"last_event_id,last_trade_time,update_at,create_at"
+
+var walletPositionValuePlaceholder = genPlaceholders(walletPositionUpsertFieldCount)
+
+// WalletPositionKey 持仓主键，Token 为编码后的地址（与 wallet_position 表一致）
+type WalletPositionKey struct {
+	Owner string
+	Token string
+}
+
+// walletPositionMu 串行化本进程内各分区对 wallet_position 的重算，减少同一持仓被并发覆盖
+var walletPositionMu sync.Mutex
+
+// TouchedWalletPositions 返回买卖涉及的持仓（去重，保持首次出现的顺序）
+func TouchedWalletPositions(trades []*model.WalletTrade) []WalletPositionKey {
+	var keys []WalletPositionKey
+	seen := make(map[WalletPositionKey]struct{})
+	for _, t := range trades {
+		key := WalletPositionKey{Owner: t.Owner, Token: t.Token}
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// RecomputeWalletPositions 按 chain_event 中钱包在该 token 上的全部买卖重算持仓并写回，需在事件写入（或回滚删除）之后调用。
+// 结果只取决于 chain_event，flush 重试、重放、补块和跨分区乱序到达的交易都会按 event_id 顺序完整计入，
+// 分叉回滚删除旧区块事件后重算即可撤销。chain_event 中没有买卖的持仓会被删除。
+// trades 为本次写入的买卖，用于获取 token 精度，库中已有持仓时沿用其精度。
+// 不同实例同时重算同一持仓时可能写入较早读取的结果，该持仓下一次有买卖或回滚时重算修正。
+func RecomputeWalletPositions(ctx context.Context, dbConn *sql.DB, keys []WalletPositionKey, trades []*model.WalletTrade) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	walletPositionMu.Lock()
+	defer walletPositionMu.Unlock()
+
+	existing, err := loadWalletPositions(ctx, dbConn, keys)
+	if err != nil {
+		return err
+	}
+	history, err := loadWalletTradeHistory(ctx, dbConn, keys)
+	if err != nil {
+		return err
+	}
+
+	decimals := make(map[WalletPositionKey]int16, len(keys))
+	for key, p := range existing {
+		decimals[key] = p.Decimals
+	}
+	for _, t := range trades {
+		if t.Decimals > 0 {
+			decimals[WalletPositionKey{Owner: t.Owner, Token: t.Token}] = t.Decimals
+		}
+	}
+
+	updateAt := int32(time.Now().Unix())
+	var toUpsert []*model.WalletPosition
+	var toDelete []WalletPositionKey
+	for _, key := range keys {
+		old, ok := existing[key]
+		applied := history[key]
+		if len(applied) == 0 {
+			if ok {
+				toDelete = append(toDelete, key)
+			}
+			continue
+		}
+
+		p := NewWalletPosition(key.Owner, key.Token)
+		p.Decimals = decimals[key]
+		p.CreateAt = updateAt
+		if ok {
+			p.CreateAt = old.CreateAt
+		}
+		for _, t := range applied {
+			t.Decimals = p.Decimals
+			ApplyWalletTrade(p, t)
+		}
+		p.UpdateAt = updateAt
+		toUpsert = append(toUpsert, p)
+	}
+
+	if err := writeWalletPositions(ctx, dbConn, toUpsert); err != nil {
+		return err
+	}
+	return deleteWalletPositions(ctx, dbConn, toDelete)
+}
+
+// loadWalletTradeHistory 读取持仓对应的全部买卖（按 event_id 升序），查询方式同 loadWalletPositions
+func loadWalletTradeHistory(ctx context.Context, dbConn *sql.DB, keys []WalletPositionKey) (map[WalletPositionKey][]*model.WalletTrade, error) {
+	history := make(map[WalletPositionKey][]*model.WalletTrade, len(keys))
+	for i := 0; i < len(keys); i += walletTradeHistoryBatchSize {
+		end := min(i+walletTradeHistoryBatchSize, len(keys))
+		ownerArgs, tokenArgs, wanted := walletPositionArgs(keys[i:end])
+		// chain_event 中保存未编码的 token 地址
+		for j, token := range tokenArgs {
+			tokenArgs[j] = utils.DecodeTokenAddress(token.(string))
+		}
+
+		query := "SELECT user_wallet, token, event_id, event_type, token_amount, volume_usd, block_time FROM chain_event " +
+			"WHERE user_wallet IN (" + placeholders(len(ownerArgs)) + ") AND token IN (" + placeholders(len(tokenArgs)) + ") " +
+			"AND event_type IN (?, ?)"
+		args := append(append(ownerArgs, tokenArgs...), int16(pb.EventType_TRADE_BUY), int16(pb.EventType_TRADE_SELL))
+
+		var batch map[WalletPositionKey][]*model.WalletTrade
+		err := db.RetryWithBackoff(ctx, func() error {
+			batch = make(map[WalletPositionKey][]*model.WalletTrade)
+			rows, queryErr := dbConn.QueryContext(ctx, query, args...)
+			if queryErr != nil {
+				logger.Warnf("retrying select wallet trades [%d:%d]: %v", i, end, queryErr)
+				return queryErr
+			}
+			defer rows.Close()
+
+			for rows.Next() {
+				t := &model.WalletTrade{}
+				if scanErr := rows.Scan(&t.Owner, &t.Token, &t.EventID, &t.EventType, &t.TokenAmount, &t.VolumeUsd, &t.BlockTime); scanErr != nil {
+					return fmt.Errorf("scan error in [%d:%d]: %w", i, end, scanErr)
+				}
+				// 与 BuildWalletTrades 一致，没有成交数量的事件不参与持仓计算
+				if t.TokenAmount == "" || t.TokenAmount == "0" {
+					continue
+				}
+				t.Token = utils.EncodeTokenAddress(t.Token)
+				key := WalletPositionKey{Owner: t.Owner, Token: t.Token}
+				if _, ok := wanted[key]; ok {
+					batch[key] = append(batch[key], t)
+				}
+			}
+			return rows.Err()
+		})
+		if err != nil {
+			return nil, err
+		}
+		for key, trades := range batch {
+			sort.Slice(trades, func(a, b int) bool { return trades[a].EventID < trades[b].EventID })
+			history[key] = trades
+		}
+	}
+	return history, nil
+}
+
+// walletPositionArgs 生成 owner IN / token IN 的参数（去重）及需要保留的持仓集合
+func walletPositionArgs(keys []WalletPositionKey) (ownerArgs, tokenArgs []any, wanted map[WalletPositionKey]struct{}) {
+	wanted = make(map[WalletPositionKey]struct{}, len(keys))
+	owners := make(map[string]struct{})
+	tokens := make(map[string]struct{})
+	for _, k := range keys {
+		wanted[k] = struct{}{}
+		if _, ok := owners[k.Owner]; !ok {
+			owners[k.Owner] = struct{}{}
+			ownerArgs = append(ownerArgs, k.Owner)
+		}
+		if _, ok := tokens[k.Token]; !ok {
+			tokens[k.Token] = struct{}{}
+			tokenArgs = append(tokenArgs, k.Token)
+		}
+	}
+	return ownerArgs, tokenArgs, wanted
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// loadWalletPositions 每批用 owner IN 和 token IN 查询后按 (owner, token) 过滤，避免依赖多列 IN 语法
+func loadWalletPositions(ctx context.Context, dbConn *sql.DB, keys []WalletPositionKey) (map[WalletPositionKey]*model.WalletPosition, error) {
+	positions := make(map[WalletPositionKey]*model.WalletPosition, len(keys))
+	for i := 0; i < len(keys); i += walletPositionBatchSize {
+		end := min(i+walletPositionBatchSize, len(keys))
+		ownerArgs, tokenArgs, wanted := walletPositionArgs(keys[i:end])
+
+		query := "SELECT " + walletPositionColumns + " FROM wallet_position WHERE owner IN (" +
+			placeholders(len(ownerArgs)) + ") AND token IN (" + placeholders(len(tokenArgs)) + ")"
+		args := append(ownerArgs, tokenArgs...)
+
+		err := db.RetryWithBackoff(ctx, func() error {
+			rows, queryErr := dbConn.QueryContext(ctx, query, args...)
+			if queryErr != nil {
+				logger.Warnf("retrying select wallet_position [%d:%d]: %v", i, end, queryErr)
+				return queryErr
+			}
+			defer rows.Close()
+
+			for rows.Next() {
+				p := &model.WalletPosition{}
+				if scanErr := rows.Scan(
+					&p.Owner, &p.Token, &p.Decimals, &p.Position, &p.AvgCostUsd,
+					&p.BoughtAmount, &p.SoldAmount, &p.BoughtUsd, &p.SoldUsd, &p.RealizedPnl, &p.BuyCount, &p.SellCount,
+					&p.LastEventID, &p.LastTradeTime, &p.UpdateAt, &p.CreateAt,
+				); scanErr != nil {
+					return fmt.Errorf("scan error in [%d:%d]: %w", i, end, scanErr)
+				}
+				key := WalletPositionKey{Owner: p.Owner, Token: p.Token}
+				if _, ok := wanted[key]; ok {
+					positions[key] = p
+				}
+			}
+			return rows.Err()
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return positions, nil
+}
+
+func deleteWalletPositions(ctx context.Context, dbConn *sql.DB, keys []WalletPositionKey) error {
+	const query = "DELETE FROM wallet_position WHERE owner = ? AND token = ?"
+	for _, key := range keys {
+		err := db.RetryWithBackoff(ctx, func() error {
+			_, execErr := dbConn.ExecContext(ctx, query, key.Owner, key.Token)
+			if execErr != nil {
+				logger.Warnf("retrying wallet_position delete (owner=%s, token=%s): %v", key.Owner, key.Token, execErr)
+			}
+			return execErr
+		})
+		if err != nil {
+			return fmt.Errorf("delete wallet_position (owner=%s, token=%s) failed after retries: %w", key.Owner, key.Token, err)
+		}
+	}
+	return nil
+}
+
+func writeWalletPositions(ctx context.Context, dbConn *sql.DB, positions []*model.WalletPosition) error {
+	for i := 0; i < len(positions); i += walletPositionBatchSize {
+		end := min(i+walletPositionBatchSize, len(positions))
+		batch := positions[i:end]
+
+		var builder strings.Builder
+		builder.Grow(256 + len(batch)*(len(walletPositionValuePlaceholder)+1))
+		builder.WriteString("INSERT INTO wallet_position(" + walletPositionColumns + ") VALUES")
+
+		args := make([]any, 0, len(batch)*walletPositionUpsertFieldCount)
+		for j, p := range batch {
+			if j > 0 {
+				builder.WriteByte(',')
+			}
+			builder.WriteString(walletPositionValuePlaceholder)
+			args = append(args,
+				p.Owner, p.Token, p.Decimals, p.Position, p.AvgCostUsd,
+				p.BoughtAmount, p.SoldAmount, p.BoughtUsd, p.SoldUsd, p.RealizedPnl, p.BuyCount, p.SellCount,
+				p.LastEventID, p.LastTradeTime, p.UpdateAt, p.CreateAt,
+			)
+		}
+
+		builder.WriteString(upsertClause(walletPositionColumns, "owner", "token"))
+
+		query := builder.String()
+		retryRange := fmt.Sprintf("[%d:%d]", i, end)
+		err := db.RetryWithBackoff(ctx, func() error {
+			_, execErr := dbConn.ExecContext(ctx, query, args...)
+			if execErr != nil {
+				logger.Warnf("retrying wallet_position upsert %s: %v", retryRange, execErr)
+			}
+			return execErr
+		})
+		if err != nil {
+			return fmt.Errorf("upsert wallet_position %s failed after retries: %w (first owner: %s)", retryRange, err, batch[0].Owner)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,393 @@
+package ingest
+
+import (
+	"context"
+	"database/sql"
+	"dex-ingest-sol/internal/config"
+	"dex-ingest-sol/internal/ingest/handler"
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/logger"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHolderReconcileInterval = time.Minute
+	defaultHolderReconcileBatch    = 200
+	defaultHolderCountTimeout      = 30 * time.Second
+	defaultHolderCountPageSize     = 5000
+)
+
+var errHolderCountStopped = errors.New("holder count stopped")
+
+// HolderStats 增量维护 token_holder_stats：写入余额前后分别查询余额跨过零的钱包是否持有该 token，
+// 按钱包去重后将持有人数的变化累加到计数上。flush 重试或重复消费时前后状态相同，不会重复计数；
+// 多实例并发、部分写入失败等情况造成的偏差由定时对账修正。
+//
+// 对账按 owner_address 分段读取 balance 统计持有人数，单次查询耗时有限，持有人很多的 token 也能完成。
+// 统计期间本进程写入的变化中，钱包已被扫过的部分记入 adjust，完成时与统计结果合并，因此不会被覆盖。
+type HolderStats struct {
+	db           *sql.DB
+	interval     time.Duration
+	batch        int
+	countTimeout time.Duration
+	pageSize     int
+	seed         bool
+
+	mu       sync.Mutex              // 串行化本进程内各分区的快照和计数更新，以及对账的分段读取
+	counting map[string]*holderCount // 正在对账的 token，受 mu 保护
+
+	dirtyMu sync.Mutex
+	dirty   map[string]bool // 待对账的 token → 是否从未对账（优先处理）
+
+	seedCursor string // 补齐计数已遍历到的 token，只在 run 中访问
+	seedDone   bool
+
+	stopCh   chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// holderCount 一个 token 的分段统计进度
+type holderCount struct {
+	cursor string // 已统计到的钱包（含）
+	count  int64  // 已统计的持有人数
+	adjust int64  // 统计期间已扫过的钱包的持有状态变化
+}
+
+func NewHolderStats(db *sql.DB, conf config.HolderStatsConf) *HolderStats {
+	h := &HolderStats{
+		db:           db,
+		interval:     conf.ReconcileInterval,
+		batch:        conf.ReconcileBatch,
+		countTimeout: conf.CountTimeout,
+		pageSize:     conf.CountPageSize,
+		seed:         conf.Seed,
+		counting:     make(map[string]*holderCount),
+		dirty:        make(map[string]bool),
+		stopCh:       make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	if h.interval <= 0 {
+		h.interval = defaultHolderReconcileInterval
+	}
+	if h.batch <= 0 {
+		h.batch = defaultHolderReconcileBatch
+	}
+	if h.countTimeout <= 0 {
+		h.countTimeout = defaultHolderCountTimeout
+	}
+	if h.pageSize <= 0 {
+		h.pageSize = defaultHolderCountPageSize
+	}
+	return h
+}
+
+// holderPairs 收集本次 flush 中余额跨过零（0 → 非 0 或非 0 → 0）的 (owner, token)
+func holderPairs(batches []*BlockBatch) []model.HolderKey {
+	var pairs []model.HolderKey
+	seen := make(map[model.HolderKey]struct{})
+	for _, b := range batches {
+		for _, h := range b.History {
+			if (h.PreBalance == "0") == (h.PostBalance == "0") {
+				continue
+			}
+			key := model.HolderKey{Owner: h.OwnerAddress, Token: h.TokenAddress}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			pairs = append(pairs, key)
+		}
+	}
+	return pairs
+}
+
+// Track 在 write 前后查询 pairs 的持有状态，并把持有人数的变化写入 token_holder_stats。
+// 读取写入前的状态之后任何一步失败都可能已写入部分余额，重试时前后状态相同，这些变化只能由对账补上。
+func (h *HolderStats) Track(ctx context.Context, pairs []model.HolderKey, write func() error) error {
+	if len(pairs) == 0 {
+		return write()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	before, err := handler.LoadHolders(ctx, h.db, pairs)
+	if err != nil {
+		return fmt.Errorf("load holders before write: %w", err)
+	}
+	if err = write(); err != nil {
+		h.markPairsDirty(pairs)
+		return err
+	}
+	after, err := handler.LoadHolders(ctx, h.db, pairs)
+	if err != nil {
+		h.markPairsDirty(pairs)
+		return fmt.Errorf("load holders after write: %w", err)
+	}
+
+	deltas := make(map[string]int64)
+	for _, p := range pairs {
+		_, was := before[p]
+		_, is := after[p]
+		var d int64
+		switch {
+		case is && !was:
+			d = 1
+		case was && !is:
+			d = -1
+		default:
+			continue
+		}
+		deltas[p.Token] += d
+		// 正在对账的 token，已扫过的钱包不会再被统计到
+		if c, ok := h.counting[p.Token]; ok && p.Owner <= c.cursor {
+			c.adjust += d
+		}
+	}
+	for token, d := range deltas {
+		if d == 0 {
+			delete(deltas, token)
+		}
+	}
+	unreconciled, err := handler.ApplyHolderDeltas(ctx, h.db, deltas)
+	if err != nil {
+		h.markPairsDirty(pairs)
+		return fmt.Errorf("apply holder deltas: %w", err)
+	}
+
+	tokens := make([]string, 0, len(deltas))
+	for token := range deltas {
+		tokens = append(tokens, token)
+	}
+	h.markDirty(tokens, false)
+	h.markDirty(unreconciled, true)
+	return nil
+}
+
+// markDirty 加入待对账集合，initial 表示该 token 从未对账过
+func (h *HolderStats) markDirty(tokens []string, initial bool) {
+	h.dirtyMu.Lock()
+	defer h.dirtyMu.Unlock()
+	for _, token := range tokens {
+		h.dirty[token] = h.dirty[token] || initial
+	}
+}
+
+func (h *HolderStats) markPairsDirty(pairs []model.HolderKey) {
+	tokens := make([]string, 0, len(pairs))
+	for _, p := range pairs {
+		tokens = append(tokens, p.Token)
+	}
+	h.markDirty(tokens, false)
+}
+
+// Start 兼容 go-zero Service 接口，启动定时对账
+func (h *HolderStats) Start() {
+	go h.run()
+}
+
+// Stop 停止定时对账，正在进行的对账在当前 token 完成后退出
+func (h *HolderStats) Stop() {
+	h.stopOnce.Do(func() {
+		close(h.stopCh)
+		<-h.done
+	})
+}
+
+func (h *HolderStats) run() {
+	defer close(h.done)
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.stopCh:
+			return
+		case <-ticker.C:
+			if h.seed && !h.seedDone {
+				h.seedTokens()
+			}
+			h.reconcile()
+		}
+	}
+}
+
+// reconcile 对近期有变化的 token 分段统计持有人数并覆盖计数，未对账过的 token 优先。
+// 其它实例在统计期间写入的增量可能被覆盖，偏差在该 token 下次变化后的对账中修正。
+func (h *HolderStats) reconcile() {
+	tokens := h.takeDirty()
+	if len(tokens) == 0 {
+		return
+	}
+
+	start := time.Now()
+	var drifted, failed int
+	for i, t := range tokens {
+		changed, err := h.reconcileToken(t.token)
+		if errors.Is(err, errHolderCountStopped) {
+			for _, rest := range tokens[i:] {
+				h.markDirty([]string{rest.token}, rest.initial)
+			}
+			return
+		}
+		if err != nil {
+			logger.Warnf("reconcile token_holder_stats for token %s failed: %v", t.token, err)
+			h.markDirty([]string{t.token}, t.initial)
+			failed++
+			continue
+		}
+		if changed {
+			drifted++
+		}
+	}
+	logger.Infof("token_holder_stats reconciled %d tokens in %s (drifted: %d, failed: %d)",
+		len(tokens), time.Since(start), drifted, failed)
+}
+
+// reconcileToken 按 owner_address 分段统计持有人数并覆盖计数，返回计数是否与库中不一致
+func (h *HolderStats) reconcileToken(token string) (bool, error) {
+	c := &holderCount{}
+	h.mu.Lock()
+	h.counting[token] = c
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.counting, token)
+		h.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-h.stopCh:
+			return false, errHolderCountStopped
+		default:
+		}
+
+		done, changed, err := h.countPage(token, c)
+		if err != nil || done {
+			return changed, err
+		}
+	}
+}
+
+// countPage 统计下一段钱包，最后一段时写入计数。持有 mu 期间没有余额写入，cursor 与 Track 看到的一致。
+func (h *HolderStats) countPage(token string, c *holderCount) (done, changed bool, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.countTimeout)
+	owners, err := handler.LoadHolderOwnersPage(ctx, h.db, token, c.cursor, h.pageSize)
+	cancel()
+	if err != nil {
+		return false, false, err
+	}
+	for _, owner := range owners {
+		// 同一钱包的多个账户相邻返回，只计一次；下一页从 cursor 之后开始，不会重复
+		if owner != c.cursor {
+			c.count++
+			c.cursor = owner
+		}
+	}
+	if len(owners) == h.pageSize {
+		return false, false, nil
+	}
+
+	count := max(0, c.count+c.adjust)
+	ctx = context.Background()
+	existing, err := handler.LoadHolderStats(ctx, h.db, []string{token})
+	if err != nil {
+		return false, false, err
+	}
+	old, ok := existing[token]
+	changed = !ok || old.HolderCount != count
+	if ok && changed {
+		logger.Debugf("token_holder_stats drift for token %s: %d -> %d", token, old.HolderCount, count)
+	}
+
+	now := int32(time.Now().Unix())
+	err = handler.UpsertHolderStats(ctx, h.db, []*model.TokenHolderStats{{
+		Token:        token,
+		HolderCount:  count,
+		ReconciledAt: now,
+		UpdateAt:     now,
+	}})
+	return true, changed, err
+}
+
+// seedTokens 按 token 顺序遍历 balance 表，每轮最多取 batch 个 token，将从未对账过的加入待对账集合。
+// 用于补齐上线后一直没有余额变化的 token，遍历进度只保存在内存中，重启后从头开始（已对账的 token 会被跳过）。
+func (h *HolderStats) seedTokens() {
+	h.dirtyMu.Lock()
+	pending := len(h.dirty)
+	h.dirtyMu.Unlock()
+	if pending >= h.batch {
+		return
+	}
+
+	ctx := context.Background()
+	prevCursor, prevDone := h.seedCursor, h.seedDone
+	var tokens []string
+	for len(tokens) < h.batch {
+		token, err := handler.NextHolderToken(ctx, h.db, h.seedCursor)
+		if err != nil {
+			logger.Warnf("seed token_holder_stats after token %s failed: %v", h.seedCursor, err)
+			break
+		}
+		if token == "" {
+			h.seedDone = true
+			break
+		}
+		tokens = append(tokens, token)
+		h.seedCursor = token
+	}
+	if len(tokens) == 0 {
+		return
+	}
+
+	existing, err := handler.LoadHolderStats(ctx, h.db, tokens)
+	if err != nil {
+		logger.Warnf("seed token_holder_stats load %d tokens failed: %v", len(tokens), err)
+		h.seedCursor, h.seedDone = prevCursor, prevDone // 下一轮重新遍历这些 token
+		return
+	}
+	var unreconciled []string
+	for _, token := range tokens {
+		if s, ok := existing[token]; !ok || s.ReconciledAt == 0 {
+			unreconciled = append(unreconciled, token)
+		}
+	}
+	h.markDirty(unreconciled, true)
+	if h.seedDone {
+		logger.Infof("token_holder_stats seed finished")
+	}
+}
+
+type dirtyToken struct {
+	token   string
+	initial bool
+}
+
+// takeDirty 取出最多 batch 个待对账 token，未对账过的优先
+func (h *HolderStats) takeDirty() []dirtyToken {
+	h.dirtyMu.Lock()
+	defer h.dirtyMu.Unlock()
+
+	tokens := make([]dirtyToken, 0, min(h.batch, len(h.dirty)))
+	for _, initial := range []bool{true, false} {
+		for token, v := range h.dirty {
+			if len(tokens) >= h.batch {
+				break
+			}
+			if v == initial {
+				tokens = append(tokens, dirtyToken{token: token, initial: initial})
+				delete(h.dirty, token)
+			}
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,178 @@
+package ingest
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	flushFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingest_flush_failures_total",
+		Help: "Number of failed flushes, the batches are kept and retried",
+	}, []string{"router", "partition"})
+
+	partitionStuck = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingest_partition_stuck",
+		Help: "1 if the partition is halted on a failed flush and offsets are not committed",
+	}, []string{"router", "partition"})
+
+	slotLast = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingest_slot_last",
+		Help: "Highest slot consumed on the partition",
+	}, []string{"router", "partition"})
+
+	slotGapsOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingest_slot_gaps_open",
+		Help: "Number of confirmed slot gaps that are not fully filled yet",
+	}, []string{"router", "partition"})
+
+	slotMissing = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingest_slot_missing",
+		Help: "Number of slots still missing in confirmed gaps",
+	}, []string{"router", "partition"})
+
+	slotGapsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingest_slot_gaps_total",
+		Help: "Number of slot gaps confirmed by a backfilled block",
+	}, []string{"router", "partition"})
+
+	slotGapFilledTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingest_slot_gap_filled_total",
+		Help: "Number of missing slots later filled, grpc=true for backfilled blocks",
+	}, []string{"router", "partition", "grpc"})
+
+	slotSkippedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingest_slot_skipped_total",
+		Help: "Number of slots never backfilled within the skip window, treated as skipped slots",
+	}, []string{"router", "partition"})
+
+	slotDuplicatesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingest_slot_duplicates_total",
+		Help: "Number of messages repeating the last consumed slot",
+	}, []string{"router", "partition"})
+
+	slotRegressionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingest_slot_regressions_total",
+		Help: "Number of out-of-order messages older than the last slot that do not fill a gap",
+	}, []string{"router", "partition"})
+
+	slotRollbacksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingest_slot_rollbacks_total",
+		Help: "Number of slots re-received with a different block hash and rolled back",
+	}, []string{"router", "partition"})
+
+	sinkErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingest_sink_errors_total",
+		Help: "Number of failed sink writes, on_error=ignore failures do not block offset commits",
+	}, []string{"router", "sink", "on_error"})
+
+	clickhouseRowsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingest_clickhouse_rows_total",
+		Help: "Number of rows written to ClickHouse",
+	}, []string{"table"})
+
+	clickhouseWriteFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingest_clickhouse_write_failures_total",
+		Help: "Number of failed ClickHouse insert attempts, the insert is retried with backoff",
+	}, []string{"table"})
+
+	clickhouseSpooledRowsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "ingest_clickhouse_spooled_rows_total",
+		Help: "Number of rows saved to the local spool file because they were not written to ClickHouse before shutdown",
+	})
+
+	clickhouseQueueLength = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ingest_clickhouse_queue_length",
+		Help: "Number of flushes waiting in the ClickHouse sink queue",
+	})
+
+	clickhouseOffsetSlot = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingest_clickhouse_offset_slot",
+		Help: "Highest realtime slot written to ClickHouse on the partition",
+	}, []string{"router", "partition"})
+
+	messagesConsumedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingest_messages_consumed_total",
+		Help: "Number of kafka messages handled by the partition worker",
+	}, []string{"router", "partition"})
+
+	messagesByVersionTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingest_messages_by_version_total",
+		Help: "Number of kafka messages by Events.version",
+	}, []string{"router", "version"})
+
+	unknownVersionTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingest_unknown_version_total",
+		Help: "Number of messages with an unsupported Events.version, by action taken",
+	}, []string{"router", "action"})
+
+	batchesFlushedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingest_batches_flushed_total",
+		Help: "Number of block batches flushed and committed",
+	}, []string{"router", "partition"})
+
+	flushDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ingest_flush_duration_seconds",
+		Help:    "Latency of a whole flush across all sinks",
+		Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+	}, []string{"router", "partition"})
+
+	rowsWrittenTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingest_rows_written_total",
+		Help: "Number of rows written to the primary storage per table",
+	}, []string{"router", "partition", "table"})
+
+	insertDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ingest_insert_duration_seconds",
+		Help:    "Latency of each Insert* call against the primary storage, including retries",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"router", "table"})
+
+	batchQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingest_batch_queue_depth",
+		Help: "Number of block batches buffered in the partition worker",
+	}, []string{"router", "partition"})
+
+	dispatchChannelLength = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingest_dispatch_channel_length",
+		Help: "Number of messages waiting in the partition channel (capacity partitionChannelSize)",
+	}, []string{"router", "partition"})
+
+	flushedSlot = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingest_flushed_slot",
+		Help: "Highest slot flushed and committed on the partition",
+	}, []string{"router", "partition"})
+
+	flushedBlockTime = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingest_flushed_block_time_seconds",
+		Help: "Latest block time (unix seconds) flushed on the partition, compare with time() for freshness",
+	}, []string{"router", "partition"})
+
+	kafkaConsumerLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingest_kafka_consumer_lag",
+		Help: "Messages between the partition high watermark and the last dispatched offset",
+	}, []string{"router", "partition"})
+
+	partitionPaused = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingest_partition_paused",
+		Help: "1 if fetching of the partition is paused because its channel passed the high-water mark",
+	}, []string{"router", "partition"})
+
+	partitionPausesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingest_partition_pauses_total",
+		Help: "Number of times the partition was paused for backpressure",
+	}, []string{"router", "partition"})
+)
+
+// deletePartitionMetrics 分区被回收后清理该分区的 gauge，避免残留过期数值
+func deletePartitionMetrics(router RouterType, partition int32) {
+	labels := []string{router.String(), strconv.Itoa(int(partition))}
+	batchQueueDepth.DeleteLabelValues(labels...)
+	dispatchChannelLength.DeleteLabelValues(labels...)
+	flushedSlot.DeleteLabelValues(labels...)
+	flushedBlockTime.DeleteLabelValues(labels...)
+	kafkaConsumerLag.DeleteLabelValues(labels...)
+	partitionPaused.DeleteLabelValues(labels...)
+}
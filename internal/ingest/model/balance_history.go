@@ -0,0 +1,13 @@
+package model
+
+// BalanceHistory 一次余额变更，由 BalanceUpdateEvent 追加写入，余额归零的变更同样保留
+type BalanceHistory struct {
+	AccountAddress string // 账户地址（TokenAccount）
+	EventID        int64  // 事件 ID
+	OwnerAddress   string // 所属钱包地址
+	TokenAddress   string // Token 合约地址
+	PreBalance     string // 变更前余额，DECIMAL(20, 0)
+	PostBalance    string // 变更后余额，DECIMAL(20, 0)
+	Delta          string // post - pre，可能为负，DECIMAL(21, 0)
+	BlockTime      int32  // 区块时间戳（秒级）
+}
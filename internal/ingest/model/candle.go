@@ -0,0 +1,26 @@
+package model
+
+// Candle 池子在一个时间周期内的 OHLCV K 线，由 TRADE_BUY / TRADE_SELL 事件聚合
+type Candle struct {
+	PoolAddress string // 池子地址
+	IntervalSec int32  // 周期长度（秒）：60 / 300 / 900 / 3600 / 14400 / 86400
+	OpenTime    int32  // 周期开始时间（秒级，按周期对齐）
+
+	Token      string // base token 地址
+	QuoteToken string // quote token 地址
+
+	Open        float64 // 开盘价（price_usd）
+	High        float64 // 最高价
+	Low         float64 // 最低价
+	Close       float64 // 收盘价
+	VolumeUsd   float64 // 成交额（USD）
+	BaseVolume  string  // base token 成交量（原生单位），DECIMAL(38, 0)
+	QuoteVolume string  // quote token 成交量（原生单位），DECIMAL(38, 0)
+	BuyCount    int32   // 买入笔数
+	SellCount   int32   // 卖出笔数
+
+	OpenEventID  int64 // 开盘价对应的事件 ID，合并时 event_id 更小的一方决定开盘价
+	CloseEventID int64 // 收盘价对应的事件 ID，合并时 event_id 更大的一方决定收盘价
+
+	UpdateAt int32 // 更新时间（秒级）
+}
@@ -0,0 +1,11 @@
+package model
+
+// Checkpoint 记录每个分区最后一次成功 flush 的位置，与数据写入同一存储，用于崩溃后精确恢复
+type Checkpoint struct {
+	Router    string // 写入方（event / balance）
+	Topic     string // Kafka topic
+	Partition int32  // Kafka 分区
+	Offset    int64  // 已写入的最后一条消息 offset（下次从 Offset+1 开始消费）
+	MaxSlot   int64  // 已写入的最大 slot
+	UpdateAt  int32  // 写入时间（秒级）
+}
@@ -0,0 +1,29 @@
+package model
+
+type Migration struct {
+	EventIDHash int32 // 事件哈希
+	EventID     int64 // 原始事件 ID
+
+	Token           string // base token 地址
+	SrcPoolAddress  string // 来源池地址（如 Pump.fun 内盘）
+	DestPoolAddress string // 目标池地址（如 Pump.fun AMM）
+	SrcDex          int16  // 来源 DEX 标识
+	DestDex         int16  // 目标 DEX 标识
+	SrcQuoteToken   string // 来源 quote token
+	DestQuoteToken  string // 目标 quote token
+
+	TokenAmount   string // 迁移的 base token 数量，DECIMAL(20, 0)
+	QuoteAmount   string // 迁移的 quote token 数量，DECIMAL(20, 0)
+	MigrationFee  string // 迁移手续费，DECIMAL(20, 0)
+	TokenDecimals int16  // base token 精度
+	QuoteDecimals int16  // quote token 精度
+
+	Creator    string // 新池创建者
+	UserWallet string // 发起迁移的地址（可能为代理地址）
+
+	TxHash string // 交易哈希
+	Signer string // 签名人地址
+
+	BlockTime int32 // 区块时间戳（秒级）
+	CreateAt  int32 // 写入数据库的时间（秒级）
+}
@@ -0,0 +1,19 @@
+package model
+
+// PoolState 池子最新的储备量和价格，按 last_event_id 单调更新
+type PoolState struct {
+	PoolAddress string // 池子地址
+
+	TokenReserve  string // 池子 base token 余额（原生单位），DECIMAL(20, 0)
+	QuoteReserve  string // 池子 quote token 余额（原生单位），DECIMAL(20, 0)
+	TokenDecimals int16  // base token 精度
+	QuoteDecimals int16  // quote token 精度
+
+	PriceUsd      float64 // 最近一笔交易的 base token 单价（USD），流动性事件不带价格，沿用上一次的值
+	QuotePriceUsd float64 // quote token 的 USD 价格（来自 Events.quote_prices，非报价币为 0）
+	LiquidityUsd  float64 // 流动性估值（USD）
+
+	LastEventID   int64 // 最近一次更新储备量的事件 ID
+	LastTradeTime int32 // 最近一笔交易的区块时间（秒级）
+	UpdateAt      int32 // 更新时间（秒级）
+}
@@ -0,0 +1,10 @@
+package model
+
+// QuotePrice 报价币（WSOL / USDC / USDT）在某个 slot 的 USD 价格，来自 Events.quote_prices
+type QuotePrice struct {
+	Token     string  // 报价币地址（编码后，与 token / pool 表一致）
+	Slot      int64   // slot
+	BlockTime int32   // 区块时间戳（秒级）
+	Price     float64 // 每 1 个 token 的 USD 价格（非最小单位）
+	Decimals  int16   // token 精度
+}
@@ -0,0 +1,16 @@
+package model
+
+// SlotBlock 记录每个 router 已写入的 slot 对应的区块哈希和写入主键，用于重启后的分叉检测和回滚
+type SlotBlock struct {
+	Router       string // 写入方（event / balance）
+	Slot         int64  // slot
+	BlockHash    string // 区块哈希（base58）
+	Source       int16  // 数据来源：1=GRPC补块，2=RPC推送
+	RollbackKeys []byte // 该区块写入的主键（二进制编码），分叉时据此删除旧区块的数据
+}
+
+// EventKey 表示事件类表的主键 (event_id_hash, event_id)
+type EventKey struct {
+	EventIDHash int32
+	EventID     int64
+}
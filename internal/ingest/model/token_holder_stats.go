@@ -0,0 +1,15 @@
+package model
+
+// TokenHolderStats token 的持有人数（至少有一个非零余额账户的钱包数），由 balance 写入增量维护并定期对账
+type TokenHolderStats struct {
+	Token        string // token 地址（与 balance 表一致，写入 token_holder_stats 时按 EncodeTokenAddress 编码）
+	HolderCount  int64  // 持有人数
+	ReconciledAt int32  // 最近一次分段统计对账的完成时间（秒级），0 表示尚未对账，计数只包含上线后的增量
+	UpdateAt     int32  // 更新时间（秒级）
+}
+
+// HolderKey 钱包在某个 token 上的持有关系
+type HolderKey struct {
+	Owner string
+	Token string
+}
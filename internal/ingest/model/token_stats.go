@@ -0,0 +1,45 @@
+package model
+
+// TokenStats token 在一个滚动窗口内的交易统计
+type TokenStats struct {
+	Token     string // token 地址（编码后，与 token 表一致）
+	WindowSec int32  // 窗口长度（秒）：300 / 3600 / 21600 / 86400
+
+	VolumeUsd     float64 // 成交额（USD）
+	TradeCount    int32   // 成交笔数
+	BuyCount      int32   // 买入笔数
+	SellCount     int32   // 卖出笔数
+	UniqueTraders int32   // 去重交易地址数
+
+	PriceOpen   float64 // 窗口内第一笔交易价格（USD）
+	PriceClose  float64 // 窗口内最后一笔交易价格（USD）
+	PriceChange float64 // 价格变化比例：(close - open) / open
+
+	TrackedSince int32 // 统计开始时间（秒级），早于该时间的交易未计入，窗口起点早于它时数据不完整
+	UpdateAt     int32 // 更新时间（秒级）
+}
+
+// TokenMinute token 一分钟内的交易汇总，由 chain_event 重算，是 token_stats 各窗口的数据来源
+type TokenMinute struct {
+	Token      string // token 地址（与 chain_event 一致，未编码）
+	MinuteTime int32  // 分钟开始时间（秒级）
+
+	VolumeUsd float64 // 成交额（USD）
+	BuyCount  int32   // 买入笔数
+	SellCount int32   // 卖出笔数
+
+	OpenPrice    float64 // 分钟内第一笔有价格交易的价格（USD）
+	OpenEventID  int64   // OpenPrice 对应的事件 ID
+	ClosePrice   float64 // 分钟内最后一笔有价格交易的价格（USD）
+	CloseEventID int64   // ClosePrice 对应的事件 ID
+
+	UpdateAt int32 // 更新时间（秒级）
+}
+
+// TokenTrader 交易地址在某个 token 上最近一次交易的时间，用于统计窗口内去重交易地址数
+type TokenTrader struct {
+	Token         string // token 地址（与 chain_event 一致，未编码）
+	Trader        string // 交易地址
+	LastTradeTime int32  // 最近一次交易的区块时间（秒级）
+	UpdateAt      int32  // 更新时间（秒级）
+}
@@ -0,0 +1,36 @@
+package model
+
+// WalletTrade 钱包的一笔买卖，由 TRADE_BUY / TRADE_SELL 事件生成，用于更新 wallet_position
+type WalletTrade struct {
+	EventID     int64   // 事件 ID，决定应用顺序
+	EventType   int16   // TRADE_BUY / TRADE_SELL
+	Owner       string  // 交易钱包地址
+	Token       string  // base token 地址（编码后，与 token / pool 表一致）
+	Decimals    int16   // base token 精度
+	TokenAmount string  // 成交数量（原生单位），DECIMAL(20, 0)
+	VolumeUsd   float64 // 成交额（USD）
+	BlockTime   int32   // 区块时间戳（秒级）
+}
+
+// WalletPosition 钱包在某个 token 上的持仓和盈亏，按平均成本法由 chain_event 中的全部买卖重算
+type WalletPosition struct {
+	Owner    string // 钱包地址
+	Token    string // base token 地址（编码后）
+	Decimals int16  // base token 精度
+
+	Position   string  // 通过买卖累计的当前持仓（原生单位），DECIMAL(38, 0)
+	AvgCostUsd float64 // 当前持仓的平均成本（每 1 个 token 的 USD 价格），清仓后归零
+
+	BoughtAmount string  // 累计买入数量（原生单位），DECIMAL(38, 0)
+	SoldAmount   string  // 累计卖出数量（原生单位），DECIMAL(38, 0)
+	BoughtUsd    float64 // 累计买入金额（USD）
+	SoldUsd      float64 // 累计卖出金额（USD）
+	RealizedPnl  float64 // 已实现盈亏（USD）
+	BuyCount     int32   // 买入笔数
+	SellCount    int32   // 卖出笔数
+
+	LastEventID   int64 // 最后一笔买卖的事件 ID
+	LastTradeTime int32 // 最后一笔交易的区块时间（秒级）
+	UpdateAt      int32 // 更新时间（秒级）
+	CreateAt      int32 // 首次写入时间（秒级）
+}
@@ -0,0 +1,72 @@
+package ingest
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PartitionState 记录分区 worker 的 flush 健康状态，供指标和健康检查读取
+type PartitionState struct {
+	mu         sync.RWMutex
+	stuck      bool      // 是否因 flush 失败而暂停消费
+	stuckSince time.Time // 首次失败时间
+	failures   int       // 连续失败次数
+	lastErr    error     // 最近一次失败原因
+	halted     error     // 遇到未知版本或死信写入失败后停止消费的原因
+}
+
+func (s *PartitionState) markFailed(err error) (failures int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.stuck {
+		s.stuck = true
+		s.stuckSince = time.Now()
+	}
+	s.failures++
+	s.lastErr = err
+	return s.failures
+}
+
+func (s *PartitionState) markRecovered() (wasStuck bool, failures int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	wasStuck, failures = s.stuck, s.failures
+	s.stuck = false
+	s.failures = 0
+	s.lastErr = nil
+	return
+}
+
+func (s *PartitionState) markHalted(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.halted = err
+}
+
+// IsHalted 是否因未知消息版本或死信写入失败停止消费（重启前不会恢复）
+func (s *PartitionState) IsHalted() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.halted != nil
+}
+
+func (s *PartitionState) IsStuck() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.stuck
+}
+
+// Err 返回卡住状态的描述，正常时返回 nil
+func (s *PartitionState) Err() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.halted != nil {
+		return fmt.Errorf("halted: %v", s.halted)
+	}
+	if !s.stuck {
+		return nil
+	}
+	return fmt.Errorf("stuck since %s after %d failed flushes: %v",
+		s.stuckSince.Format(time.RFC3339), s.failures, s.lastErr)
+}
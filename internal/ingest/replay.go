@@ -0,0 +1,98 @@
+package ingest
+
+import (
+	"context"
+	"dex-ingest-sol/internal/ingest/handler"
+	"dex-ingest-sol/internal/pkg/dlq"
+	"dex-ingest-sol/internal/pkg/logger"
+	"dex-ingest-sol/internal/pkg/utils"
+	"errors"
+	"io"
+	"math"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ReplayStats 记录一次死信重放的结果
+type ReplayStats struct {
+	Total    int // 读取的死信条数
+	Replayed int // 重新构建并写库成功的条数
+	Skipped  int // 不属于当前 router 类型而跳过的条数
+	Failed   int // 仍然无法构建或写库失败的条数
+}
+
+// ReplayDeadLetters 将死信逐条重新走 buildBlockBatch 和写库流程。
+// 仍然失败的条目不会被确认：Kafka 模式下从第一条失败开始不再提交 offset，修复后可再次重放。
+func ReplayDeadLetters(ctx context.Context, reader dlq.Reader, sink Sink, routerType RouterType) (ReplayStats, error) {
+	w := &WorkerContext{
+		ctx:         ctx,
+		RouterType:  routerType,
+		Partition:   -1,
+		Sink:        sink,
+		Base58Cache: utils.NewBase58Cache(),
+		// 重放数据一定早于线上数据，余额统一走 historical 路径（按 last_event_id 过滤），避免覆盖新值
+		lastSlot:      math.MaxUint64,
+		lastFlushTime: time.Now(),
+	}
+	if routerType == RouterEvent {
+		w.PoolCache = handler.NewPoolCache()
+		w.TokenCache = handler.NewTokenCache()
+	}
+
+	var stats ReplayStats
+	commitable := true
+	for {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		entry, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return stats, nil
+		}
+		if err != nil {
+			return stats, err
+		}
+		stats.Total++
+
+		if entry.Router != routerType.String() {
+			stats.Skipped++
+			continue
+		}
+
+		msg := &kafka.Message{
+			TopicPartition: kafka.TopicPartition{
+				Topic:     &entry.Topic,
+				Partition: entry.Partition,
+				Offset:    kafka.Offset(entry.Offset),
+			},
+			Value: entry.Value,
+		}
+		batch, buildErr := buildBlockBatch(routerType, entry.Partition, msg, w.Base58Cache, w.PoolCache, w.TokenCache)
+		if buildErr != nil {
+			stats.Failed++
+			commitable = false
+			logger.Errorf("[replay topic=%s partition=%d offset=%d] still failing: %v",
+				entry.Topic, entry.Partition, entry.Offset, buildErr)
+			continue
+		}
+		if batch != nil {
+			if _, err = w.flushBatches([]*BlockBatch{batch}); err != nil {
+				stats.Failed++
+				commitable = false
+				logger.Errorf("[replay topic=%s partition=%d offset=%d] flush failed: %v",
+					entry.Topic, entry.Partition, entry.Offset, err)
+				continue
+			}
+		}
+		stats.Replayed++
+
+		if commitable {
+			if err = reader.Commit(entry); err != nil {
+				logger.Warnf("[replay topic=%s partition=%d offset=%d] commit dead letter failed: %v",
+					entry.Topic, entry.Partition, entry.Offset, err)
+			}
+		}
+	}
+}
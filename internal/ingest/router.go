@@ -2,43 +2,93 @@ package ingest
 
 import (
 	"context"
-	"database/sql"
 	"dex-ingest-sol/internal/config"
+	"dex-ingest-sol/internal/pkg/dlq"
 	"dex-ingest-sol/internal/pkg/logger"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
-	"github.com/redis/go-redis/v9"
+	"net/http"
+	"sort"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
+const (
+	partitionChannelSize = 1000                   // 每个分区 worker 的消息通道容量
+	defaultHighWater     = 800                    // 默认暂停水位
+	defaultLowWater      = 200                    // 默认恢复水位
+	resumeCheckInterval  = 100 * time.Millisecond // 检查已暂停分区是否可以恢复的间隔
+	lagReportInterval    = 10 * time.Second       // Kafka 消费延迟指标的刷新间隔
+	defaultShutdown      = 30 * time.Second       // 关闭时 worker 写完缓冲数据的默认超时时间
+)
+
+// partitionWorker 表示单个分区 worker 的句柄
+type partitionWorker struct {
+	topic      string
+	partition  int32
+	msgCh      chan *kafka.Message
+	stop       chan stopSignal // 通知 worker 写完缓冲数据后退出（分区回收 / 服务关闭）
+	done       chan struct{}   // worker 退出后关闭
+	result     drainResult     // worker 退出前最后一次 flush 的结果，done 关闭后可读
+	state      *PartitionState
+	slots      *SlotTracker
+	lastOffset atomic.Int64 // 最近分发的消息 offset，用于计算消费延迟
+	paused     atomic.Bool  // 是否因通道积压已暂停拉取该分区
+}
+
 type PartitionRouter struct {
 	mu      sync.Mutex
-	workers map[int32]chan *kafka.Message
+	workers map[int32]*partitionWorker
 	wg      sync.WaitGroup
 
 	ctx    context.Context
 	cancel context.CancelFunc
 
 	routerType  RouterType
-	db          *sql.DB
-	redis       *redis.Client
+	sink        Sink
 	kafka       *kafka.Consumer
+	deadLetter  dlq.Writer
+	stats       *TokenStatsTracker // token 滚动窗口统计（未启用时为 nil）
+	slotBlocks  SlotBlockLoader    // 读取持久化的 slot_block，用于内存窗口之外的分叉检测（可为 nil）
 	config      *config.WorkerConfig
+	highWater   int           // 通道积压达到该值时暂停分区
+	lowWater    int           // 积压降到该值以下时恢复分区
+	shutdown    time.Duration // 关闭时 worker 写完缓冲数据的超时时间
+	stopOnce    sync.Once
 	lastLogTime atomic.Int64
 }
 
 // NewPartitionRouter 构造函数，需指定类型
-func NewPartitionRouter(db *sql.DB, redis *redis.Client, cfg *config.WorkerConfig, routerType RouterType) *PartitionRouter {
+func NewPartitionRouter(sink Sink, deadLetter dlq.Writer, cfg *config.WorkerConfig, routerType RouterType) *PartitionRouter {
+	highWater, lowWater := cfg.HighWater, cfg.LowWater
+	if highWater <= 0 || highWater > partitionChannelSize {
+		highWater = defaultHighWater
+	}
+	if lowWater <= 0 || lowWater >= highWater {
+		lowWater = min(defaultLowWater, highWater/2)
+	}
+
+	shutdown := cfg.ShutdownTimeout
+	if shutdown <= 0 {
+		shutdown = defaultShutdown
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	return &PartitionRouter{
-		workers:    make(map[int32]chan *kafka.Message),
+		workers:    make(map[int32]*partitionWorker),
 		ctx:        ctx,
 		cancel:     cancel,
 		routerType: routerType,
-		db:         db,
-		redis:      redis,
+		sink:       sink,
+		deadLetter: deadLetter,
 		config:     cfg,
+		highWater:  highWater,
+		lowWater:   lowWater,
+		shutdown:   shutdown,
 	}
 }
 
@@ -46,20 +96,167 @@ func (r *PartitionRouter) SetKafkaConsumer(k *kafka.Consumer) {
 	r.kafka = k
 }
 
-// Start 空实现，兼容 go-zero Service 接口
+// SetTokenStats 由 router 管理 token 滚动窗口统计的定时写入（分钟汇总由 sink 在 flush 内重算）
+func (r *PartitionRouter) SetTokenStats(stats *TokenStatsTracker) {
+	r.stats = stats
+}
+
+// SetSlotBlockLoader 启用基于 slot_block 表的分叉检测，覆盖重启和 rebalance 之前写入的区块
+func (r *PartitionRouter) SetSlotBlockLoader(load SlotBlockLoader) {
+	r.slotBlocks = load
+}
+
+// Start 兼容 go-zero Service 接口，启动消费延迟指标的定时上报
 func (r *PartitionRouter) Start() {
 	logger.Infof("PartitionRouter started: type=%v", r.routerType)
+	go r.reportLag()
+	go r.resumePartitions()
+	if r.stats != nil {
+		r.stats.Start()
+	}
 }
 
-// Stop 优雅关闭所有 worker
+// Stop 优雅关闭：通知所有 worker 写完缓冲数据并提交 offset（受 shutdown_timeout 限制），
+// 然后关闭 sink 和死信。调用前应先停止 Kafka 拉取，避免 drain 期间仍有新消息分发进来。可重复调用。
 func (r *PartitionRouter) Stop() {
-	r.cancel()
+	r.stopOnce.Do(r.stop)
+}
+
+func (r *PartitionRouter) stop() {
+	start := time.Now()
+
 	r.mu.Lock()
-	for _, ch := range r.workers {
-		close(ch)
+	workers := make([]*partitionWorker, 0, len(r.workers))
+	for partition, pw := range r.workers {
+		workers = append(workers, pw)
+		delete(r.workers, partition)
 	}
 	r.mu.Unlock()
+
+	logger.Infof("[%s] draining %d partition workers (timeout %s)", r.routerType, len(workers), r.shutdown)
+	for _, pw := range workers {
+		pw.stop <- stopSignal{reason: "shutdown", timeout: r.shutdown}
+	}
+
+	// 正在重试中的写入不受 worker 自身超时控制，到达截止时间后取消 ctx 强制退出
+	allDone := make(chan struct{})
+	go func() {
+		for _, pw := range workers {
+			<-pw.done
+		}
+		close(allDone)
+	}()
+	select {
+	case <-allDone:
+	case <-time.After(r.shutdown):
+		logger.Warnf("[%s] drain timeout after %s, cancelling in-flight writes", r.routerType, r.shutdown)
+		r.cancel()
+		<-allDone
+	}
+
+	var total drainResult
+	for _, pw := range workers {
+		total.flushed += pw.result.flushed
+		total.dropped += pw.result.dropped
+		deletePartitionMetrics(r.routerType, pw.partition)
+	}
+	logger.Infof("[%s] %d workers drained in %s: %d batches flushed and committed, %d batches dropped (not committed)",
+		r.routerType, len(workers), time.Since(start), total.flushed, total.dropped)
+
+	r.cancel()
 	r.wg.Wait()
+
+	if r.stats != nil {
+		r.stats.Stop()
+		logger.Infof("[%s] token stats flushed", r.routerType)
+	}
+	if err := r.sink.Close(); err != nil {
+		logger.Errorf("close sink failed: %v", err)
+	} else {
+		logger.Infof("[%s] sink %s closed", r.routerType, r.sink.Name())
+	}
+	if r.deadLetter != nil {
+		r.deadLetter.Close()
+		logger.Infof("[%s] dead letter closed", r.routerType)
+	}
+}
+
+// Health 汇总各分区状态，存在因 flush 失败而卡住的分区时返回 error
+func (r *PartitionRouter) Health() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errs []error
+	for partition, pw := range r.workers {
+		if err := pw.state.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("[%s partition=%d] %w", r.routerType, partition, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SlotReports 返回各分区的 slot 连续性快照（按分区排序）
+func (r *PartitionRouter) SlotReports() []SlotReport {
+	r.mu.Lock()
+	reports := make([]SlotReport, 0, len(r.workers))
+	for _, pw := range r.workers {
+		reports = append(reports, pw.slots.Report())
+	}
+	r.mu.Unlock()
+
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Partition < reports[j].Partition })
+	return reports
+}
+
+// ServeSlots 以 JSON 输出各分区的 slot 缺口记录，挂载到监控服务
+func (r *PartitionRouter) ServeSlots(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(r.SlotReports())
+}
+
+// Rebalance 是 Kafka 分区分配/回收回调（kafka.RebalanceCb），在消费主循环中同步执行：
+//   - 回收：通知对应 worker 处理完已缓冲的消息并 flush + commit 后退出，等待其结束再返回，
+//     保证回收完成前不会再有旧 worker 提交 offset
+//   - 分配：新分区在收到第一条消息时按需创建全新的 worker
+func (r *PartitionRouter) Rebalance(c *kafka.Consumer, ev kafka.Event) error {
+	switch e := ev.(type) {
+	case kafka.AssignedPartitions:
+		logger.Infof("[%s] partitions assigned: %v", r.routerType, partitionIDs(e.Partitions))
+	case kafka.RevokedPartitions:
+		// 分配已丢失（如会话超时）时 offset 无法再提交，直接丢弃缓存，由新的 owner 重新消费
+		lost := c.AssignmentLost()
+		logger.Infof("[%s] partitions revoked: %v, lost=%v", r.routerType, partitionIDs(e.Partitions), lost)
+		r.revokePartitions(e.Partitions, lost)
+	}
+	return nil
+}
+
+// revokePartitions 停止被回收分区的 worker 并等待其退出
+func (r *PartitionRouter) revokePartitions(partitions []kafka.TopicPartition, lost bool) {
+	revoked := make([]*partitionWorker, 0, len(partitions))
+
+	r.mu.Lock()
+	for _, tp := range partitions {
+		pw, ok := r.workers[tp.Partition]
+		if !ok {
+			continue
+		}
+		delete(r.workers, tp.Partition)
+		revoked = append(revoked, pw)
+	}
+	r.mu.Unlock()
+
+	for _, pw := range revoked {
+		// 暂停状态会保留在 librdkafka 的分区上，回收前恢复，避免之后重新分配到本实例时无法拉取
+		if pw.paused.Load() {
+			r.resume(pw)
+		}
+		pw.stop <- stopSignal{reason: "revoked", lost: lost, timeout: revokeFlushTimeout}
+	}
+	for _, pw := range revoked {
+		<-pw.done
+		deletePartitionMetrics(r.routerType, pw.partition)
+	}
 }
 
 // Dispatch 根据分区分发消息
@@ -67,28 +264,134 @@ func (r *PartitionRouter) Dispatch(msg *kafka.Message) {
 	partition := msg.TopicPartition.Partition
 
 	r.mu.Lock()
-	ch, ok := r.workers[partition]
+	pw, ok := r.workers[partition]
 	if !ok {
-		ch = make(chan *kafka.Message, 1000)
-		r.workers[partition] = ch
+		pw = &partitionWorker{
+			topic:     *msg.TopicPartition.Topic,
+			partition: partition,
+			msgCh:     make(chan *kafka.Message, partitionChannelSize),
+			stop:      make(chan stopSignal, 1),
+			done:      make(chan struct{}),
+			state:     &PartitionState{},
+			slots:     NewSlotTracker(r.routerType, partition),
+		}
+		r.workers[partition] = pw
 		r.wg.Add(1)
 
-		go func(partition int32, ch <-chan *kafka.Message) {
+		go func(partition int32, pw *partitionWorker) {
 			defer r.wg.Done()
-			StartWorker(r.ctx, partition, ch, r.db, r.redis, r.kafka, r.config, r.routerType)
-		}(partition, ch)
+			defer close(pw.done)
+			pw.result = StartWorker(r.ctx, partition, pw.msgCh, pw.stop, r.sink, r.kafka, r.deadLetter, pw.state, pw.slots, r.slotBlocks, r.config, r.routerType)
+		}(partition, pw)
 	}
 	r.mu.Unlock()
 
 	select {
-	case ch <- msg:
+	case pw.msgCh <- msg:
 	default:
+		// 正常情况下积压达到高水位时已暂停该分区，这里只会在暂停前已拉取的消息上阻塞
 		// block 但打印日志
 		now := time.Now().Unix()
 		last := r.lastLogTime.Load()
 		if now-last >= 15 && r.lastLogTime.CompareAndSwap(last, now) {
 			logger.Warnf("[partition=%d] message blocked, channel full", partition)
 		}
-		ch <- msg
+		pw.msgCh <- msg
+	}
+	pw.lastOffset.Store(int64(msg.TopicPartition.Offset))
+	dispatchChannelLength.WithLabelValues(r.routerType.String(), strconv.Itoa(int(partition))).Set(float64(len(pw.msgCh)))
+
+	// 积压达到高水位：只暂停该分区的拉取，其它分区继续消费
+	if len(pw.msgCh) >= r.highWater && !pw.paused.Load() {
+		r.pause(pw)
+	}
+}
+
+// pause 暂停拉取分区消息（librdkafka Pause），已拉取到本地的消息会被丢弃，恢复后从当前位置继续拉取
+func (r *PartitionRouter) pause(pw *partitionWorker) {
+	if r.kafka == nil {
+		return
+	}
+	tp := kafka.TopicPartition{Topic: &pw.topic, Partition: pw.partition}
+	if err := r.kafka.Pause([]kafka.TopicPartition{tp}); err != nil {
+		logger.Errorf("[partition=%d] pause partition failed: %v", pw.partition, err)
+		return
+	}
+	pw.paused.Store(true)
+
+	partition := strconv.Itoa(int(pw.partition))
+	partitionPaused.WithLabelValues(r.routerType.String(), partition).Set(1)
+	partitionPausesTotal.WithLabelValues(r.routerType.String(), partition).Inc()
+	logger.Warnf("[partition=%d] channel backlog %d >= %d, partition paused", pw.partition, len(pw.msgCh), r.highWater)
+}
+
+func (r *PartitionRouter) resume(pw *partitionWorker) {
+	tp := kafka.TopicPartition{Topic: &pw.topic, Partition: pw.partition}
+	if err := r.kafka.Resume([]kafka.TopicPartition{tp}); err != nil {
+		logger.Errorf("[partition=%d] resume partition failed: %v", pw.partition, err)
+		return
+	}
+	pw.paused.Store(false)
+	partitionPaused.WithLabelValues(r.routerType.String(), strconv.Itoa(int(pw.partition))).Set(0)
+	logger.Infof("[partition=%d] channel backlog %d <= %d, partition resumed", pw.partition, len(pw.msgCh), r.lowWater)
+}
+
+// resumePartitions 定时检查已暂停的分区，积压降到低水位以下时恢复拉取。
+// 暂停的分区不会再触发 Dispatch，因此恢复只能由独立的协程完成。
+func (r *PartitionRouter) resumePartitions() {
+	ticker := time.NewTicker(resumeCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		r.mu.Lock()
+		for _, pw := range r.workers {
+			if pw.paused.Load() && len(pw.msgCh) <= r.lowWater {
+				r.resume(pw)
+			}
+		}
+		r.mu.Unlock()
+	}
+}
+
+// reportLag 定时根据分区高水位计算消费延迟：high watermark - (最近分发的 offset + 1)。
+// 使用客户端缓存的水位（随 fetch 响应更新），不会额外请求 broker。
+func (r *PartitionRouter) reportLag() {
+	ticker := time.NewTicker(lagReportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if r.kafka == nil {
+			continue
+		}
+
+		r.mu.Lock()
+		for partition, pw := range r.workers {
+			offset := pw.lastOffset.Load()
+			_, high, err := r.kafka.GetWatermarkOffsets(pw.topic, partition)
+			if err != nil || high < 0 {
+				continue
+			}
+			kafkaConsumerLag.WithLabelValues(r.routerType.String(), strconv.Itoa(int(partition))).Set(float64(max(high-offset-1, 0)))
+		}
+		r.mu.Unlock()
+	}
+}
+
+func partitionIDs(partitions []kafka.TopicPartition) []int32 {
+	ids := make([]int32, 0, len(partitions))
+	for _, tp := range partitions {
+		ids = append(ids, tp.Partition)
 	}
+	return ids
 }
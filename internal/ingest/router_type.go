@@ -12,6 +12,17 @@ const (
 	RouterBalance
 )
 
+func (t RouterType) String() string {
+	switch t {
+	case RouterEvent:
+		return "event"
+	case RouterBalance:
+		return "balance"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(t))
+	}
+}
+
 func ParseRouterType(s string) (RouterType, error) {
 	switch strings.ToLower(s) {
 	case "event":
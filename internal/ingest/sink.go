@@ -0,0 +1,188 @@
+package ingest
+
+import (
+	"context"
+	"database/sql"
+	"dex-ingest-sol/internal/config"
+	"dex-ingest-sol/internal/ingest/handler"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/logger"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"sync"
+)
+
+// Sink 接收 worker flush 出来的一组 BlockBatch 并写入存储。
+// 写入失败的 flush 会整体重试（offset 不提交），因此实现必须是幂等的（upsert / 按主键覆盖）。
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, f *Flush) error
+	Close() error
+}
+
+// Flush 表示一次 flush 的数据及其上下文
+type Flush struct {
+	Router    RouterType
+	Partition int32
+	LastSlot  uint64 // 上一次成功 flush 的最大 slot，用于区分实时 / 历史余额
+	Batches   []*BlockBatch
+	Topic     string // 消息所属 topic，为空时不记录 checkpoint（如死信重放）
+	Offset    int64  // 本次 flush 最后一条消息的 offset
+}
+
+// sink 类型
+const (
+	SinkLindorm    = "lindorm"
+	SinkPostgres   = "postgres"
+	SinkClickHouse = "clickhouse" // 分析类旁路存储，异步攒批写入，Write 不等待 ClickHouse
+)
+
+// sink 写入失败时的处理策略
+const (
+	SinkOnErrorFail   = "fail"   // 整个 flush 失败，保留数据并重试，不提交 offset（默认）
+	SinkOnErrorIgnore = "ignore" // 只记录日志和指标，不影响 offset 提交（适合分析类旁路存储）
+)
+
+// SinkDeps 是构建 sink 所需的外部依赖
+type SinkDeps struct {
+	DB         *sql.DB
+	Dialect    db.DBType             // DB 的 SQL 方言，需与 sink 类型一致
+	Redis      redis.UniversalClient // 热点池子缓存（可为 nil）
+	PoolCache  config.PoolCacheConf
+	ClickHouse config.ClickHouseConf
+	Holders    *HolderStats       // 写入 balance 时增量维护 token 持有人数（可为 nil）
+	TokenStats *TokenStatsTracker // 写入事件时重算 token 分钟交易汇总（可为 nil）
+}
+
+// NewSink 按配置构建 sink，多个 sink 时并行扇出写入；未配置时默认只写 Lindorm
+func NewSink(confs []config.SinkConf, deps SinkDeps) (Sink, error) {
+	if len(confs) == 0 {
+		primary := SinkLindorm
+		if deps.Dialect == db.PG {
+			primary = SinkPostgres
+		}
+		confs = []config.SinkConf{{Type: primary, OnError: SinkOnErrorFail}}
+	}
+	handler.SetDialect(deps.Dialect)
+
+	sinks := make([]policySink, 0, len(confs))
+	for _, conf := range confs {
+		onError := conf.OnError
+		if onError == "" {
+			onError = SinkOnErrorFail
+			if conf.Type == SinkClickHouse {
+				// 旁路存储默认不影响主存储的 offset 提交
+				onError = SinkOnErrorIgnore
+			}
+		}
+		if onError != SinkOnErrorFail && onError != SinkOnErrorIgnore {
+			closeSinks(sinks)
+			return nil, fmt.Errorf("invalid sink on_error: %s (must be fail or ignore)", conf.OnError)
+		}
+
+		var s Sink
+		switch conf.Type {
+		case SinkLindorm, SinkPostgres:
+			// 主存储连接只有一个，sink 类型需与 storage 配置一致
+			if deps.DB == nil || (conf.Type == SinkPostgres) != (deps.Dialect == db.PG) {
+				closeSinks(sinks)
+				return nil, fmt.Errorf("sink %s does not match storage %s", conf.Type, deps.Dialect)
+			}
+			sqlSink := NewSQLSink(conf.Type, deps.DB, deps.Redis)
+			sqlSink.holders = deps.Holders
+			sqlSink.stats = deps.TokenStats
+			sqlSink.setPoolCache(deps.PoolCache)
+			s = sqlSink
+		case SinkClickHouse:
+			chSink, err := NewClickHouseSink(deps.ClickHouse)
+			if err != nil {
+				closeSinks(sinks)
+				return nil, fmt.Errorf("init clickhouse sink: %w", err)
+			}
+			s = chSink
+		default:
+			closeSinks(sinks)
+			return nil, fmt.Errorf("unknown sink type: %s", conf.Type)
+		}
+		sinks = append(sinks, policySink{Sink: s, onError: onError, nonBlocking: conf.Type == SinkClickHouse})
+		logger.Infof("sink enabled: type=%s, on_error=%s", conf.Type, onError)
+	}
+
+	if len(sinks) == 1 && sinks[0].onError == SinkOnErrorFail && !sinks[0].nonBlocking {
+		return sinks[0].Sink, nil
+	}
+	return &MultiSink{sinks: sinks}, nil
+}
+
+type policySink struct {
+	Sink
+	onError string
+	// nonBlocking 表示 Write 只在内存中转换排队、不等待外部存储，
+	// MultiSink 在调用方协程中顺序调用，保持分区内 flush 的顺序，不参与并行等待
+	nonBlocking bool
+}
+
+// MultiSink 将同一次 flush 并行写入多个 sink，按各自的错误策略汇总结果
+type MultiSink struct {
+	sinks []policySink
+}
+
+func (m *MultiSink) Name() string {
+	return "multi"
+}
+
+func (m *MultiSink) Write(ctx context.Context, f *Flush) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	write := func(s policySink) {
+		err := s.Write(ctx, f)
+		if err == nil {
+			return
+		}
+		sinkErrorsTotal.WithLabelValues(f.Router.String(), s.Name(), s.onError).Inc()
+		if s.onError == SinkOnErrorIgnore {
+			logger.Warnf("[partition=%d] sink %s write failed, ignored: %v", f.Partition, s.Name(), err)
+			return
+		}
+		mu.Lock()
+		errs = append(errs, fmt.Errorf("sink %s: %w", s.Name(), err))
+		mu.Unlock()
+	}
+
+	for _, s := range m.sinks {
+		if s.nonBlocking {
+			continue
+		}
+		wg.Add(1)
+		go func(s policySink) {
+			defer wg.Done()
+			write(s)
+		}(s)
+	}
+	// 非阻塞 sink 在其他 sink 写入期间完成排队，不增加 flush 耗时
+	for _, s := range m.sinks {
+		if s.nonBlocking {
+			write(s)
+		}
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func (m *MultiSink) Close() error {
+	return closeSinks(m.sinks)
+}
+
+func closeSinks(sinks []policySink) error {
+	var errs []error
+	for _, s := range sinks {
+		if err := s.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close sink %s: %w", s.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
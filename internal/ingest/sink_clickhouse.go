@@ -0,0 +1,626 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"dex-ingest-sol/internal/config"
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/logger"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ClickHouse 表名，建表语句见 schema/clickhouse
+const (
+	chTableChainEvent    = "chain_event"
+	chTableTransferEvent = "transfer_event"
+	chTableBalanceChange = "balance_change"
+	chTableSinkOffset    = "sink_offset"
+)
+
+// ClickHouse sink 默认参数
+const (
+	defaultClickHouseTimeout       = 30 * time.Second
+	defaultClickHouseBatchRows     = 50000
+	defaultClickHouseFlushInterval = 5 * time.Second
+	defaultClickHouseQueueSize     = 256
+	defaultClickHouseSpoolPath     = "./clickhouse/spool.jsonl"
+	clickHouseCloseTimeout         = 30 * time.Second
+)
+
+type chChainEvent struct {
+	EventID     int64   `json:"event_id"`
+	Slot        uint64  `json:"slot"`
+	EventType   int16   `json:"event_type"`
+	Dex         int16   `json:"dex"`
+	UserWallet  string  `json:"user_wallet"`
+	ToWallet    string  `json:"to_wallet"`
+	PoolAddress string  `json:"pool_address"`
+	Token       string  `json:"token"`
+	QuoteToken  string  `json:"quote_token"`
+	TokenAmount string  `json:"token_amount"`
+	QuoteAmount string  `json:"quote_amount"`
+	VolumeUsd   float64 `json:"volume_usd"`
+	PriceUsd    float64 `json:"price_usd"`
+	TxHash      string  `json:"tx_hash"`
+	Signer      string  `json:"signer"`
+	BlockTime   int32   `json:"block_time"`
+	CreateAt    int32   `json:"create_at"`
+}
+
+type chTransferEvent struct {
+	EventID    int64  `json:"event_id"`
+	Slot       uint64 `json:"slot"`
+	FromWallet string `json:"from_wallet"`
+	ToWallet   string `json:"to_wallet"`
+	Token      string `json:"token"`
+	Amount     string `json:"amount"`
+	Decimals   int16  `json:"decimals"`
+	TxHash     string `json:"tx_hash"`
+	Signer     string `json:"signer"`
+	BlockTime  int32  `json:"block_time"`
+	CreateAt   int32  `json:"create_at"`
+}
+
+type chBalanceChange struct {
+	AccountAddress string `json:"account_address"`
+	OwnerAddress   string `json:"owner_address"`
+	TokenAddress   string `json:"token_address"`
+	Balance        string `json:"balance"`
+	EventID        int64  `json:"event_id"`
+	Slot           uint64 `json:"slot"`
+	CreateAt       int32  `json:"create_at"`
+}
+
+type chSinkOffset struct {
+	Sink      string `json:"sink"`
+	Router    string `json:"router"`
+	Partition int32  `json:"partition"`
+	Slot      uint64 `json:"slot"`
+	UpdateAt  int32  `json:"update_at"`
+}
+
+type chPartitionKey struct {
+	router    string
+	partition int32
+}
+
+// chFlush 是一次 worker flush 转换后的待写入数据（已编码为 JSONEachRow 行）
+type chFlush struct {
+	key       chPartitionKey
+	maxSlot   uint64 // 本次实时区块的最大 slot，写入成功后记为该分区的 offset
+	rollbacks []*SlotRollback
+	events    [][]byte
+	transfers [][]byte
+	balances  [][]byte
+}
+
+func (f *chFlush) rows() int {
+	return len(f.events) + len(f.transfers) + len(f.balances)
+}
+
+// ClickHouseSink 将交易、转账和余额变更写入 ClickHouse，供分析类聚合查询使用。
+//
+// 与主存储不同，Write 只把数据转换后放入队列即返回，由后台协程按 batch_rows / flush_interval
+// 攒批写入；写入失败时退避重试，不丢弃数据。Write 从不等待 ClickHouse：队列满时转存到本地积压文件
+// spool_path，此后到达的 flush 也追加到积压文件以保持顺序，队列写空后由后台协程读回积压数据补写。
+// 退出时来不及写入的数据（队列和缓冲）同样转存，下次启动时先补写积压数据再处理新数据；
+// 进程被强制终止时内存中尚未写入的数据仍会丢失。
+// 每个分区已写入的最大实时 slot 记录在 sink_offset 表中，重启后 Kafka 重放的旧区块直接跳过；
+// 表使用 ReplacingMergeTree，少量重复写入会在合并时去重。
+type ClickHouseSink struct {
+	client *db.ClickHouseClient
+	conf   config.ClickHouseConf
+	queue  chan *chFlush
+	done   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	offsets map[chPartitionKey]uint64
+
+	// overflow 表示积压文件中有待补写的数据，此时新的 flush 追加到积压文件而不是队列，保持写入顺序
+	spoolMu  sync.Mutex
+	overflow bool
+	closed   bool
+
+	// 以下字段只由 run 协程访问
+	pending     []*chFlush
+	pendingRows int
+}
+
+func NewClickHouseSink(conf config.ClickHouseConf) (*ClickHouseSink, error) {
+	if conf.Addr == "" {
+		return nil, fmt.Errorf("clickhouse addr is required")
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = defaultClickHouseTimeout
+	}
+	if conf.BatchRows <= 0 {
+		conf.BatchRows = defaultClickHouseBatchRows
+	}
+	if conf.FlushInterval <= 0 {
+		conf.FlushInterval = defaultClickHouseFlushInterval
+	}
+	if conf.QueueSize <= 0 {
+		conf.QueueSize = defaultClickHouseQueueSize
+	}
+	if conf.SpoolPath == "" {
+		conf.SpoolPath = defaultClickHouseSpoolPath
+	}
+
+	// 积压文件读取失败时不能启动，否则补写成功后删除文件会丢掉其中的数据
+	restored, err := loadSpool(conf.SpoolPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(restored) > 0 {
+		logger.Infof("clickhouse spool loaded, %d flushes to rewrite from %s", len(restored), conf.SpoolPath)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &ClickHouseSink{
+		client:   db.NewClickHouseClient(strings.TrimRight(conf.Addr, "/"), conf.Database, conf.User, conf.Password, conf.Timeout),
+		conf:     conf,
+		queue:    make(chan *chFlush, conf.QueueSize),
+		done:     make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+		offsets:  make(map[chPartitionKey]uint64),
+		overflow: len(restored) > 0,
+	}
+
+	// ClickHouse 不可用时不阻止启动，只是无法跳过重放的区块
+	if err := s.loadOffsets(ctx); err != nil {
+		logger.Warnf("clickhouse load offsets failed, replayed blocks will be rewritten: %v", err)
+	}
+
+	go s.run()
+	return s, nil
+}
+
+func (s *ClickHouseSink) Name() string {
+	return SinkClickHouse
+}
+
+func (s *ClickHouseSink) Write(ctx context.Context, f *Flush) error {
+	cf, err := s.convert(f)
+	if err != nil {
+		return err
+	}
+	if cf == nil {
+		return nil
+	}
+
+	// 不等待队列：队列满、已有积压数据或 sink 已关闭时直接转存到积压文件
+	s.spoolMu.Lock()
+	defer s.spoolMu.Unlock()
+	if !s.overflow && !s.closed {
+		select {
+		case s.queue <- cf:
+			clickhouseQueueLength.Set(float64(len(s.queue)))
+			return nil
+		default:
+			logger.Warnf("clickhouse queue full, spooling flushes to %s until the queue drains", s.conf.SpoolPath)
+		}
+	}
+	return s.spillLocked([]*chFlush{cf})
+}
+
+// Close 停止接收新数据，写完队列和缓冲中的数据后退出，超时则将剩余数据转存到积压文件
+func (s *ClickHouseSink) Close() error {
+	s.spoolMu.Lock()
+	s.closed = true
+	close(s.queue)
+	s.spoolMu.Unlock()
+
+	select {
+	case <-s.done:
+	case <-time.After(clickHouseCloseTimeout):
+		logger.Warnf("clickhouse sink close timeout, pending data will be spooled to %s", s.conf.SpoolPath)
+		s.cancel()
+		<-s.done
+	}
+	s.cancel()
+	return nil
+}
+
+// convert 将 flush 中的 batch 转换为 ClickHouse 行，已写入过的实时区块会被跳过
+func (s *ClickHouseSink) convert(f *Flush) (*chFlush, error) {
+	key := chPartitionKey{router: f.Router.String(), partition: f.Partition}
+	offset := s.offset(key)
+
+	cf := &chFlush{key: key}
+	now := int32(time.Now().Unix())
+	for _, b := range f.Batches {
+		if b.Rollback != nil {
+			cf.rollbacks = append(cf.rollbacks, b.Rollback)
+		}
+		if b.Superseded || (b.IsGrpc && b.Slot <= offset && b.Rollback == nil) {
+			continue
+		}
+		if b.IsGrpc && b.Slot > cf.maxSlot {
+			cf.maxSlot = b.Slot
+		}
+
+		for _, e := range b.Events {
+			row, err := json.Marshal(&chChainEvent{
+				EventID:     e.EventID,
+				Slot:        b.Slot,
+				EventType:   e.EventType,
+				Dex:         e.Dex,
+				UserWallet:  e.UserWallet,
+				ToWallet:    e.ToWallet,
+				PoolAddress: e.PoolAddress,
+				Token:       e.Token,
+				QuoteToken:  e.QuoteToken,
+				TokenAmount: e.TokenAmount,
+				QuoteAmount: e.QuoteAmount,
+				VolumeUsd:   e.VolumeUsd,
+				PriceUsd:    e.PriceUsd,
+				TxHash:      e.TxHash,
+				Signer:      e.Signer,
+				BlockTime:   e.BlockTime,
+				CreateAt:    e.CreateAt,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("marshal chain_event %d: %w", e.EventID, err)
+			}
+			cf.events = append(cf.events, row)
+		}
+
+		for _, t := range b.Transfers {
+			row, err := json.Marshal(&chTransferEvent{
+				EventID:    t.EventID,
+				Slot:       b.Slot,
+				FromWallet: t.FromWallet,
+				ToWallet:   t.ToWallet,
+				Token:      t.Token,
+				Amount:     t.Amount,
+				Decimals:   t.Decimals,
+				TxHash:     t.TxHash,
+				Signer:     t.Signer,
+				BlockTime:  t.BlockTime,
+				CreateAt:   t.CreateAt,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("marshal transfer_event %d: %w", t.EventID, err)
+			}
+			cf.transfers = append(cf.transfers, row)
+		}
+
+		for _, bal := range b.Balances {
+			row, err := json.Marshal(&chBalanceChange{
+				AccountAddress: bal.AccountAddress,
+				OwnerAddress:   bal.OwnerAddress,
+				TokenAddress:   bal.TokenAddress,
+				Balance:        bal.Balance,
+				EventID:        bal.LastEventID,
+				Slot:           b.Slot,
+				CreateAt:       now,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("marshal balance_change %s: %w", bal.AccountAddress, err)
+			}
+			cf.balances = append(cf.balances, row)
+		}
+	}
+
+	if cf.rows() == 0 && len(cf.rollbacks) == 0 {
+		return nil, nil
+	}
+	return cf, nil
+}
+
+// run 按顺序写入积压数据和队列中的数据。写入只会因 sink 关闭超时而失败，此时剩余数据全部转存
+func (s *ClickHouseSink) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.conf.FlushInterval)
+	defer ticker.Stop()
+
+	if err := s.drainSpool(); err != nil {
+		return
+	}
+	for {
+		select {
+		case cf, ok := <-s.queue:
+			if !ok {
+				if err := s.flushPending(); err != nil {
+					s.spillRemaining(nil)
+				}
+				return
+			}
+			clickhouseQueueLength.Set(float64(len(s.queue)))
+
+			if err := s.accept(cf); err != nil {
+				s.spillRemaining(nil)
+				return
+			}
+		case <-ticker.C:
+			if err := s.flushPending(); err != nil {
+				s.spillRemaining(nil)
+				return
+			}
+			if err := s.drainSpool(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// accept 将 flush 放入缓冲，回滚必须在旧区块写入之后、新区块写入之前执行。
+// 失败时 flush 仍留在缓冲中，随缓冲一起转存（补写时回滚会重新执行）
+func (s *ClickHouseSink) accept(cf *chFlush) error {
+	if len(cf.rollbacks) > 0 {
+		err := s.flushPending()
+		if err == nil {
+			err = s.rollback(cf)
+		}
+		if err != nil {
+			s.pending = append(s.pending, cf)
+			return err
+		}
+	}
+	s.pending = append(s.pending, cf)
+	s.pendingRows += cf.rows()
+	if s.pendingRows >= s.conf.BatchRows {
+		return s.flushPending()
+	}
+	return nil
+}
+
+// flushPending 将缓冲中的数据按表合并写入，失败时退避重试直到成功或 sink 关闭；
+// 返回错误时缓冲保持不变（部分表可能已写入，补写时由 ReplacingMergeTree 去重）
+func (s *ClickHouseSink) flushPending() error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	var events, transfers, balances [][]byte
+	offsets := make(map[chPartitionKey]uint64)
+	for _, cf := range s.pending {
+		events = append(events, cf.events...)
+		transfers = append(transfers, cf.transfers...)
+		balances = append(balances, cf.balances...)
+		if cf.maxSlot > offsets[cf.key] {
+			offsets[cf.key] = cf.maxSlot
+		}
+	}
+
+	start := time.Now()
+	for _, t := range []struct {
+		table string
+		rows  [][]byte
+	}{
+		{chTableChainEvent, events},
+		{chTableTransferEvent, transfers},
+		{chTableBalanceChange, balances},
+	} {
+		if err := s.insert(t.table, t.rows); err != nil {
+			logger.Errorf("clickhouse insert %s failed, %d flushes kept for spooling: %v", t.table, len(s.pending), err)
+			return err
+		}
+	}
+	logger.Infof("clickhouse flush done in %s (events: %d, transfers: %d, balances: %d)",
+		time.Since(start), len(events), len(transfers), len(balances))
+
+	s.pending = s.pending[:0]
+	s.pendingRows = 0
+	s.saveOffsets(offsets)
+	return nil
+}
+
+// spillRemaining 将缓冲、rest 和队列中剩余的数据按顺序转存到积压文件，只在 run 退出前调用（队列已关闭）
+func (s *ClickHouseSink) spillRemaining(rest []*chFlush) {
+	flushes := append(s.pending, rest...)
+	for cf := range s.queue {
+		flushes = append(flushes, cf)
+	}
+	s.pending = nil
+	s.pendingRows = 0
+	if len(flushes) == 0 {
+		return
+	}
+
+	// 积压文件中的数据晚于缓冲和队列，需排在它们之后
+	s.spoolMu.Lock()
+	defer s.spoolMu.Unlock()
+	if !s.overflow {
+		_ = s.spillLocked(flushes)
+		return
+	}
+	if err := prependSpool(s.conf.SpoolPath, flushes); err != nil {
+		logger.Errorf("clickhouse spool %d flushes before existing spool failed, appending instead: %v", len(flushes), err)
+		_ = s.spillLocked(flushes)
+		return
+	}
+	rows := 0
+	for _, cf := range flushes {
+		rows += cf.rows()
+	}
+	clickhouseSpooledRowsTotal.Add(float64(rows))
+	logger.Warnf("clickhouse %d flushes (%d rows) spooled to %s", len(flushes), rows, s.conf.SpoolPath)
+}
+
+// spillLocked 追加写入积压文件，队列写空后或下次启动时补写，调用方需持有 spoolMu
+func (s *ClickHouseSink) spillLocked(flushes []*chFlush) error {
+	if len(flushes) == 0 {
+		return nil
+	}
+	rows := 0
+	for _, cf := range flushes {
+		rows += cf.rows()
+	}
+
+	s.overflow = true
+	if err := appendSpool(s.conf.SpoolPath, flushes); err != nil {
+		logger.Errorf("clickhouse spool %d flushes (%d rows) failed: %v", len(flushes), rows, err)
+		return fmt.Errorf("clickhouse spool: %w", err)
+	}
+	clickhouseSpooledRowsTotal.Add(float64(rows))
+	logger.Warnf("clickhouse %d flushes (%d rows) spooled to %s", len(flushes), rows, s.conf.SpoolPath)
+	return nil
+}
+
+// drainSpool 在队列写空后读回积压数据并按顺序补写，只由 run 协程调用。
+// 积压期间的 flush 都追加到积压文件，队列中的数据更早，必须先写完队列。
+// 返回错误表示 sink 已关闭，剩余数据已重新转存，run 需退出
+func (s *ClickHouseSink) drainSpool() error {
+	s.spoolMu.Lock()
+	if !s.overflow || len(s.queue) > 0 {
+		s.spoolMu.Unlock()
+		return nil
+	}
+	restored, err := loadSpool(s.conf.SpoolPath)
+	if err == nil {
+		err = os.Remove(s.conf.SpoolPath)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		// 积压文件保持不变，下次再试
+		s.spoolMu.Unlock()
+		logger.Warnf("clickhouse read spool failed, retry later: %v", err)
+		return nil
+	}
+	s.overflow = false
+	s.spoolMu.Unlock()
+
+	if len(restored) == 0 {
+		return nil
+	}
+	logger.Infof("clickhouse rewriting %d spooled flushes from %s", len(restored), s.conf.SpoolPath)
+	for i, cf := range restored {
+		if err := s.accept(cf); err != nil {
+			s.spillRemaining(restored[i+1:])
+			return err
+		}
+	}
+	if err := s.flushPending(); err != nil {
+		s.spillRemaining(nil)
+		return err
+	}
+	logger.Infof("clickhouse spool rewritten: %s", s.conf.SpoolPath)
+	return nil
+}
+
+func (s *ClickHouseSink) insert(table string, rows [][]byte) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	err := db.RetryWithBackoff(s.ctx, func() error {
+		err := s.client.InsertJSONEachRow(s.ctx, table, rows)
+		if err != nil {
+			clickhouseWriteFailuresTotal.WithLabelValues(table).Inc()
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	clickhouseRowsTotal.WithLabelValues(table).Add(float64(len(rows)))
+	return nil
+}
+
+// rollback 删除分叉旧区块写入的行（轻量删除，按事件 ID / 账户 + slot 定位）
+func (s *ClickHouseSink) rollback(cf *chFlush) error {
+	for _, rb := range cf.rollbacks {
+		var stmts []string
+		if ids := eventIDList(rb.Events); ids != "" {
+			stmts = append(stmts, fmt.Sprintf("DELETE FROM %s WHERE event_id IN (%s)", chTableChainEvent, ids))
+		}
+		if ids := eventIDList(rb.Transfers); ids != "" {
+			stmts = append(stmts, fmt.Sprintf("DELETE FROM %s WHERE event_id IN (%s)", chTableTransferEvent, ids))
+		}
+		if len(rb.Accounts) > 0 {
+			stmts = append(stmts, fmt.Sprintf("DELETE FROM %s WHERE slot = %d AND account_address IN (%s)",
+				chTableBalanceChange, rb.Slot, quoteList(rb.Accounts)))
+		}
+
+		for _, stmt := range stmts {
+			err := db.RetryWithBackoff(s.ctx, func() error {
+				return s.client.Exec(s.ctx, stmt)
+			})
+			if err != nil {
+				logger.Errorf("[partition=%d] clickhouse rollback slot %d failed: %v", cf.key.partition, rb.Slot, err)
+				return err
+			}
+		}
+		logger.Infof("[partition=%d] clickhouse slot %d rolled back (old hash %s)", cf.key.partition, rb.Slot, rb.OldHash)
+	}
+	return nil
+}
+
+func (s *ClickHouseSink) offset(key chPartitionKey) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.offsets[key]
+}
+
+// saveOffsets 更新内存中的分区 offset 并持久化到 sink_offset 表，持久化失败只影响重启后的跳过范围
+func (s *ClickHouseSink) saveOffsets(offsets map[chPartitionKey]uint64) {
+	now := int32(time.Now().Unix())
+	rows := make([][]byte, 0, len(offsets))
+
+	s.mu.Lock()
+	for key, slot := range offsets {
+		if slot <= s.offsets[key] {
+			continue
+		}
+		s.offsets[key] = slot
+		clickhouseOffsetSlot.WithLabelValues(key.router, strconv.Itoa(int(key.partition))).Set(float64(slot))
+
+		row, _ := json.Marshal(&chSinkOffset{Sink: SinkClickHouse, Router: key.router, Partition: key.partition, Slot: slot, UpdateAt: now})
+		rows = append(rows, row)
+	}
+	s.mu.Unlock()
+
+	if err := s.client.InsertJSONEachRow(s.ctx, chTableSinkOffset, rows); err != nil {
+		logger.Warnf("clickhouse save offsets failed: %v", err)
+	}
+}
+
+func (s *ClickHouseSink) loadOffsets(ctx context.Context) error {
+	query := fmt.Sprintf("SELECT router, partition, max(slot) AS slot FROM %s FINAL WHERE sink = '%s' "+
+		"GROUP BY router, partition SETTINGS output_format_json_quote_64bit_integers = 0 FORMAT JSONEachRow",
+		chTableSinkOffset, SinkClickHouse)
+	data, err := s.client.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var row chSinkOffset
+		if err := dec.Decode(&row); err != nil {
+			return fmt.Errorf("decode sink offset: %w", err)
+		}
+		s.offsets[chPartitionKey{router: row.Router, partition: row.Partition}] = row.Slot
+		logger.Infof("clickhouse offset loaded, router=%s, partition=%d, slot=%d", row.Router, row.Partition, row.Slot)
+	}
+	return nil
+}
+
+func eventIDList(keys []model.EventKey) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	ids := make([]string, 0, len(keys))
+	for _, k := range keys {
+		ids = append(ids, strconv.FormatInt(k.EventID, 10))
+	}
+	return strings.Join(ids, ",")
+}
+
+func quoteList(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, "'"+strings.ReplaceAll(v, "'", `\'`)+"'")
+	}
+	return strings.Join(quoted, ",")
+}
@@ -0,0 +1,608 @@
+package ingest
+
+import (
+	"context"
+	"database/sql"
+	"dex-ingest-sol/internal/config"
+	"dex-ingest-sol/internal/ingest/handler"
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/logger"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SQLSink 将数据写入主存储（Lindorm 或 PostgreSQL），SQL 方言由 handler.SetDialect 决定
+type SQLSink struct {
+	name    string
+	db      *sql.DB
+	redis   redis.UniversalClient
+	holders *HolderStats       // token 持有人数增量维护（仅 balance，可为 nil）
+	stats   *TokenStatsTracker // token 分钟交易汇总重算（仅 event，可为 nil）
+	candles *candleSweeper     // K 线延迟再重算，修正多实例并发重算的覆盖
+
+	poolCacheSize int           // 每个池子缓存的最近事件数
+	poolCacheTTL  time.Duration // 池子缓存过期时间
+}
+
+const (
+	defaultPoolCacheSize = 200
+	defaultPoolCacheTTL  = 10 * time.Minute
+)
+
+func NewSQLSink(name string, db *sql.DB, redis redis.UniversalClient) *SQLSink {
+	return &SQLSink{
+		name:          name,
+		db:            db,
+		redis:         redis,
+		candles:       newCandleSweeper(db),
+		poolCacheSize: defaultPoolCacheSize,
+		poolCacheTTL:  defaultPoolCacheTTL,
+	}
+}
+
+// setPoolCache 设置热点池子缓存参数，未配置的项使用默认值
+func (s *SQLSink) setPoolCache(conf config.PoolCacheConf) {
+	if conf.Size > 0 {
+		s.poolCacheSize = conf.Size
+	}
+	if conf.TTL > 0 {
+		s.poolCacheTTL = conf.TTL
+	}
+}
+
+func (s *SQLSink) Name() string {
+	return s.name
+}
+
+func (s *SQLSink) Write(ctx context.Context, f *Flush) error {
+	f = uniqueBatches(f)
+
+	// 分叉回滚必须先于新数据写入
+	if err := s.rollbackSlots(ctx, f); err != nil {
+		return err
+	}
+
+	// 先记录 slot → block_hash 和写入主键：数据部分写入后崩溃，重启后出现分叉时也能据此删除
+	blocks := s.buildSlotBlocks(f)
+	start := time.Now()
+	err := handler.InsertSlotBlocks(ctx, s.db, blocks)
+	observeInsert(f, "slot_block", len(blocks), start, err)
+	if err != nil {
+		logger.Errorf("[partition=%d] insertSlotBlocks error: %v", f.Partition, err)
+		return fmt.Errorf("insertSlotBlocks: %w", err)
+	}
+
+	switch f.Router {
+	case RouterEvent:
+		err = s.flushEventBatches(ctx, f)
+	case RouterBalance:
+		err = s.flushBalanceBatches(ctx, f)
+	default:
+		err = fmt.Errorf("unknown RouterType: %v", f.Router)
+	}
+	if err != nil {
+		return err
+	}
+
+	// checkpoint 最后写入：只有该次 flush 的数据全部落库后才前移
+	if f.Topic != "" {
+		if err = handler.UpsertCheckpoint(ctx, s.db, buildCheckpoint(f)); err != nil {
+			logger.Errorf("[partition=%d] upsertCheckpoint error: %v", f.Partition, err)
+			return fmt.Errorf("upsertCheckpoint: %w", err)
+		}
+	}
+	return nil
+}
+
+func buildCheckpoint(f *Flush) *model.Checkpoint {
+	var maxSlot uint64
+	for _, b := range f.Batches {
+		maxSlot = max(maxSlot, b.Slot)
+	}
+	return &model.Checkpoint{
+		Router:    f.Router.String(),
+		Topic:     f.Topic,
+		Partition: f.Partition,
+		Offset:    f.Offset,
+		MaxSlot:   int64(maxSlot),
+		UpdateAt:  int32(time.Now().Unix()),
+	}
+}
+
+// Close 数据库连接由 ServiceContext 统一管理，这里无需关闭
+func (s *SQLSink) Close() error {
+	s.candles.stop()
+	return nil
+}
+
+// uniqueBatches 去掉同一次 flush 中重复投递的区块（slot 和 block_hash 都相同）。
+// PostgreSQL 不允许一条 INSERT ... ON CONFLICT DO UPDATE 中出现重复主键。
+func uniqueBatches(f *Flush) *Flush {
+	type blockKey struct {
+		slot uint64
+		hash string
+	}
+	seen := make(map[blockKey]struct{}, len(f.Batches))
+	batches := make([]*BlockBatch, 0, len(f.Batches))
+	for _, b := range f.Batches {
+		key := blockKey{slot: b.Slot, hash: b.BlockHash}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		batches = append(batches, b)
+	}
+	if len(batches) == len(f.Batches) {
+		return f
+	}
+
+	dedup := *f
+	dedup.Batches = batches
+	return &dedup
+}
+
+func (s *SQLSink) flushEventBatches(ctx context.Context, f *Flush) error {
+	var eventCount, poolCount, tokenCount, migrateCount, transferCount, tradeCount int
+
+	// 第一次遍历：统计容量
+	for _, b := range f.Batches {
+		eventCount += len(b.Events)
+		poolCount += len(b.Pools)
+		tokenCount += len(b.Tokens)
+		migrateCount += len(b.Migrates)
+		transferCount += len(b.Transfers)
+		tradeCount += len(b.Trades)
+	}
+
+	// 分配内存
+	chainEvents := make([]*model.ChainEvent, 0, eventCount)
+	pools := make([]*model.Pool, 0, poolCount)
+	tokens := make([]*model.Token, 0, tokenCount)
+	migrations := make([]*model.Migration, 0, migrateCount)
+	transferEvents := make([]*model.TransferEvent, 0, transferCount)
+	walletTrades := make([]*model.WalletTrade, 0, tradeCount)
+
+	// 第二次遍历：聚合数据
+	for _, b := range f.Batches {
+		chainEvents = append(chainEvents, b.Events...)
+		pools = append(pools, b.Pools...)
+		tokens = append(tokens, b.Tokens...)
+		migrations = append(migrations, b.Migrates...)
+		transferEvents = append(transferEvents, b.Transfers...)
+		walletTrades = append(walletTrades, b.Trades...)
+	}
+	quotePrices := uniqueQuotePrices(f.Batches)
+	poolStates := uniquePoolStates(f.Batches)
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	addErr := func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+
+	// 写入 ChainEvent
+	if len(chainEvents) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := handler.InsertChainEvents(ctx, s.db, chainEvents)
+			observeInsert(f, "chain_event", len(chainEvents), start, err)
+			if err != nil {
+				logger.Errorf("[partition=%d] insertChainEvents error: %v", f.Partition, err)
+				addErr(fmt.Errorf("insertChainEvents: %w", err))
+				return
+			}
+			logger.Infof("[partition=%d] insertChainEvents done in %s", f.Partition, time.Since(start))
+
+			// Redis 同步缓存（如启用），只在 chain_event 写入成功后更新，缓存中不会出现库里没有的事件
+			if s.redis != nil {
+				s.syncPoolCache(ctx, f)
+			}
+		}()
+	}
+
+	// 写入 Pool 数据
+	if len(pools) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := handler.InsertPools(ctx, s.db, pools)
+			observeInsert(f, "pool", len(pools), start, err)
+			if err != nil {
+				logger.Errorf("[partition=%d] insertPools error: %v", f.Partition, err)
+				addErr(fmt.Errorf("insertPools: %w", err))
+			}
+			logger.Infof("[partition=%d] insertPools done in %s", f.Partition, time.Since(start))
+		}()
+	}
+
+	// 写入 Token 数据
+	if len(tokens) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := handler.InsertTokens(ctx, s.db, tokens)
+			observeInsert(f, "token", len(tokens), start, err)
+			if err != nil {
+				logger.Errorf("[partition=%d] insertTokens error: %v", f.Partition, err)
+				addErr(fmt.Errorf("insertTokens: %w", err))
+			}
+			logger.Infof("[partition=%d] insertTokens done in %s (%d tokens)", f.Partition, time.Since(start), len(tokens))
+		}()
+	}
+
+	// 写入 Migration
+	if len(migrations) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := handler.InsertMigrations(ctx, s.db, migrations)
+			observeInsert(f, "migration", len(migrations), start, err)
+			if err != nil {
+				logger.Errorf("[partition=%d] insertMigrations error: %v", f.Partition, err)
+				addErr(fmt.Errorf("insertMigrations: %w", err))
+			}
+			logger.Infof("[partition=%d] insertMigrations done in %s (%d migrations)", f.Partition, time.Since(start), len(migrations))
+		}()
+	}
+
+	// 写入 TransferEvent
+	if len(transferEvents) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := handler.InsertTransferEvents(ctx, s.db, transferEvents)
+			observeInsert(f, "transfer_event", len(transferEvents), start, err)
+			if err != nil {
+				logger.Errorf("[partition=%d] insertTransferEvents error: %v", f.Partition, err)
+				addErr(fmt.Errorf("insertTransferEvents: %w", err))
+			}
+			logger.Infof("[partition=%d] insertTransferEvents done in %s", f.Partition, time.Since(start))
+		}()
+	}
+
+	// 写入池子最新储备量
+	if len(poolStates) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := handler.UpsertPoolStates(ctx, s.db, poolStates)
+			observeInsert(f, "pool_state", len(poolStates), start, err)
+			if err != nil {
+				logger.Errorf("[partition=%d] upsertPoolStates error: %v", f.Partition, err)
+				addErr(fmt.Errorf("upsertPoolStates: %w", err))
+			}
+		}()
+	}
+
+	// 写入报价币价格
+	if len(quotePrices) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := handler.InsertQuotePrices(ctx, s.db, quotePrices)
+			observeInsert(f, "quote_price", len(quotePrices), start, err)
+			if err != nil {
+				logger.Errorf("[partition=%d] insertQuotePrices error: %v", f.Partition, err)
+				addErr(fmt.Errorf("insertQuotePrices: %w", err))
+			}
+		}()
+	}
+
+	// 等待所有任务完成（Redis 缓存同步失败不影响 offset 提交）
+	wg.Wait()
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	// K 线、token 分钟汇总和钱包持仓由已写入的 chain_event 重算，放在其它表全部成功之后
+	if err := s.recomputeCandles(ctx, f, handler.TouchedCandleMinutes(chainEvents)); err != nil {
+		return err
+	}
+	if err := s.recomputeTokenMinutes(ctx, f, handler.TouchedTokenMinutes(chainEvents)); err != nil {
+		return err
+	}
+	return s.recomputeWalletPositions(ctx, f, handler.TouchedWalletPositions(walletTrades), walletTrades)
+}
+
+// recomputeWalletPositions 重算本次 flush 涉及的钱包持仓，重复执行结果相同，flush 重试时直接重算
+func (s *SQLSink) recomputeWalletPositions(ctx context.Context, f *Flush, keys []handler.WalletPositionKey, trades []*model.WalletTrade) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	start := time.Now()
+	err := handler.RecomputeWalletPositions(ctx, s.db, keys, trades)
+	observeInsert(f, "wallet_position", len(keys), start, err)
+	if err != nil {
+		logger.Errorf("[partition=%d] recomputeWalletPositions error: %v", f.Partition, err)
+		return fmt.Errorf("recomputeWalletPositions: %w", err)
+	}
+	return nil
+}
+
+// syncPoolCache 同步热点池子缓存：实时区块的事件写入缓存，补块事件涉及的池子删除缓存（旧事件会在缓存中造成缺口）。
+// 缓存只影响查询，失败不阻塞写入：写入失败的池子删除缓存，删除也失败时残留数据随 key 到期消失
+func (s *SQLSink) syncPoolCache(ctx context.Context, f *Flush) {
+	var hotEvents, staleEvents []*model.ChainEvent
+	for _, b := range f.Batches {
+		if b.isRealtime(f.LastSlot) {
+			hotEvents = append(hotEvents, b.Events...)
+		} else {
+			staleEvents = append(staleEvents, b.Events...)
+		}
+	}
+	// 先删除再写入，同时有补块和实时事件的池子只保留本次的实时事件
+	s.invalidatePoolCache(ctx, f, handler.CachedPools(staleEvents))
+	if len(hotEvents) == 0 {
+		return
+	}
+	if err := handler.SyncPoolCache(ctx, s.redis, hotEvents, s.poolCacheSize, s.poolCacheTTL); err != nil {
+		logger.Errorf("[partition=%d] sync pool cache error: %v", f.Partition, err)
+		s.invalidatePoolCache(ctx, f, handler.CachedPools(hotEvents))
+	}
+}
+
+func (s *SQLSink) invalidatePoolCache(ctx context.Context, f *Flush, pools []string) {
+	if err := handler.InvalidatePoolCache(ctx, s.redis, pools); err != nil {
+		logger.Errorf("[partition=%d] invalidate pool cache for %d pools error: %v", f.Partition, len(pools), err)
+	}
+}
+
+// recomputeTokenMinutes 重算本次 flush 涉及的 token 分钟交易汇总（未启用 token_stats 时跳过）
+func (s *SQLSink) recomputeTokenMinutes(ctx context.Context, f *Flush, keys []handler.TokenMinuteKey) error {
+	if s.stats == nil || len(keys) == 0 {
+		return nil
+	}
+	start := time.Now()
+	err := s.stats.Recompute(ctx, keys)
+	observeInsert(f, "token_stats_minute", len(keys), start, err)
+	if err != nil {
+		logger.Errorf("[partition=%d] recomputeTokenMinutes error: %v", f.Partition, err)
+		return fmt.Errorf("recomputeTokenMinutes: %w", err)
+	}
+	return nil
+}
+
+// recomputeCandles 重算本次 flush 涉及的 K 线，重复执行结果相同，flush 重试时直接重算
+func (s *SQLSink) recomputeCandles(ctx context.Context, f *Flush, minutes []handler.CandleMinute) error {
+	if len(minutes) == 0 {
+		return nil
+	}
+	start := time.Now()
+	err := handler.RecomputeCandles(ctx, s.db, minutes)
+	observeInsert(f, "candle", len(minutes), start, err)
+	if err != nil {
+		logger.Errorf("[partition=%d] recomputeCandles error: %v", f.Partition, err)
+		return fmt.Errorf("recomputeCandles: %w", err)
+	}
+	s.candles.mark(minutes)
+	return nil
+}
+
+// uniqueQuotePrices 按 (token, slot) 去重，同一 slot 出现多次（分叉）时以后到的区块为准
+func uniqueQuotePrices(batches []*BlockBatch) []*model.QuotePrice {
+	type priceKey struct {
+		token string
+		slot  int64
+	}
+	var prices []*model.QuotePrice
+	index := make(map[priceKey]int)
+	for _, b := range batches {
+		for _, p := range b.Quotes {
+			key := priceKey{token: p.Token, slot: p.Slot}
+			if i, ok := index[key]; ok {
+				prices[i] = p
+				continue
+			}
+			index[key] = len(prices)
+			prices = append(prices, p)
+		}
+	}
+	return prices
+}
+
+// uniquePoolStates 按池子去重，保留 event_id 最大的状态，并用较早的状态补齐价格
+func uniquePoolStates(batches []*BlockBatch) []*model.PoolState {
+	var states []*model.PoolState
+	index := make(map[string]int)
+	for _, b := range batches {
+		for _, s := range b.States {
+			i, ok := index[s.PoolAddress]
+			if !ok {
+				index[s.PoolAddress] = len(states)
+				states = append(states, s)
+				continue
+			}
+			if s.LastEventID > states[i].LastEventID {
+				handler.MergePoolState(s, states[i])
+				states[i] = s
+			} else {
+				handler.MergePoolState(states[i], s)
+			}
+		}
+	}
+	return states
+}
+
+func (s *SQLSink) flushBalanceBatches(ctx context.Context, f *Flush) error {
+	var (
+		totalBalanceCount              int
+		realtimeCount, historicalCount int
+	)
+
+	for _, b := range f.Batches {
+		n := len(b.Balances)
+		totalBalanceCount += n
+		if b.isRealtime(f.LastSlot) {
+			realtimeCount += n
+		} else {
+			historicalCount += n
+		}
+	}
+	if totalBalanceCount == 0 {
+		return nil
+	}
+
+	realtimeBalances := make([]*model.Balance, 0, realtimeCount)
+	historicalBalances := make([]*model.Balance, 0, historicalCount)
+
+	for _, b := range f.Batches {
+		if b.isRealtime(f.LastSlot) {
+			realtimeBalances = append(realtimeBalances, b.Balances...)
+		} else {
+			historicalBalances = append(historicalBalances, b.Balances...)
+		}
+	}
+
+	logger.Infof("[partition=%d] flushing %d balances (realtime: %d, historical: %d)",
+		f.Partition, totalBalanceCount, len(realtimeBalances), len(historicalBalances))
+
+	var history []*model.BalanceHistory
+	for _, b := range f.Batches {
+		history = append(history, b.History...)
+	}
+
+	write := func() error {
+		if err := s.writeBalances(ctx, f, realtimeBalances, historicalBalances); err != nil {
+			return err
+		}
+		// 追加余额变更历史（主键幂等，重试不会重复写入）
+		if len(history) == 0 {
+			return nil
+		}
+		start := time.Now()
+		err := handler.InsertBalanceHistory(ctx, s.db, history)
+		observeInsert(f, "balance_history", len(history), start, err)
+		if err != nil {
+			logger.Errorf("[partition=%d] insertBalanceHistory error: %v", f.Partition, err)
+			return fmt.Errorf("insertBalanceHistory: %w", err)
+		}
+		return nil
+	}
+	if s.holders != nil {
+		// 持有人数按写入前后的持有状态增量更新，写入失败时涉及的 token 加入对账
+		return s.holders.Track(ctx, holderPairs(f.Batches), write)
+	}
+	return write()
+}
+
+// writeBalances 写入最新余额，实时区块直接覆盖，补块按 last_event_id 过滤
+func (s *SQLSink) writeBalances(ctx context.Context, f *Flush, realtimeBalances, historicalBalances []*model.Balance) error {
+	if len(realtimeBalances) > 0 {
+		start := time.Now()
+		err := handler.InsertBalances(ctx, s.db, realtimeBalances, true)
+		observeInsert(f, "balance", len(realtimeBalances), start, err)
+		if err != nil {
+			logger.Errorf("[partition=%d] insertBalances (realtime) error: %v", f.Partition, err)
+			return fmt.Errorf("insertBalances (realtime): %w", err)
+		}
+		logger.Infof("[partition=%d] insertBalances (realtime) done in %s", f.Partition, time.Since(start))
+	}
+
+	if len(historicalBalances) > 0 {
+		start := time.Now()
+		err := handler.InsertBalances(ctx, s.db, historicalBalances, false)
+		observeInsert(f, "balance", len(historicalBalances), start, err)
+		if err != nil {
+			logger.Errorf("[partition=%d] insertBalances (historical) error: %v", f.Partition, err)
+			return fmt.Errorf("insertBalances (historical): %w", err)
+		}
+		logger.Infof("[partition=%d] insertBalances (historical) done in %s", f.Partition, time.Since(start))
+	}
+	return nil
+}
+
+// rollbackSlots 在写入新数据前删除分叉旧区块写入的行，失败时整个 flush 失败并重试
+func (s *SQLSink) rollbackSlots(ctx context.Context, f *Flush) error {
+	for _, b := range f.Batches {
+		rb := b.Rollback
+		if rb == nil {
+			continue
+		}
+		start := time.Now()
+		if err := handler.DeleteEventsByKeys(ctx, s.db, handler.TableChainEvent, rb.Events); err != nil {
+			return fmt.Errorf("rollback slot %d: %w", rb.Slot, err)
+		}
+		if err := handler.DeleteEventsByKeys(ctx, s.db, handler.TableTransferEvent, rb.Transfers); err != nil {
+			return fmt.Errorf("rollback slot %d: %w", rb.Slot, err)
+		}
+		if err := handler.DeleteEventsByKeys(ctx, s.db, handler.TableMigration, rb.Migrates); err != nil {
+			return fmt.Errorf("rollback slot %d: %w", rb.Slot, err)
+		}
+		if err := handler.DeletePoolStatesByPools(ctx, s.db, rb.Pools, rb.Slot); err != nil {
+			return fmt.Errorf("rollback slot %d: %w", rb.Slot, err)
+		}
+		if err := handler.RestoreBalancesByAccounts(ctx, s.db, rb.Accounts, rb.Slot); err != nil {
+			return fmt.Errorf("rollback slot %d: %w", rb.Slot, err)
+		}
+		if err := handler.DeleteBalanceHistoryByAccounts(ctx, s.db, rb.Accounts, rb.Slot); err != nil {
+			return fmt.Errorf("rollback slot %d: %w", rb.Slot, err)
+		}
+		// 旧区块的成交已删除，按旧区块时间重算其池子的 K 线（新区块的 K 线在写入事件后重算）
+		if err := s.recomputeCandles(ctx, f, handler.CandleMinutesAt(rb.Pools, rb.BlockTime)); err != nil {
+			return fmt.Errorf("rollback slot %d: %w", rb.Slot, err)
+		}
+		if err := s.recomputeTokenMinutes(ctx, f, handler.TokenMinutesAt(rb.Tokens, rb.BlockTime)); err != nil {
+			return fmt.Errorf("rollback slot %d: %w", rb.Slot, err)
+		}
+		if err := s.recomputeWalletPositions(ctx, f, rb.Positions, nil); err != nil {
+			return fmt.Errorf("rollback slot %d: %w", rb.Slot, err)
+		}
+		if s.redis != nil {
+			// 缓存只影响查询，回滚失败时删除涉及池子的整个缓存，删除也失败时残留数据随 key 到期消失
+			if err := handler.RollbackPoolCache(ctx, s.redis, rb.Pools, rb.Slot); err != nil {
+				logger.Errorf("[partition=%d] rollback pool cache for slot %d error: %v", f.Partition, rb.Slot, err)
+				s.invalidatePoolCache(ctx, f, rb.Pools)
+			}
+		}
+		logger.Infof("[partition=%d] slot %d rolled back (old hash %s) in %s",
+			f.Partition, rb.Slot, rb.OldHash, time.Since(start))
+	}
+	return nil
+}
+
+// buildSlotBlocks 生成 slot → block_hash 及写入主键记录（被取代的旧区块不记录）
+func (s *SQLSink) buildSlotBlocks(f *Flush) []*model.SlotBlock {
+	blocks := make([]*model.SlotBlock, 0, len(f.Batches))
+	for _, b := range f.Batches {
+		if b.BlockHash == "" || b.Superseded {
+			continue
+		}
+		source := int16(2)
+		if b.IsGrpc {
+			source = 1
+		}
+		blocks = append(blocks, &model.SlotBlock{
+			Router:       f.Router.String(),
+			Slot:         int64(b.Slot),
+			BlockHash:    b.BlockHash,
+			Source:       source,
+			RollbackKeys: encodeRollbackKeys(collectKeys(b)),
+		})
+	}
+	return blocks
+}
+
+// observeInsert 记录一次 Insert* 调用的耗时，成功时累加写入行数
+func observeInsert(f *Flush, table string, rows int, start time.Time, err error) {
+	insertDuration.WithLabelValues(f.Router.String(), table).Observe(time.Since(start).Seconds())
+	if err == nil {
+		rowsWrittenTotal.WithLabelValues(f.Router.String(), strconv.Itoa(int(f.Partition)), table).Add(float64(rows))
+	}
+}
@@ -0,0 +1,235 @@
+package ingest
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// 每个分区最多保留的缺口记录数，超出后淘汰最早的记录
+	maxTrackedGaps = 256
+	// 每个分区最多保留的待确认区间数，超出后淘汰最早的区间（按跳过处理）
+	maxPendingGaps = 4096
+	// 待确认区间落后最新 slot 超过该距离仍无补块时视为被跳过的 slot（约 1 小时）
+	slotSkipWindow = 9000
+)
+
+// SlotGap 表示分区内一段缺失的 slot 区间 [From, To]
+type SlotGap struct {
+	From       uint64    `json:"from"`
+	To         uint64    `json:"to"`
+	Missing    uint64    `json:"missing"`             // 仍未补齐的 slot 数量
+	DetectedAt time.Time `json:"detected_at"`         // 发现缺口的时间
+	FilledAt   time.Time `json:"filled_at,omitempty"` // 全部补齐的时间（未补齐时为零值）
+
+	remaining []slotRange // 尚未补齐的子区间，按 from 升序
+}
+
+type slotRange struct {
+	from, to uint64
+}
+
+// SlotReport 是分区 slot 连续性的快照，用于监控接口输出
+type SlotReport struct {
+	Router      string    `json:"router"`
+	Partition   int32     `json:"partition"`
+	LastSlot    uint64    `json:"last_slot"`
+	Duplicates  uint64    `json:"duplicates"`
+	Regressions uint64    `json:"regressions"`
+	OpenGaps    int       `json:"open_gaps"`
+	MissingSlot uint64    `json:"missing_slots"`
+	PendingGaps int       `json:"pending_gaps"`
+	Skipped     uint64    `json:"skipped_slots"`
+	Gaps        []SlotGap `json:"gaps"`
+}
+
+// SlotTracker 检测单个分区内的 slot 缺口、乱序和重复：
+//   - slot == last+1：正常推进
+//   - slot > last+1：记录待确认区间 [last+1, slot-1]
+//   - slot == last：重复
+//   - slot < last：命中缺口或待确认区间则视为补块（上游以 source=1 补发），否则视为乱序回退
+//
+// Solana 会正常跳过 slot，且只统计包含事件的消息，空块也会表现为跳跃，
+// 所以跳跃本身不算缺口：待确认区间收到第一个补块后才确认为缺口并计入监控，
+// 超过 slotSkipWindow 仍无补块的区间按被跳过的 slot 丢弃。
+type SlotTracker struct {
+	mu          sync.Mutex
+	router      string
+	partition   int32
+	lastSlot    uint64
+	duplicates  uint64
+	regressions uint64
+	skipped     uint64
+	gaps        []*SlotGap // 已确认的缺口，按确认顺序排列
+	pending     []*SlotGap // 待确认区间，按发现顺序排列
+}
+
+func NewSlotTracker(router RouterType, partition int32) *SlotTracker {
+	return &SlotTracker{router: router.String(), partition: partition}
+}
+
+// Observe 记录一个已消费的 slot
+func (t *SlotTracker) Observe(slot uint64, isGrpc bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	partition := strconv.Itoa(int(t.partition))
+	switch {
+	case t.lastSlot == 0 || slot == t.lastSlot+1:
+		t.lastSlot = slot
+	case slot > t.lastSlot+1:
+		t.addPending(t.lastSlot+1, slot-1)
+		t.lastSlot = slot
+		t.expirePending()
+	case slot == t.lastSlot:
+		t.duplicates++
+		slotDuplicatesTotal.WithLabelValues(t.router, partition).Inc()
+	default:
+		if t.fill(slot, partition) {
+			slotGapFilledTotal.WithLabelValues(t.router, partition, strconv.FormatBool(isGrpc)).Inc()
+		} else {
+			t.regressions++
+			slotRegressionsTotal.WithLabelValues(t.router, partition).Inc()
+		}
+	}
+
+	openGaps, missing := t.openGaps()
+	slotLast.WithLabelValues(t.router, partition).Set(float64(t.lastSlot))
+	slotGapsOpen.WithLabelValues(t.router, partition).Set(float64(openGaps))
+	slotMissing.WithLabelValues(t.router, partition).Set(float64(missing))
+}
+
+func (t *SlotTracker) addPending(from, to uint64) {
+	if len(t.pending) >= maxPendingGaps {
+		t.skip(t.pending[0])
+		t.pending = append(t.pending[:0], t.pending[1:]...)
+	}
+	t.pending = append(t.pending, &SlotGap{
+		From:       from,
+		To:         to,
+		Missing:    to - from + 1,
+		DetectedAt: time.Now(),
+		remaining:  []slotRange{{from: from, to: to}},
+	})
+}
+
+// expirePending 丢弃超出补块窗口仍未收到补块的待确认区间
+func (t *SlotTracker) expirePending() {
+	n := 0
+	for _, gap := range t.pending {
+		if gap.To+slotSkipWindow < t.lastSlot {
+			t.skip(gap)
+			continue
+		}
+		t.pending[n] = gap
+		n++
+	}
+	clear(t.pending[n:])
+	t.pending = t.pending[:n]
+}
+
+func (t *SlotTracker) skip(gap *SlotGap) {
+	t.skipped += gap.Missing
+	slotSkippedTotal.WithLabelValues(t.router, strconv.Itoa(int(t.partition))).Add(float64(gap.Missing))
+}
+
+// confirm 将待确认区间转为缺口
+func (t *SlotTracker) confirm(i int, partition string) {
+	gap := t.pending[i]
+	t.pending = append(t.pending[:i], t.pending[i+1:]...)
+	if len(t.gaps) >= maxTrackedGaps {
+		t.gaps = append(t.gaps[:0], t.gaps[1:]...)
+	}
+	t.gaps = append(t.gaps, gap)
+	slotGapsTotal.WithLabelValues(t.router, partition).Inc()
+}
+
+// fill 将 slot 从所属缺口中移除，返回是否命中缺口；命中待确认区间时先将其确认为缺口
+func (t *SlotTracker) fill(slot uint64, partition string) bool {
+	for _, gap := range t.gaps {
+		if slot >= gap.From && slot <= gap.To && gap.Missing > 0 {
+			return fillGap(gap, slot)
+		}
+	}
+	for i, gap := range t.pending {
+		if slot >= gap.From && slot <= gap.To {
+			t.confirm(i, partition)
+			return fillGap(gap, slot)
+		}
+	}
+	return false
+}
+
+func fillGap(gap *SlotGap, slot uint64) bool {
+	i := sort.Search(len(gap.remaining), func(i int) bool { return gap.remaining[i].to >= slot })
+	if i == len(gap.remaining) || gap.remaining[i].from > slot {
+		return false // 该 slot 已补过，属于重复补块
+	}
+
+	r := gap.remaining[i]
+	switch {
+	case r.from == slot && r.to == slot:
+		gap.remaining = append(gap.remaining[:i], gap.remaining[i+1:]...)
+	case r.from == slot:
+		gap.remaining[i].from++
+	case r.to == slot:
+		gap.remaining[i].to--
+	default:
+		gap.remaining = append(gap.remaining, slotRange{})
+		copy(gap.remaining[i+2:], gap.remaining[i+1:])
+		gap.remaining[i] = slotRange{from: r.from, to: slot - 1}
+		gap.remaining[i+1] = slotRange{from: slot + 1, to: r.to}
+	}
+
+	gap.Missing--
+	if gap.Missing == 0 {
+		gap.FilledAt = time.Now()
+	}
+	return true
+}
+
+func (t *SlotTracker) openGaps() (count int, missing uint64) {
+	for _, gap := range t.gaps {
+		if gap.Missing > 0 {
+			count++
+			missing += gap.Missing
+		}
+	}
+	return
+}
+
+// Report 返回当前状态快照
+func (t *SlotTracker) Report() SlotReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	openGaps, missing := t.openGaps()
+	gaps := make([]SlotGap, 0, len(t.gaps))
+	for _, gap := range t.gaps {
+		g := *gap
+		g.remaining = nil
+		gaps = append(gaps, g)
+	}
+	return SlotReport{
+		Router:      t.router,
+		Partition:   t.partition,
+		LastSlot:    t.lastSlot,
+		Duplicates:  t.duplicates,
+		Regressions: t.regressions,
+		OpenGaps:    openGaps,
+		MissingSlot: missing,
+		PendingGaps: len(t.pending),
+		Skipped:     t.skipped,
+		Gaps:        gaps,
+	}
+}
+
+// deleteMetrics 分区被回收时清理该分区的 gauge
+func (t *SlotTracker) deleteMetrics() {
+	partition := strconv.Itoa(int(t.partition))
+	slotLast.DeleteLabelValues(t.router, partition)
+	slotGapsOpen.DeleteLabelValues(t.router, partition)
+	slotMissing.DeleteLabelValues(t.router, partition)
+}
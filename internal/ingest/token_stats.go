@@ -0,0 +1,335 @@
+package ingest
+
+import (
+	"context"
+	"database/sql"
+	"dex-ingest-sol/internal/ingest/handler"
+	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/logger"
+	"dex-ingest-sol/internal/pkg/utils"
+	"sync"
+	"time"
+)
+
+// tokenStatsWindows 滚动窗口长度（秒）：5m / 1h / 6h / 24h
+var tokenStatsWindows = []int32{300, 3600, 21600, 86400}
+
+const (
+	tokenStatsBucketSec       = handler.TokenStatsBucketSec // 窗口统计的时间粒度
+	tokenStatsMaxWindow       = 86400                       // 最大窗口，超出的分钟汇总和交易地址会被清理
+	defaultTokenStatsInterval = 15 * time.Second            // 默认写入间隔
+	tokenStatsFinalTimeout    = 10 * time.Second            // 关闭时最后一次写入的超时时间
+)
+
+// tokenActivity 一个 token 最近 24h 的分钟汇总（最近一次从库中加载或重算的结果）
+type tokenActivity struct {
+	minutes map[int32]*model.TokenMinute // 分钟开始时间 → 汇总
+	dirty   bool                         // 上次写入后是否有分钟汇总被重算
+}
+
+// TokenStatsTracker 维护每个 token 的滚动窗口交易统计，定时写入 token_stats 表。
+//
+// 数据以 (token, 分钟) 为粒度持久化在 token_stats_minute 表中：写入事件的 flush 内由 Recompute 按 chain_event 重算
+// 涉及的分钟（幂等，重复投递和回放不会重复计数），交易地址的最近交易时间按较大值合并到 token_trader 表。
+// 写入 token_stats 时从这两张表读取窗口内的数据，因此重启不会丢失窗口，多个实例分别消费部分分区时结果也完整
+// （需在所有消费 event topic 的实例上开启）。内存中只保留分钟汇总的副本，用于判断哪些 token 需要重新写入。
+// 窗口的“当前时间”取已重算的最大分钟，消费延迟时统计不会被提前清空。
+type TokenStatsTracker struct {
+	db       *sql.DB
+	interval time.Duration
+
+	mu          sync.Mutex
+	tokens      map[string]*tokenActivity // key 为未编码的 token 地址
+	now         int32                     // 已重算的最大分钟
+	persistedAt int32                     // 上一次写入时的 now
+	since       int32                     // 库中最早的分钟汇总
+	loaded      bool                      // 是否已从库中加载最近的分钟汇总
+
+	stopCh   chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewTokenStatsTracker(db *sql.DB, interval time.Duration) *TokenStatsTracker {
+	if interval <= 0 {
+		interval = defaultTokenStatsInterval
+	}
+	return &TokenStatsTracker{
+		db:       db,
+		interval: interval,
+		tokens:   make(map[string]*tokenActivity),
+		stopCh:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Recompute 按已写入的 chain_event 重算 (token, 分钟) 汇总并标记对应 token 待写入，需在事件写入（或回滚删除）之后调用。
+// 已移出最大窗口的分钟不再重算。
+func (t *TokenStatsTracker) Recompute(ctx context.Context, keys []handler.TokenMinuteKey) error {
+	t.mu.Lock()
+	now := t.now
+	t.mu.Unlock()
+
+	pending := keys[:0:0]
+	for _, k := range keys {
+		if inWindow(k.MinuteTime, now, tokenStatsMaxWindow) {
+			pending = append(pending, k)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	minutes, err := handler.RecomputeTokenMinutes(ctx, t.db, pending)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for k, m := range minutes {
+		act := t.activity(k.Token)
+		if m == nil {
+			delete(act.minutes, k.MinuteTime)
+		} else {
+			act.minutes[k.MinuteTime] = m
+			t.now = max(t.now, k.MinuteTime)
+			if t.since == 0 || k.MinuteTime < t.since {
+				t.since = k.MinuteTime
+			}
+		}
+		act.dirty = true
+	}
+	return nil
+}
+
+func (t *TokenStatsTracker) activity(token string) *tokenActivity {
+	act, ok := t.tokens[token]
+	if !ok {
+		act = &tokenActivity{minutes: make(map[int32]*model.TokenMinute)}
+		t.tokens[token] = act
+	}
+	return act
+}
+
+// load 启动后加载最近 24h 的分钟汇总并全部标记待写入，使没有新交易的 token 也能随窗口滑动更新
+func (t *TokenStatsTracker) load(ctx context.Context) error {
+	after := int32(time.Now().Unix()) - tokenStatsMaxWindow - tokenStatsBucketSec
+	minutes, err := handler.LoadRecentTokenMinutes(ctx, t.db, after)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, m := range minutes {
+		act := t.activity(m.Token)
+		if _, ok := act.minutes[m.MinuteTime]; !ok {
+			act.minutes[m.MinuteTime] = m
+		}
+		act.dirty = true
+		t.now = max(t.now, m.MinuteTime)
+		if t.since == 0 || m.MinuteTime < t.since {
+			t.since = m.MinuteTime
+		}
+	}
+	t.loaded = true
+	logger.Infof("token_stats loaded %d minute buckets of %d tokens", len(minutes), len(t.tokens))
+	return nil
+}
+
+// inWindow 分钟桶与 [now-window, now] 有重叠即计入窗口
+func inWindow(minute, now, window int32) bool {
+	return minute+tokenStatsBucketSec > now-window
+}
+
+// crossed 是否有分钟汇总在 (prev, now] 期间移出了某个窗口，此时即使没有新交易也需要重新写入
+func (a *tokenActivity) crossed(prev, now int32) bool {
+	for minute := range a.minutes {
+		for _, w := range tokenStatsWindows {
+			if inWindow(minute, prev, w) && !inWindow(minute, now, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// prune 清理移出最大窗口的分钟汇总，返回是否有清理
+func (a *tokenActivity) prune(now int32) bool {
+	pruned := false
+	for minute := range a.minutes {
+		if !inWindow(minute, now, tokenStatsMaxWindow) {
+			delete(a.minutes, minute)
+			pruned = true
+		}
+	}
+	return pruned
+}
+
+// snapshot 计算各窗口的统计，traderTimes 为各交易地址最近一次交易的区块时间
+func (a *tokenActivity) snapshot(token string, traderTimes []int32, now, since, updateAt int32) []*model.TokenStats {
+	result := make([]*model.TokenStats, 0, len(tokenStatsWindows))
+	for _, w := range tokenStatsWindows {
+		s := &model.TokenStats{Token: utils.EncodeTokenAddress(token), WindowSec: w, TrackedSince: since, UpdateAt: updateAt}
+		var openEventID, closeEventID int64
+		for minute, m := range a.minutes {
+			if !inWindow(minute, now, w) {
+				continue
+			}
+			s.VolumeUsd += m.VolumeUsd
+			s.BuyCount += m.BuyCount
+			s.SellCount += m.SellCount
+			if m.OpenPrice > 0 && (openEventID == 0 || m.OpenEventID < openEventID) {
+				openEventID, s.PriceOpen = m.OpenEventID, m.OpenPrice
+			}
+			if m.ClosePrice > 0 && m.CloseEventID > closeEventID {
+				closeEventID, s.PriceClose = m.CloseEventID, m.ClosePrice
+			}
+		}
+		s.TradeCount = s.BuyCount + s.SellCount
+		for _, last := range traderTimes {
+			if inWindow(last-last%tokenStatsBucketSec, now, w) {
+				s.UniqueTraders++
+			}
+		}
+		if s.PriceOpen > 0 {
+			s.PriceChange = (s.PriceClose - s.PriceOpen) / s.PriceOpen
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+// Start 启动定时写入
+func (t *TokenStatsTracker) Start() {
+	go t.run()
+}
+
+// Stop 停止定时写入并做最后一次写入，需在 worker 全部退出后调用
+func (t *TokenStatsTracker) Stop() {
+	t.stopOnce.Do(func() {
+		close(t.stopCh)
+		<-t.done
+	})
+}
+
+func (t *TokenStatsTracker) run() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stopCh:
+			ctx, cancel := context.WithTimeout(context.Background(), tokenStatsFinalTimeout)
+			t.persist(ctx)
+			cancel()
+			return
+		case <-ticker.C:
+			t.persist(context.Background())
+		}
+	}
+}
+
+// persist 写入有分钟汇总被重算或窗口发生滑动的 token。窗口数据从库中读取（包含其它实例重算的分钟），
+// 写入失败时重新标记为 dirty，下一轮重试
+func (t *TokenStatsTracker) persist(ctx context.Context) {
+	if !t.loaded {
+		if err := t.load(ctx); err != nil {
+			logger.Errorf("load token_stats_minute failed, retry next round: %v", err)
+			return
+		}
+	}
+
+	t.mu.Lock()
+	if t.now == 0 {
+		t.mu.Unlock()
+		return
+	}
+	now, prev, since := t.now, t.persistedAt, t.since
+
+	var tokens, expired []string
+	for token, act := range t.tokens {
+		crossed := act.crossed(prev, now)
+		if act.prune(now) {
+			expired = append(expired, token)
+		}
+		if !act.dirty && !crossed {
+			// 全部移出窗口且全零统计已在之前写入，不再跟踪
+			if len(act.minutes) == 0 {
+				delete(t.tokens, token)
+			}
+			continue
+		}
+		tokens = append(tokens, token)
+		act.dirty = false
+	}
+	t.persistedAt = now
+	t.mu.Unlock()
+
+	after := now - tokenStatsMaxWindow - tokenStatsBucketSec
+	if len(expired) > 0 {
+		// 过期数据只占用存储，清理失败不影响统计（读取时按时间过滤）
+		if err := handler.PruneTokenStats(ctx, t.db, expired, after); err != nil {
+			logger.Warnf("prune token stats for %d tokens failed: %v", len(expired), err)
+		}
+	}
+	if len(tokens) == 0 {
+		return
+	}
+
+	start := time.Now()
+	minutes, err := handler.LoadTokenMinutes(ctx, t.db, tokens, after)
+	if err != nil {
+		logger.Errorf("load token_stats_minute for %d tokens failed: %v", len(tokens), err)
+		t.markDirty(tokens)
+		return
+	}
+	traders, err := handler.LoadTokenTraderTimes(ctx, t.db, tokens, after)
+	if err != nil {
+		logger.Errorf("load token_trader for %d tokens failed: %v", len(tokens), err)
+		t.markDirty(tokens)
+		return
+	}
+
+	loaded := make(map[string]map[int32]*model.TokenMinute, len(tokens))
+	for _, m := range minutes {
+		if loaded[m.Token] == nil {
+			loaded[m.Token] = make(map[int32]*model.TokenMinute)
+		}
+		loaded[m.Token][m.MinuteTime] = m
+	}
+
+	updateAt := int32(time.Now().Unix())
+	stats := make([]*model.TokenStats, 0, len(tokens)*len(tokenStatsWindows))
+	t.mu.Lock()
+	for _, token := range tokens {
+		act := t.activity(token)
+		// 以库中数据为准，同时补上其它实例重算的分钟
+		act.minutes = loaded[token]
+		if act.minutes == nil {
+			act.minutes = make(map[int32]*model.TokenMinute)
+		}
+		stats = append(stats, act.snapshot(token, traders[token], now, since, updateAt)...)
+	}
+	t.mu.Unlock()
+
+	if err := handler.UpsertTokenStats(ctx, t.db, stats); err != nil {
+		logger.Errorf("upsert token_stats for %d tokens failed: %v", len(tokens), err)
+		t.markDirty(tokens)
+		return
+	}
+	logger.Debugf("token_stats updated for %d tokens in %s", len(tokens), time.Since(start))
+}
+
+func (t *TokenStatsTracker) markDirty(tokens []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, token := range tokens {
+		if act, ok := t.tokens[token]; ok {
+			act.dirty = true
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package ingest
+
+import (
+	"dex-ingest-sol/pb"
+	"errors"
+	"fmt"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"strconv"
+)
+
+// 未知 Events 版本的处理方式
+const (
+	UnknownVersionDeadLetter = "dead_letter" // 写入死信，升级后可重放（默认）
+	UnknownVersionSkip       = "skip"        // 记录日志和指标后跳过，offset 正常提交
+	UnknownVersionHalt       = "halt"        // 停止消费该分区且不提交 offset，升级后重启从该消息继续
+)
+
+// eventsVersionField 为 Events.version 的字段编号，各版本必须保持不变，用于在解码前识别版本
+const eventsVersionField protowire.Number = 1
+
+// eventsDecoder 将某一版本的原始消息解码为当前的 pb.Events 结构
+type eventsDecoder func(raw []byte) (*pb.Events, error)
+
+// eventsDecoders 按版本注册解码器，上游升级消息结构时在此新增版本并转换为当前结构
+var eventsDecoders = map[uint32]eventsDecoder{
+	0: decodeEventsV1, // 早期上游未填写 version，结构与 v1 相同
+	1: decodeEventsV1,
+}
+
+func decodeEventsV1(raw []byte) (*pb.Events, error) {
+	events := &pb.Events{}
+	if err := proto.Unmarshal(raw, events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// ValidateUnknownVersion 校验未知版本处理方式配置
+func ValidateUnknownVersion(action string) error {
+	switch action {
+	case "", UnknownVersionDeadLetter, UnknownVersionSkip, UnknownVersionHalt:
+		return nil
+	default:
+		return fmt.Errorf("invalid unknown_version: %s (must be dead_letter/skip/halt)", action)
+	}
+}
+
+// errUnknownVersion 表示消息版本没有对应的解码器
+type errUnknownVersion struct {
+	version uint32
+}
+
+func (e *errUnknownVersion) Error() string {
+	return fmt.Sprintf("unsupported events version %d", e.version)
+}
+
+func isUnknownVersion(err error) bool {
+	var uv *errUnknownVersion
+	return errors.As(err, &uv)
+}
+
+// decodeEvents 读取消息版本并分发给对应的解码器
+func decodeEvents(routerType RouterType, raw []byte) (*pb.Events, error) {
+	version, err := peekEventsVersion(raw)
+	if err != nil {
+		return nil, err
+	}
+	messagesByVersionTotal.WithLabelValues(routerType.String(), strconv.FormatUint(uint64(version), 10)).Inc()
+	return decodeEventsVersion(version, raw)
+}
+
+// decodeEventsVersion 按已读取的 version 选择解码器，不记录指标（定位 offset 等非消费路径也使用）
+func decodeEventsVersion(version uint32, raw []byte) (*pb.Events, error) {
+	decode, ok := eventsDecoders[version]
+	if !ok {
+		return nil, &errUnknownVersion{version: version}
+	}
+	return decode(raw)
+}
+
+// peekEventsVersion 只扫描顶层字段读取 version，不解码其余内容；未设置时为 0
+func peekEventsVersion(raw []byte) (uint32, error) {
+	var version uint32
+	for len(raw) > 0 {
+		num, typ, n := protowire.ConsumeTag(raw)
+		if n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+		raw = raw[n:]
+
+		if num == eventsVersionField && typ == protowire.VarintType {
+			v, m := protowire.ConsumeVarint(raw)
+			if m < 0 {
+				return 0, protowire.ParseError(m)
+			}
+			version = uint32(v) // 与 proto 语义一致，重复出现时以最后一个为准
+			raw = raw[m:]
+			continue
+		}
+
+		m := protowire.ConsumeFieldValue(num, typ, raw)
+		if m < 0 {
+			return 0, protowire.ParseError(m)
+		}
+		raw = raw[m:]
+	}
+	return version, nil
+}
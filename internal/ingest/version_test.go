@@ -0,0 +1,281 @@
+package ingest
+
+import (
+	"bytes"
+	"dex-ingest-sol/internal/pkg/utils"
+	"dex-ingest-sol/pb"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// go test ./internal/ingest -run TestEventsFixtures -update 重新生成 testdata 下的版本样例
+var updateFixtures = flag.Bool("update", false, "regenerate testdata/events_v*.bin")
+
+const (
+	fixtureSlot           = 312345678
+	fixtureUnknownVersion = 99
+)
+
+var fixtureBlockHash = bytes.Repeat([]byte{0xab}, 32)
+
+func fixturePath(version uint32) string {
+	return filepath.Join("testdata", fmt.Sprintf("events_v%d.bin", version))
+}
+
+// fixtureEvents 构造样例消息：一条余额变更，version 为 0 时不写该字段（早期上游格式）
+func fixtureEvents(version uint32) *pb.Events {
+	return &pb.Events{
+		Version:   version,
+		ChainId:   100000,
+		Slot:      fixtureSlot,
+		Source:    1,
+		BlockHash: fixtureBlockHash,
+		Events: []*pb.Event{{
+			Event: &pb.Event_Balance{Balance: &pb.BalanceUpdateEvent{
+				Type:        pb.EventType_BALANCE_UPDATE,
+				EventId:     fixtureSlot<<32 | 1<<16,
+				Slot:        fixtureSlot,
+				BlockTime:   1760000000,
+				Token:       bytes.Repeat([]byte{0x01}, 32),
+				Account:     bytes.Repeat([]byte{0x02}, 32),
+				Owner:       bytes.Repeat([]byte{0x03}, 32),
+				PreBalance:  100,
+				PostBalance: 250,
+				Decimals:    6,
+			}},
+		}},
+	}
+}
+
+func readFixture(t *testing.T, version uint32) []byte {
+	t.Helper()
+	raw, err := os.ReadFile(fixturePath(version))
+	if err != nil {
+		t.Fatalf("read fixture v%d: %v", version, err)
+	}
+	return raw
+}
+
+func fixtureMessage(raw []byte) *kafka.Message {
+	topic := "dex_indexer_sol_balance"
+	return &kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 0, Offset: 42},
+		Value:          raw,
+	}
+}
+
+func supportedVersions() []uint32 {
+	versions := make([]uint32, 0, len(eventsDecoders))
+	for v := range eventsDecoders {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions
+}
+
+func TestEventsFixtures(t *testing.T) {
+	if !*updateFixtures {
+		t.Skip("run with -update to regenerate fixtures")
+	}
+	if err := os.MkdirAll("testdata", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range append(supportedVersions(), fixtureUnknownVersion) {
+		raw, err := proto.MarshalOptions{Deterministic: true}.Marshal(fixtureEvents(v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fixturePath(v), raw, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// 每个注册的版本都必须有样例，新增解码器时需同时提交样例
+func TestEveryDecoderHasFixture(t *testing.T) {
+	for _, v := range supportedVersions() {
+		if _, err := os.Stat(fixturePath(v)); err != nil {
+			t.Errorf("decoder v%d has no fixture: %v", v, err)
+		}
+	}
+}
+
+func TestDecodeEventsSupportedVersions(t *testing.T) {
+	for _, v := range supportedVersions() {
+		t.Run(fmt.Sprintf("v%d", v), func(t *testing.T) {
+			raw := readFixture(t, v)
+
+			got, err := peekEventsVersion(raw)
+			if err != nil {
+				t.Fatalf("peek version: %v", err)
+			}
+			if got != v {
+				t.Fatalf("peek version = %d, want %d", got, v)
+			}
+
+			events, err := decodeEvents(RouterBalance, raw)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if !proto.Equal(events, fixtureEvents(v)) {
+				t.Fatalf("decoded events mismatch:\n got %v\nwant %v", events, fixtureEvents(v))
+			}
+		})
+	}
+}
+
+func TestBuildBlockBatchSupportedVersions(t *testing.T) {
+	for _, v := range supportedVersions() {
+		t.Run(fmt.Sprintf("v%d", v), func(t *testing.T) {
+			batch, err := buildBlockBatch(RouterBalance, 0, fixtureMessage(readFixture(t, v)), utils.NewBase58Cache(), nil, nil)
+			if err != nil {
+				t.Fatalf("build: %v", err)
+			}
+			if batch == nil {
+				t.Fatal("build returned nil batch")
+			}
+			if batch.Slot != fixtureSlot || !batch.IsGrpc {
+				t.Fatalf("slot = %d, grpc = %v, want %d, true", batch.Slot, batch.IsGrpc, fixtureSlot)
+			}
+			if len(batch.Balances) != 1 || batch.Balances[0].Balance != "250" {
+				t.Fatalf("balances = %+v, want one balance of 250", batch.Balances)
+			}
+		})
+	}
+}
+
+func TestDecodeEventsUnknownVersion(t *testing.T) {
+	raw := readFixture(t, fixtureUnknownVersion)
+
+	events, err := decodeEvents(RouterBalance, raw)
+	if events != nil {
+		t.Fatalf("unknown version decoded: %v", events)
+	}
+	if !isUnknownVersion(err) {
+		t.Fatalf("err = %v, want unknown version", err)
+	}
+	var uv *errUnknownVersion
+	if !errors.As(err, &uv) || uv.version != fixtureUnknownVersion {
+		t.Fatalf("err = %v, want version %d", err, fixtureUnknownVersion)
+	}
+
+	// buildBlockBatch 需保留未知版本错误，worker 据此按 unknown_version 配置处理
+	batch, err := buildBlockBatch(RouterBalance, 0, fixtureMessage(raw), utils.NewBase58Cache(), nil, nil)
+	if batch != nil {
+		t.Fatalf("unknown version built a batch: %+v", batch)
+	}
+	var be *buildError
+	if !errors.As(err, &be) || be.reason != buildErrVersion {
+		t.Fatalf("err = %v, want build error with reason %q", err, buildErrVersion)
+	}
+	if !isUnknownVersion(err) {
+		t.Fatalf("err = %v, want unknown version", err)
+	}
+}
+
+func TestPeekEventsVersion(t *testing.T) {
+	appendVersion := func(b []byte, v uint64) []byte {
+		b = protowire.AppendTag(b, eventsVersionField, protowire.VarintType)
+		return protowire.AppendVarint(b, v)
+	}
+
+	tests := []struct {
+		name    string
+		raw     []byte
+		want    uint32
+		wantErr bool
+	}{
+		{name: "empty", raw: nil, want: 0},
+		{name: "version only", raw: appendVersion(nil, 3), want: 3},
+		{name: "after other fields", raw: appendVersion(protowire.AppendBytes(protowire.AppendTag(nil, 6, protowire.BytesType), fixtureBlockHash), 2), want: 2},
+		{name: "repeated keeps last", raw: appendVersion(appendVersion(nil, 1), 7), want: 7},
+		{name: "truncated tag", raw: []byte{0x80}, wantErr: true},
+		{name: "truncated value", raw: protowire.AppendTag(nil, 6, protowire.BytesType), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := peekEventsVersion(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Fatalf("version = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeEventsMalformed(t *testing.T) {
+	_, err := decodeEvents(RouterBalance, []byte{0x80})
+	if err == nil {
+		t.Fatal("malformed message decoded")
+	}
+	if isUnknownVersion(err) {
+		t.Fatalf("malformed message reported as unknown version: %v", err)
+	}
+}
+
+func TestValidateUnknownVersion(t *testing.T) {
+	for _, action := range []string{"", UnknownVersionDeadLetter, UnknownVersionSkip, UnknownVersionHalt} {
+		if err := ValidateUnknownVersion(action); err != nil {
+			t.Errorf("ValidateUnknownVersion(%q) = %v", action, err)
+		}
+	}
+	if err := ValidateUnknownVersion("drop"); err == nil {
+		t.Error("ValidateUnknownVersion(\"drop\") accepted")
+	}
+}
+
+func TestOnUnknownVersion(t *testing.T) {
+	_, uvErr := decodeEvents(RouterBalance, readFixture(t, fixtureUnknownVersion))
+	msg := fixtureMessage(readFixture(t, fixtureUnknownVersion))
+
+	t.Run("skip", func(t *testing.T) {
+		w := &WorkerContext{RouterType: RouterBalance, State: &PartitionState{}, UnknownVersion: UnknownVersionSkip}
+		w.onUnknownVersion(msg, uvErr)
+		if w.State.IsHalted() {
+			t.Fatal("skip halted the partition")
+		}
+	})
+
+	t.Run("halt", func(t *testing.T) {
+		w := &WorkerContext{RouterType: RouterBalance, State: &PartitionState{}, UnknownVersion: UnknownVersionHalt}
+		w.onUnknownVersion(msg, uvErr)
+		if !w.State.IsHalted() {
+			t.Fatal("halt did not halt the partition")
+		}
+
+		// 停止后的消息不再进入队列，offset 停在未知版本消息之前
+		w.handleMessage(fixtureMessage(readFixture(t, 1)))
+		if len(w.BatchQueue) != 0 {
+			t.Fatalf("halted partition queued %d batches", len(w.BatchQueue))
+		}
+	})
+}
+
+// 按 slot 定位 offset 与消费使用相同的版本分发
+func TestDecodeSlotVersions(t *testing.T) {
+	for _, v := range supportedVersions() {
+		slot, err := decodeSlot(fixtureMessage(readFixture(t, v)))
+		if err != nil {
+			t.Fatalf("v%d: decode slot: %v", v, err)
+		}
+		if slot != fixtureSlot {
+			t.Fatalf("v%d: slot = %d, want %d", v, slot, fixtureSlot)
+		}
+	}
+
+	_, err := decodeSlot(fixtureMessage(readFixture(t, fixtureUnknownVersion)))
+	if !isUnknownVersion(err) {
+		t.Fatalf("err = %v, want unknown version", err)
+	}
+}
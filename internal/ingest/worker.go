@@ -2,58 +2,100 @@ package ingest
 
 import (
 	"context"
-	"database/sql"
 	"dex-ingest-sol/internal/config"
 	"dex-ingest-sol/internal/ingest/handler"
 	"dex-ingest-sol/internal/ingest/model"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/dlq"
 	"dex-ingest-sol/internal/pkg/logger"
 	"dex-ingest-sol/internal/pkg/utils"
 	"dex-ingest-sol/pb"
+	"errors"
+	"fmt"
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 	"github.com/hashicorp/golang-lru"
-	"github.com/redis/go-redis/v9"
-	"google.golang.org/protobuf/proto"
+	"github.com/mr-tron/base58"
 	"runtime/debug"
-	"sync"
+	"strconv"
 	"time"
 )
 
 // BLOCKBATCH_BUFFER 表示每个分区缓存的最大 batch 数
 const BLOCKBATCH_BUFFER = 10
 
+// flush 失败后的重试间隔上限
+const maxFlushRetryDelay = 30 * time.Second
+
+// 分区回收时最后一次 flush 的超时时间，避免阻塞 rebalance 过久
+const revokeFlushTimeout = 10 * time.Second
+
+// stopSignal 通知 worker 处理完缓冲数据后退出（分区回收或服务关闭）
+type stopSignal struct {
+	reason  string        // revoked / shutdown，用于日志
+	lost    bool          // 分配已丢失，offset 无法再提交
+	timeout time.Duration // 最后一次 flush 的超时时间
+}
+
+// drainResult 记录 worker 退出前最后一次 flush 的结果
+type drainResult struct {
+	flushed int // flush 并提交的 batch 数
+	dropped int // 未能写入而丢弃的 batch 数（offset 未提交，会被重新消费）
+}
+
 // BlockBatch 表示按 slot 聚合的一批数据，包含事件和对象
 type BlockBatch struct {
 	Slot      uint64
-	IsGrpc    bool                   // 是否为 gRPC 实时推送（false 表示补块）
-	Messages  []*kafka.Message       // Kafka 原始消息
-	Balances  []*model.Balance       // Balance
-	Events    []*model.ChainEvent    // 普通事件
-	Pools     []*model.Pool          // 新增池子
-	Transfers []*model.TransferEvent // Transfer事件
+	IsGrpc    bool                    // 是否为 gRPC 实时推送（false 表示补块）
+	Messages  []*kafka.Message        // Kafka 原始消息
+	Balances  []*model.Balance        // Balance
+	History   []*model.BalanceHistory // 余额变更历史
+	Events    []*model.ChainEvent     // 普通事件
+	Pools     []*model.Pool           // 新增池子
+	Tokens    []*model.Token          // 新增 Token / 补全 decimals
+	Migrates  []*model.Migration      // 迁移事件
+	Transfers []*model.TransferEvent  // Transfer事件
+	Quotes    []*model.QuotePrice     // 报价币 USD 价格
+	States    []*model.PoolState      // 池子最新储备量
+	Trades    []*model.WalletTrade    // 钱包买卖，用于计算持仓盈亏
+
+	BlockTime  int64         // 区块时间（Unix 秒），用于数据新鲜度指标
+	BlockHash  string        // 区块哈希（base58），用于分叉检测
+	Rollback   *SlotRollback // 分叉回滚任务：写入本 batch 前先删除旧区块写入的行
+	Superseded bool          // 已被同一 slot 的新区块取代，只提交 offset，不再写入数据
+
+	forkUnchecked bool // 内存窗口中没有该 slot 的记录，flush 前需查询 slot_block 检测分叉
+
 }
 
 // WorkerContext 表示每个分区独立处理上下文
 type WorkerContext struct {
 	RouterType  RouterType            // 消费者类型（事件 / 余额）
 	Partition   int32                 // 当前分区编号
-	DB          *sql.DB               // 数据库连接
-	Redis       *redis.Client         // Redis 客户端
+	Sink        Sink                  // 数据写入目标
 	MsgCh       <-chan *kafka.Message // Kafka 消息通道
+	StopCh      <-chan stopSignal     // 分区回收 / 服务关闭通知
 	Kafka       *kafka.Consumer       // Kafka 消费者（用于 commit）
+	DeadLetter  dlq.Writer            // 死信写入（可为 nil）
 	Base58Cache *lru.Cache            // base58 解码缓存
 	PoolCache   *handler.PoolCache    // LRU缓存，避免重复处理pool
+	TokenCache  *handler.TokenCache   // LRU缓存，避免重复处理token
 	BatchQueue  []*BlockBatch         // 当前缓存的 batch 队列
+	State       *PartitionState       // flush 健康状态（卡住时暂停消费）
+	Slots       *SlotTracker          // slot 缺口 / 乱序 / 重复检测
+	Forks       *ForkTracker          // slot → block_hash 记录，检测分叉
 
 	// 配置项
-	MaxBlockHold  int           // 达到 N 条 block 时强制 flush
-	MaxBatchFlush int           // 每次最多 flush M 条 batch
-	FlushInterval time.Duration // flush 超时时间
+	MaxBlockHold   int           // 达到 N 条 block 时强制 flush
+	MaxBatchFlush  int           // 每次最多 flush M 条 batch
+	FlushInterval  time.Duration // flush 超时时间
+	UnknownVersion string        // 未知 Events 版本的处理方式
 
 	// 内部状态
 	ctx           context.Context
 	flushCounter  int
 	lastFlushTime time.Time
 	lastSlot      uint64
+	nextRetryTime time.Time // flush 失败后下一次重试的时间
 }
 
 // StartWorker 启动分区 worker，处理指定类型的消息
@@ -61,48 +103,68 @@ func StartWorker(
 	ctx context.Context,
 	partition int32,
 	ch <-chan *kafka.Message,
-	db *sql.DB,
-	redis *redis.Client,
+	stop <-chan stopSignal,
+	sink Sink,
 	kafkaConsumer *kafka.Consumer,
+	deadLetter dlq.Writer,
+	state *PartitionState,
+	slots *SlotTracker,
+	slotBlocks SlotBlockLoader,
 	conf *config.WorkerConfig,
 	routerType RouterType,
-) {
+) drainResult {
 	w := &WorkerContext{
-		ctx:           ctx,
-		RouterType:    routerType,
-		Partition:     partition,
-		DB:            db,
-		Redis:         redis,
-		MsgCh:         ch,
-		Kafka:         kafkaConsumer,
-		Base58Cache:   utils.NewBase58Cache(),
-		BatchQueue:    make([]*BlockBatch, 0, BLOCKBATCH_BUFFER),
-		MaxBlockHold:  conf.MaxBlockHold,
-		MaxBatchFlush: conf.MaxBatchFlush,
-		FlushInterval: conf.FlushInterval,
-		lastFlushTime: time.Now(),
-		flushCounter:  0,
+		ctx:            db.WithRetryLabels(ctx, routerType.String(), partition),
+		RouterType:     routerType,
+		Partition:      partition,
+		Sink:           sink,
+		MsgCh:          ch,
+		StopCh:         stop,
+		Kafka:          kafkaConsumer,
+		DeadLetter:     deadLetter,
+		Base58Cache:    utils.NewBase58Cache(),
+		BatchQueue:     make([]*BlockBatch, 0, BLOCKBATCH_BUFFER),
+		State:          state,
+		Slots:          slots,
+		Forks:          NewForkTracker(slotBlocks),
+		MaxBlockHold:   conf.MaxBlockHold,
+		MaxBatchFlush:  conf.MaxBatchFlush,
+		FlushInterval:  conf.FlushInterval,
+		UnknownVersion: conf.UnknownVersion,
+		lastFlushTime:  time.Now(),
+		flushCounter:   0,
 	}
 	if routerType == RouterEvent {
 		w.PoolCache = handler.NewPoolCache()
+		w.TokenCache = handler.NewTokenCache()
 	}
-	w.Run()
+	return w.Run()
 }
 
-func (w *WorkerContext) Run() {
+// Run 处理消息直到收到退出通知，返回退出前最后一次 flush 的结果
+func (w *WorkerContext) Run() drainResult {
 	ticker := time.NewTicker(500 * time.Millisecond)
 	defer ticker.Stop()
 
 	for {
+		// flush 失败时暂停读取新消息（nil channel 永远不会就绪），只在 ticker 中重试
+		msgCh := w.MsgCh
+		if w.State.IsStuck() || w.State.IsHalted() {
+			msgCh = nil
+		}
+
 		select {
 		case <-w.ctx.Done():
-			logger.Infof("[partition=%d] worker exiting", w.Partition)
-			return
+			logger.Infof("[partition=%d] worker exiting, %d batches not flushed", w.Partition, len(w.BatchQueue))
+			return drainResult{dropped: len(w.BatchQueue)}
+
+		case sig := <-w.StopCh:
+			return w.onStop(sig)
 
-		case msg, ok := <-w.MsgCh:
+		case msg, ok := <-msgCh:
 			if !ok {
-				logger.Infof("[partition=%d] channel closed", w.Partition)
-				return
+				logger.Infof("[partition=%d] channel closed, %d batches not flushed", w.Partition, len(w.BatchQueue))
+				return drainResult{dropped: len(w.BatchQueue)}
 			}
 			w.handleMessage(msg)
 
@@ -118,6 +180,14 @@ func (w *WorkerContext) Run() {
 			}
 
 		case <-ticker.C:
+			// 卡住状态：到达重试时间后重新 flush 同一批数据
+			if w.State.IsStuck() {
+				if time.Now().After(w.nextRetryTime) {
+					w.flushIfNeeded()
+				}
+				continue
+			}
+
 			// 定时兜底 flush
 			if len(w.BatchQueue) > 0 && time.Since(w.lastFlushTime) > w.FlushInterval {
 				w.flushIfNeeded()
@@ -126,6 +196,49 @@ func (w *WorkerContext) Run() {
 	}
 }
 
+// onStop 分区被回收或服务关闭：处理已缓冲的消息并 flush + commit 后退出。
+// flush 失败、超时或分配已丢失时直接丢弃缓存，offset 未提交，重启后或由新的 owner 重新消费。
+func (w *WorkerContext) onStop(sig stopSignal) drainResult {
+	defer partitionStuck.DeleteLabelValues(w.RouterType.String(), strconv.Itoa(int(w.Partition)))
+	defer w.Slots.deleteMetrics()
+
+	if sig.lost || w.State.IsStuck() {
+		logger.Warnf("[partition=%d] worker %s (lost=%v, stuck=%v), dropping %d batches and %d buffered messages",
+			w.Partition, sig.reason, sig.lost, w.State.IsStuck(), len(w.BatchQueue), len(w.MsgCh))
+		return drainResult{dropped: len(w.BatchQueue)}
+	}
+
+	// 消息通道中已分发但未处理的消息也需要写入，否则 offset 会停在更早的位置
+	for len(w.MsgCh) > 0 {
+		w.handleMessage(<-w.MsgCh)
+	}
+	if len(w.BatchQueue) == 0 {
+		logger.Infof("[partition=%d] worker %s, nothing to flush", w.Partition, sig.reason)
+		return drainResult{}
+	}
+
+	ctx, cancel := context.WithTimeout(w.ctx, sig.timeout)
+	defer cancel()
+	w.ctx = ctx
+
+	var result drainResult
+	for len(w.BatchQueue) > 0 && !w.State.IsStuck() {
+		n := min(len(w.BatchQueue), w.MaxBatchFlush)
+		w.flushIfNeeded()
+		if !w.State.IsStuck() {
+			result.flushed += n
+		}
+	}
+	if w.State.IsStuck() {
+		result.dropped = len(w.BatchQueue)
+		logger.Warnf("[partition=%d] worker %s, final flush failed, %d batches flushed, %d dropped: %v",
+			w.Partition, sig.reason, result.flushed, result.dropped, w.State.Err())
+		return result
+	}
+	logger.Infof("[partition=%d] worker %s, %d buffered batches flushed and committed", w.Partition, sig.reason, result.flushed)
+	return result
+}
+
 func (w *WorkerContext) drainMessages(batchSize int) {
 	for i := 0; i < batchSize; i++ {
 		select {
@@ -138,10 +251,114 @@ func (w *WorkerContext) drainMessages(batchSize int) {
 }
 
 func (w *WorkerContext) handleMessage(msg *kafka.Message) {
-	batch := buildBlockBatch(w.RouterType, w.Partition, msg, w.Base58Cache, w.PoolCache)
+	// 已停止（未知版本或死信写入失败）：丢弃后续消息，offset 停在该消息之前
+	if w.State.IsHalted() {
+		return
+	}
+	messagesConsumedTotal.WithLabelValues(w.RouterType.String(), strconv.Itoa(int(w.Partition))).Inc()
+
+	batch, err := buildBlockBatch(w.RouterType, w.Partition, msg, w.Base58Cache, w.PoolCache, w.TokenCache)
+	if isUnknownVersion(err) {
+		w.onUnknownVersion(msg, err)
+		return
+	}
+	if err != nil {
+		w.deadLetter(msg, err)
+		return
+	}
 	if batch != nil {
+		w.Slots.Observe(batch.Slot, batch.IsGrpc)
+		if rollback := w.Forks.Observe(batch); rollback != nil {
+			w.onFork(batch, rollback)
+		}
 		w.BatchQueue = append(w.BatchQueue, batch)
+		batchQueueDepth.WithLabelValues(w.RouterType.String(), strconv.Itoa(int(w.Partition))).Set(float64(len(w.BatchQueue)))
+	}
+}
+
+// onUnknownVersion 按配置处理无法识别版本的消息
+func (w *WorkerContext) onUnknownVersion(msg *kafka.Message, err error) {
+	action := w.UnknownVersion
+	if action == "" {
+		action = UnknownVersionDeadLetter
+	}
+	unknownVersionTotal.WithLabelValues(w.RouterType.String(), action).Inc()
+
+	switch action {
+	case UnknownVersionSkip:
+		logger.Warnf("[partition=%d offset=%d] skip message: %v", w.Partition, msg.TopicPartition.Offset, err)
+	case UnknownVersionHalt:
+		w.State.markHalted(fmt.Errorf("offset %d: %w", msg.TopicPartition.Offset, err))
+		logger.Errorf("[partition=%d offset=%d] partition halted, upgrade and restart to continue: %v",
+			w.Partition, msg.TopicPartition.Offset, err)
+	default:
+		w.deadLetter(msg, err)
+	}
+}
+
+// onFork 同一 slot 以不同 block_hash 再次出现：旧区块若仍在队列中直接作废，
+// 同时在写入新区块前删除旧区块可能已写入的行（flush 失败时可能已部分写入）
+func (w *WorkerContext) onFork(batch *BlockBatch, rollback *SlotRollback) {
+	for _, queued := range w.BatchQueue {
+		if queued.Slot == rollback.Slot && queued.BlockHash == rollback.OldHash && !queued.Superseded {
+			queued.supersede()
+		}
+	}
+	batch.Rollback = rollback
+
+	slotRollbacksTotal.WithLabelValues(w.RouterType.String(), strconv.Itoa(int(w.Partition))).Inc()
+	logger.Warnf("[partition=%d] slot %d block hash changed %s → %s, rolling back %d events, %d transfers, %d migrations, %d pool states, %d balances",
+		w.Partition, rollback.Slot, rollback.OldHash, rollback.NewHash,
+		len(rollback.Events), len(rollback.Transfers), len(rollback.Migrates), len(rollback.Pools), len(rollback.Accounts))
+}
+
+// supersede 清空被取代区块的数据，保留 Messages 以便正常提交 offset。
+// Pool / Token 为创建类信息，与区块无关，保留写入。
+func (b *BlockBatch) supersede() {
+	b.Superseded = true
+	b.Events = nil
+	b.Migrates = nil
+	b.Transfers = nil
+	b.Quotes = nil
+	b.States = nil
+	b.Trades = nil
+	b.Balances = nil
+	b.History = nil
+}
+
+// deadLetter 将无法解析的消息写入死信，写入成功后该 offset 会随下一批正常提交。
+// 未启用死信或写入未成功（退出时重试被取消）时停止消费该分区，offset 停在该消息之前，重启后重新处理
+func (w *WorkerContext) deadLetter(msg *kafka.Message, err error) {
+	entry := &dlq.Entry{
+		Router:    w.RouterType.String(),
+		Topic:     *msg.TopicPartition.Topic,
+		Partition: msg.TopicPartition.Partition,
+		Offset:    int64(msg.TopicPartition.Offset),
+		Reason:    buildErrorReason(err),
+		Error:     err.Error(),
+		Time:      time.Now(),
+		Value:     msg.Value,
+	}
+	dlq.Observe(entry)
+
+	if w.DeadLetter == nil {
+		w.State.markHalted(fmt.Errorf("offset %d: dead letter disabled: %w", entry.Offset, err))
+		logger.Errorf("[partition=%d offset=%d] dead letter disabled, partition halted to keep the message: %v",
+			w.Partition, entry.Offset, err)
+		return
+	}
+
+	// 死信写入失败时持续重试（阻塞当前分区），避免消息丢失
+	writeErr := db.RetryWithBackoff(w.ctx, func() error {
+		return w.DeadLetter.Write(entry)
+	})
+	if writeErr != nil {
+		w.State.markHalted(fmt.Errorf("offset %d: write dead letter failed: %w", entry.Offset, writeErr))
+		logger.Errorf("[partition=%d offset=%d] write dead letter failed, partition halted: %v", w.Partition, entry.Offset, writeErr)
+		return
 	}
+	logger.Warnf("[partition=%d offset=%d] message sent to dead letter, reason=%s",
+		w.Partition, entry.Offset, entry.Reason)
 }
 
 func (w *WorkerContext) flushIfNeeded() {
@@ -152,8 +369,16 @@ func (w *WorkerContext) flushIfNeeded() {
 	flushCount := min(len(w.BatchQueue), w.MaxBatchFlush)
 	toFlush := w.BatchQueue[:flushCount]
 
-	// 实际批处理逻辑
-	maxSlot := w.flushBatches(toFlush)
+	// 实际批处理逻辑：任何一个 sink 失败都保留这批数据、不提交 offset，等待重试
+	start := time.Now()
+	maxSlot, err := w.flushBatches(toFlush)
+	if err != nil {
+		w.onFlushFailed(toFlush, err)
+		return
+	}
+	w.onFlushSucceeded()
+	w.commitLastMessage(toFlush)
+	w.observeFlush(toFlush, maxSlot, time.Since(start))
 
 	// 更新状态
 	w.flushCounter++
@@ -171,6 +396,7 @@ func (w *WorkerContext) flushIfNeeded() {
 	} else {
 		w.BatchQueue = w.BatchQueue[flushCount:]
 	}
+	batchQueueDepth.WithLabelValues(w.RouterType.String(), strconv.Itoa(int(w.Partition))).Set(float64(len(w.BatchQueue)))
 
 	// 打印 flush 范围日志（可观察 slot 分布）
 	if flushCount > 0 {
@@ -179,12 +405,13 @@ func (w *WorkerContext) flushIfNeeded() {
 
 		// 统计事件数量（事件 Router 才有意义）
 		if w.RouterType == RouterEvent {
-			eventCount := 0
+			eventCount, tokenCount := 0, 0
 			for _, b := range toFlush {
 				eventCount += len(b.Events)
+				tokenCount += len(b.Tokens)
 			}
-			logger.Infof("[partition=%d] flushing %d batches, %d events (slots %d → %d)",
-				w.Partition, flushCount, eventCount, startSlot, endSlot)
+			logger.Infof("[partition=%d] flushing %d batches, %d events, %d tokens (slots %d → %d)",
+				w.Partition, flushCount, eventCount, tokenCount, startSlot, endSlot)
 		} else {
 			logger.Infof("[partition=%d] flushing %d batches, (slots %d → %d)",
 				w.Partition, flushCount, startSlot, endSlot)
@@ -198,7 +425,8 @@ func buildBlockBatch(
 	msg *kafka.Message,
 	base58Cache *lru.Cache,
 	poolCache *handler.PoolCache,
-) *BlockBatch {
+	tokenCache *handler.TokenCache,
+) (batch *BlockBatch, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			logger.Errorf(
@@ -210,24 +438,32 @@ func buildBlockBatch(
 				r,
 				debug.Stack(),
 			)
+			batch = nil
+			err = &buildError{reason: buildErrPanic, err: fmt.Errorf("%v", r)}
 		}
 	}()
 
-	events := &pb.Events{}
-	err := proto.Unmarshal(msg.Value, events)
+	events, err := decodeEvents(routerType, msg.Value)
+	if isUnknownVersion(err) {
+		return nil, &buildError{reason: buildErrVersion, err: err}
+	}
 	if err != nil {
 		logger.Errorf("[router=%v partition=%d offset=%v topic=%s] failed to unmarshal kafka message: %v",
 			routerType, partition, msg.TopicPartition.Offset, *msg.TopicPartition.Topic, err)
-		return nil
+		return nil, &buildError{reason: buildErrDecode, err: err}
 	}
 	if len(events.Events) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	batch := &BlockBatch{
-		Slot:     events.Slot,
-		IsGrpc:   events.Source == 1,
-		Messages: []*kafka.Message{msg},
+	batch = &BlockBatch{
+		Slot:      events.Slot,
+		IsGrpc:    events.Source == 1,
+		Messages:  []*kafka.Message{msg},
+		BlockTime: blockTimeOf(events),
+	}
+	if len(events.BlockHash) > 0 {
+		batch.BlockHash = base58.Encode(events.BlockHash)
 	}
 
 	// 根据 RouterType 分发事件解析
@@ -235,168 +471,161 @@ func buildBlockBatch(
 	case RouterEvent:
 		batch.Events = handler.BuildChainEventModels(events, base58Cache)
 		batch.Pools = handler.BuildPoolModels(events, base58Cache, poolCache)
+		batch.Tokens = handler.BuildTokenModels(events, base58Cache, tokenCache)
+		batch.Migrates = handler.BuildMigrationModels(events, base58Cache)
 		batch.Transfers = handler.BuildTransferEventModels(events, base58Cache)
+		batch.Quotes = handler.BuildQuotePriceModels(events, base58Cache, batch.BlockTime)
+		batch.States = handler.BuildPoolStateModels(events, base58Cache)
+		batch.Trades = handler.BuildWalletTrades(events, base58Cache)
 	case RouterBalance:
 		batch.Balances = handler.BuildBalanceModels(events, base58Cache)
+		batch.History = handler.BuildBalanceHistoryModels(events, base58Cache)
 	}
 
-	return batch
+	return batch, nil
 }
 
-func (w *WorkerContext) flushBatches(batches []*BlockBatch) uint64 {
-	switch w.RouterType {
-	case RouterEvent:
-		w.flushEventBatches(batches)
-	case RouterBalance:
-		w.flushBalanceBatches(batches)
-	default:
-		logger.Errorf("[partition=%d] unknown RouterType: %v", w.Partition, w.RouterType)
-	}
-
-	var maxSlot uint64
-	for _, b := range batches {
-		if b.Slot > maxSlot {
-			maxSlot = b.Slot
+// blockTimeOf 返回消息中第一个带区块时间的事件的 block_time（各事件类型均携带该字段）
+func blockTimeOf(events *pb.Events) int64 {
+	for _, e := range events.Events {
+		var ev interface{ GetBlockTime() int64 }
+		switch v := e.GetEvent().(type) {
+		case *pb.Event_Trade:
+			ev = v.Trade
+		case *pb.Event_Transfer:
+			ev = v.Transfer
+		case *pb.Event_Liquidity:
+			ev = v.Liquidity
+		case *pb.Event_Mint:
+			ev = v.Mint
+		case *pb.Event_Burn:
+			ev = v.Burn
+		case *pb.Event_Balance:
+			ev = v.Balance
+		case *pb.Event_Migrate:
+			ev = v.Migrate
+		case *pb.Event_Token:
+			ev = v.Token
+		}
+		if ev != nil {
+			if t := ev.GetBlockTime(); t > 0 {
+				return t
+			}
 		}
 	}
-	return maxSlot
+	return 0
 }
 
-func (w *WorkerContext) flushEventBatches(batches []*BlockBatch) {
-	var eventCount, poolCount, transferCount int
-
-	// 第一次遍历：统计容量
-	for _, b := range batches {
-		eventCount += len(b.Events)
-		poolCount += len(b.Pools)
-		transferCount += len(b.Transfers)
-	}
+const (
+	buildErrDecode  = "decode"  // protobuf 反序列化失败
+	buildErrPanic   = "panic"   // 构建模型时 panic
+	buildErrVersion = "version" // Events.version 没有对应的解码器
+)
 
-	// 分配内存
-	chainEvents := make([]*model.ChainEvent, 0, eventCount)
-	pools := make([]*model.Pool, 0, poolCount)
-	transferEvents := make([]*model.TransferEvent, 0, transferCount)
+// buildError 表示消息构建失败，reason 用于死信分类
+type buildError struct {
+	reason string
+	err    error
+}
 
-	// 第二次遍历：聚合数据
-	for _, b := range batches {
-		chainEvents = append(chainEvents, b.Events...)
-		pools = append(pools, b.Pools...)
-		transferEvents = append(transferEvents, b.Transfers...)
-	}
+func (e *buildError) Error() string {
+	return e.reason + ": " + e.err.Error()
+}
 
-	var wg sync.WaitGroup
+func (e *buildError) Unwrap() error {
+	return e.err
+}
 
-	// 写入 ChainEvent
-	if len(chainEvents) > 0 {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			start := time.Now()
-			if err := handler.InsertChainEvents(w.ctx, w.DB, chainEvents); err != nil {
-				logger.Errorf("[partition=%d] insertChainEvents error: %v", w.Partition, err)
-			}
-			logger.Infof("[partition=%d] insertChainEvents done in %s", w.Partition, time.Since(start))
-		}()
-
-		// Redis 同步缓存（如启用）
-		if w.Redis != nil {
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				if err := handler.SyncPoolCache(w.ctx, w.Redis, chainEvents); err != nil {
-					logger.Errorf("[partition=%d] sync pool cache error: %v", w.Partition, err)
-				}
-			}()
-		}
+func buildErrorReason(err error) string {
+	var be *buildError
+	if errors.As(err, &be) {
+		return be.reason
 	}
+	return "unknown"
+}
 
-	// 写入 Pool 数据
-	if len(pools) > 0 {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			start := time.Now()
-			if err := handler.InsertPools(w.ctx, w.DB, pools); err != nil {
-				logger.Errorf("[partition=%d] insertPools error: %v", w.Partition, err)
-			}
-			logger.Infof("[partition=%d] insertPools done in %s", w.Partition, time.Since(start))
-		}()
-	}
-
-	// 写入 TransferEvent
-	if len(transferEvents) > 0 {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			start := time.Now()
-			if err := handler.InsertTransferEvents(w.ctx, w.DB, transferEvents); err != nil {
-				logger.Errorf("[partition=%d] insertTransferEvents error: %v", w.Partition, err)
-			}
-			logger.Infof("[partition=%d] insertTransferEvents done in %s", w.Partition, time.Since(start))
-		}()
-	}
+// onFlushFailed 记录失败状态，按指数退避安排下一次重试
+func (w *WorkerContext) onFlushFailed(batches []*BlockBatch, err error) {
+	failures := w.State.markFailed(err)
+	delay := min(time.Second<<min(failures-1, 5), maxFlushRetryDelay)
+	w.nextRetryTime = time.Now().Add(delay)
 
-	// 等待所有任务完成
-	wg.Wait()
+	partition := strconv.Itoa(int(w.Partition))
+	flushFailuresTotal.WithLabelValues(w.RouterType.String(), partition).Inc()
+	partitionStuck.WithLabelValues(w.RouterType.String(), partition).Set(1)
 
-	// 提交 Kafka offset
-	w.commitLastMessage(batches)
+	logger.Errorf("[partition=%d] flush %d batches (slots %d → %d) failed (attempt=%d), offsets not committed, retry in %s: %v",
+		w.Partition, len(batches), batches[0].Slot, batches[len(batches)-1].Slot, failures, delay, err)
 }
 
-func (w *WorkerContext) flushBalanceBatches(batches []*BlockBatch) {
-	var (
-		totalBalanceCount              int
-		realtimeCount, historicalCount int
-	)
-
-	for _, b := range batches {
-		n := len(b.Balances)
-		totalBalanceCount += n
-		if b.isRealtime(w.lastSlot) {
-			realtimeCount += n
-		} else {
-			historicalCount += n
-		}
-	}
-	if totalBalanceCount == 0 {
+func (w *WorkerContext) onFlushSucceeded() {
+	wasStuck, failures := w.State.markRecovered()
+	if !wasStuck {
 		return
 	}
+	partitionStuck.WithLabelValues(w.RouterType.String(), strconv.Itoa(int(w.Partition))).Set(0)
+	logger.Infof("[partition=%d] flush recovered after %d failed attempts", w.Partition, failures)
+}
 
-	realtimeBalances := make([]*model.Balance, 0, realtimeCount)
-	historicalBalances := make([]*model.Balance, 0, historicalCount)
+// observeFlush 记录成功 flush 的批次数、耗时以及已写入的 slot / 区块时间
+func (w *WorkerContext) observeFlush(batches []*BlockBatch, maxSlot uint64, elapsed time.Duration) {
+	router, partition := w.RouterType.String(), strconv.Itoa(int(w.Partition))
+	batchesFlushedTotal.WithLabelValues(router, partition).Add(float64(len(batches)))
+	flushDuration.WithLabelValues(router, partition).Observe(elapsed.Seconds())
 
+	if maxSlot > w.lastSlot {
+		flushedSlot.WithLabelValues(router, partition).Set(float64(maxSlot))
+	}
+	var blockTime int64
 	for _, b := range batches {
-		if b.isRealtime(w.lastSlot) {
-			realtimeBalances = append(realtimeBalances, b.Balances...)
-		} else {
-			historicalBalances = append(historicalBalances, b.Balances...)
-		}
+		blockTime = max(blockTime, b.BlockTime)
+	}
+	if blockTime > 0 {
+		flushedBlockTime.WithLabelValues(router, partition).Set(float64(blockTime))
 	}
+}
 
-	logger.Infof("[partition=%d] flushing %d balances (realtime: %d, historical: %d)",
-		w.Partition, totalBalanceCount, len(realtimeBalances), len(historicalBalances))
+func (w *WorkerContext) flushBatches(batches []*BlockBatch) (uint64, error) {
+	f := &Flush{
+		Router:    w.RouterType,
+		Partition: w.Partition,
+		LastSlot:  w.lastSlot,
+		Batches:   batches,
+	}
+	// 死信重放（Partition=-1）不记录 checkpoint，避免把线上进度回退到旧 offset
+	if msgs := batches[len(batches)-1].Messages; w.Partition >= 0 && len(msgs) > 0 {
+		lastMsg := msgs[len(msgs)-1]
+		f.Topic = *lastMsg.TopicPartition.Topic
+		f.Offset = int64(lastMsg.TopicPartition.Offset)
+	}
 
-	if len(realtimeBalances) > 0 {
-		start := time.Now()
-		if err := handler.InsertBalances(w.ctx, w.DB, realtimeBalances, true); err != nil {
-			logger.Errorf("[partition=%d] insertBalances (realtime) error: %v", w.Partition, err)
+	// 内存窗口之外的分叉（重启、rebalance 后）按持久化的 slot_block 检测，查询失败时本次 flush 失败并重试
+	if w.Forks != nil {
+		rollbacks, err := w.Forks.CheckPersisted(w.ctx, batches)
+		if err != nil {
+			return 0, fmt.Errorf("check persisted forks: %w", err)
+		}
+		for b, rollback := range rollbacks {
+			w.onFork(b, rollback)
 		}
-		logger.Infof("[partition=%d] insertBalances (realtime) done in %s", w.Partition, time.Since(start))
 	}
 
-	if len(historicalBalances) > 0 {
-		start := time.Now()
-		if err := handler.InsertBalances(w.ctx, w.DB, historicalBalances, false); err != nil {
-			logger.Errorf("[partition=%d] insertBalances (historical) error: %v", w.Partition, err)
-		}
-		logger.Infof("[partition=%d] insertBalances (historical) done in %s", w.Partition, time.Since(start))
+	err := w.Sink.Write(w.ctx, f)
+	if err != nil {
+		return 0, err
 	}
 
-	w.commitLastMessage(batches)
+	var maxSlot uint64
+	for _, b := range batches {
+		if b.Slot > maxSlot {
+			maxSlot = b.Slot
+		}
+	}
+	return maxSlot, nil
 }
 
 func (w *WorkerContext) commitLastMessage(batches []*BlockBatch) {
-	if len(batches) == 0 {
+	if len(batches) == 0 || w.Kafka == nil {
 		return
 	}
 	lastBatch := batches[len(batches)-1]
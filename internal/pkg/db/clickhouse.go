@@ -0,0 +1,87 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ClickHouseClient 通过 HTTP 接口（默认端口 8123）访问 ClickHouse。
+// 只提供执行语句、查询和 JSONEachRow 批量写入，满足分析类旁路写入的需要。
+type ClickHouseClient struct {
+	addr     string
+	database string
+	user     string
+	password string
+	http     *http.Client
+}
+
+func NewClickHouseClient(addr, database, user, password string, timeout time.Duration) *ClickHouseClient {
+	return &ClickHouseClient{
+		addr:     addr,
+		database: database,
+		user:     user,
+		password: password,
+		http:     &http.Client{Timeout: timeout},
+	}
+}
+
+// Exec 执行不返回结果的语句（DDL、DELETE 等）
+func (c *ClickHouseClient) Exec(ctx context.Context, query string) error {
+	_, err := c.do(ctx, query, nil)
+	return err
+}
+
+// Query 执行查询并返回原始响应体，格式由语句中的 FORMAT 子句决定
+func (c *ClickHouseClient) Query(ctx context.Context, query string) ([]byte, error) {
+	return c.do(ctx, query, nil)
+}
+
+// InsertJSONEachRow 将已编码的 JSON 行批量写入表中
+func (c *ClickHouseClient) InsertJSONEachRow(ctx context.Context, table string, rows [][]byte) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	body := bytes.Join(rows, []byte{'\n'})
+	_, err := c.do(ctx, "INSERT INTO "+table+" FORMAT JSONEachRow", body)
+	return err
+}
+
+func (c *ClickHouseClient) do(ctx context.Context, query string, body []byte) ([]byte, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	if c.database != "" {
+		params.Set("database", c.database)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.addr+"/?"+params.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if c.user != "" {
+		req.Header.Set("X-ClickHouse-User", c.user)
+		req.Header.Set("X-ClickHouse-Key", c.password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		if len(data) > 512 {
+			data = data[:512]
+		}
+		return nil, fmt.Errorf("clickhouse http %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+	return data, nil
+}
@@ -5,10 +5,33 @@ import (
 	"dex-ingest-sol/internal/pkg/logger"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
+var retriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "ingest_retries_total",
+	Help: "Number of retried attempts in RetryWithBackoff",
+}, []string{"router", "partition"})
+
+type retryLabelsKey struct{}
+
+// WithRetryLabels 在 ctx 中附加重试指标的 router / partition 标签，未附加时标签为空
+func WithRetryLabels(ctx context.Context, router string, partition int32) context.Context {
+	return context.WithValue(ctx, retryLabelsKey{}, [2]string{router, strconv.Itoa(int(partition))})
+}
+
+func retryLabels(ctx context.Context) []string {
+	if labels, ok := ctx.Value(retryLabelsKey{}).([2]string); ok {
+		return labels[:]
+	}
+	return []string{"", ""}
+}
+
 // RetryWithBackoff retries the given operation with exponential backoff.
 // Delay pattern: 100ms, 400ms, 1s, 2s, 5s, 10s x N
 func RetryWithBackoff(ctx context.Context, op func() error) error {
@@ -35,6 +58,7 @@ func RetryWithBackoff(ctx context.Context, op func() error) error {
 
 		// 计算下一次延迟
 		delay := getBackoffDelay(attempt)
+		retriesTotal.WithLabelValues(retryLabels(ctx)...).Inc()
 
 		// 日志放在此处，避免第一次 op() 之前就打印
 		logger.Warnf("retrying after error (attempt=%d, delay=%s): %v", attempt+1, delay, err)
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseDBType 解析存储方言配置，留空默认为 Lindorm
+func ParseDBType(s string) (DBType, error) {
+	switch DBType(strings.ToLower(s)) {
+	case "", Lindorm:
+		return Lindorm, nil
+	case PG, "postgres", "postgresql":
+		return PG, nil
+	default:
+		return "", fmt.Errorf("unsupported storage: %s (must be lindorm or pg)", s)
+	}
+}
+
+// UpsertClause 返回主键冲突时覆盖 columns 的子句。
+// Lindorm（MUTABLE_LATEST）的 INSERT 天然按主键覆盖，返回空串；PostgreSQL 使用 ON CONFLICT DO UPDATE。
+func UpsertClause(dialect DBType, conflict []string, columns []string) string {
+	if dialect != PG {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(" ON CONFLICT (")
+	b.WriteString(strings.Join(conflict, ","))
+	b.WriteString(") DO UPDATE SET ")
+	for i, col := range columns {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(col)
+		b.WriteString("=EXCLUDED.")
+		b.WriteString(col)
+	}
+	return b.String()
+}
+
+// IgnoreClause 返回主键冲突时保留已有数据的子句
+func IgnoreClause(dialect DBType) string {
+	if dialect == PG {
+		return " ON CONFLICT DO NOTHING"
+	}
+	return " ON DUPLICATE KEY IGNORE"
+}
@@ -0,0 +1,133 @@
+package dlq
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	SinkNone  = "none"  // 不启用死信，遇到无法处理的消息时停止消费该分区（需显式配置）
+	SinkKafka = "kafka" // 写入 Kafka 死信 topic（生产环境）
+	SinkFile  = "file"  // 写入本地滚动文件（未配置 type 时的默认值）
+
+	defaultFilePath = "./dlq/dead_letter.jsonl"
+)
+
+// DeadLetterConf 死信配置
+type DeadLetterConf struct {
+	Type  string        `yaml:"type"`  // 死信类型：kafka / file / none，留空默认 file
+	Kafka KafkaSinkConf `yaml:"kafka"` // Kafka 死信配置
+	File  FileSinkConf  `yaml:"file"`  // 本地文件死信配置
+}
+
+type KafkaSinkConf struct {
+	Brokers []string `yaml:"brokers"` // 死信 Kafka broker，留空则复用消费者的 brokers
+	Topic   string   `yaml:"topic"`   // 死信 topic
+}
+
+type FileSinkConf struct {
+	Path       string `yaml:"path"`        // 死信文件路径
+	MaxSizeMB  int    `yaml:"max_size_mb"` // 单个文件最大大小（MB）
+	MaxBackups int    `yaml:"max_backups"` // 最多保留的旧文件数
+	Compress   bool   `yaml:"compress"`    // 是否压缩旧文件
+}
+
+// Entry 表示一条死信：原始消息 + 来源位置 + 失败原因
+type Entry struct {
+	Router    string    `json:"router"`    // 消费者类型（event / balance）
+	Topic     string    `json:"topic"`     // 原始 topic
+	Partition int32     `json:"partition"` // 原始分区
+	Offset    int64     `json:"offset"`    // 原始 offset
+	Reason    string    `json:"reason"`    // 失败分类：decode / panic
+	Error     string    `json:"error"`     // 错误详情
+	Time      time.Time `json:"time"`      // 写入死信的时间
+	Value     []byte    `json:"value"`     // 原始消息字节（JSON 中为 base64）
+}
+
+// Writer 死信写入接口
+type Writer interface {
+	Write(e *Entry) error
+	Close()
+}
+
+// Reader 死信读取接口，读完返回 io.EOF
+type Reader interface {
+	Next() (*Entry, error)
+	Commit(e *Entry) error // 确认最近一次 Next 返回的条目已重放成功（文件模式为空操作）
+	Close()
+}
+
+var deadLetterTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "ingest_dead_letter_total",
+	Help: "Number of kafka messages routed to the dead letter sink",
+}, []string{"router", "reason"})
+
+// Observe 记录一条死信指标（无论死信是否启用都会计数）
+func Observe(e *Entry) {
+	deadLetterTotal.WithLabelValues(e.Router, e.Reason).Inc()
+}
+
+// resolve 补全默认值：未配置 type 时写入本地文件，避免无法解析的消息被直接丢弃
+func (conf *DeadLetterConf) resolve() {
+	if conf.Type == "" {
+		conf.Type = SinkFile
+	}
+	if conf.Type == SinkFile && conf.File.Path == "" {
+		conf.File.Path = defaultFilePath
+	}
+}
+
+// NewWriter 根据配置创建死信 Writer，显式配置 none 时返回 nil
+func NewWriter(conf *DeadLetterConf, defaultBrokers []string) (Writer, error) {
+	conf.resolve()
+	switch conf.Type {
+	case SinkNone:
+		return nil, nil
+	case SinkKafka:
+		brokers := conf.Kafka.Brokers
+		if len(brokers) == 0 {
+			brokers = defaultBrokers
+		}
+		w, err := NewKafkaWriter(brokers, conf.Kafka.Topic)
+		if err != nil {
+			return nil, err
+		}
+		return w, nil
+	case SinkFile:
+		w, err := NewFileWriter(&conf.File)
+		if err != nil {
+			return nil, err
+		}
+		return w, nil
+	default:
+		return nil, fmt.Errorf("invalid dead_letter.type: %s (must be 'kafka', 'file' or 'none')", conf.Type)
+	}
+}
+
+// NewReader 根据配置创建死信 Reader，用于重放
+func NewReader(conf *DeadLetterConf, defaultBrokers []string, groupID string) (Reader, error) {
+	conf.resolve()
+	switch conf.Type {
+	case SinkKafka:
+		brokers := conf.Kafka.Brokers
+		if len(brokers) == 0 {
+			brokers = defaultBrokers
+		}
+		r, err := NewKafkaReader(brokers, conf.Kafka.Topic, groupID)
+		if err != nil {
+			return nil, err
+		}
+		return r, nil
+	case SinkFile:
+		r, err := NewFileReader(conf.File.Path)
+		if err != nil {
+			return nil, err
+		}
+		return r, nil
+	default:
+		return nil, fmt.Errorf("dead_letter.type must be 'kafka' or 'file' for replay, got: %q", conf.Type)
+	}
+}
@@ -0,0 +1,97 @@
+package dlq
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// FileWriter 以 JSON Lines 格式写入本地滚动文件
+type FileWriter struct {
+	mu  sync.Mutex
+	out *lumberjack.Logger
+}
+
+func NewFileWriter(conf *FileSinkConf) (*FileWriter, error) {
+	if conf.Path == "" {
+		return nil, fmt.Errorf("dead_letter.file.path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(conf.Path), 0755); err != nil {
+		return nil, fmt.Errorf("create dead letter dir failed: %w", err)
+	}
+	return &FileWriter{
+		out: &lumberjack.Logger{
+			Filename:   conf.Path,
+			MaxSize:    max(conf.MaxSizeMB, 1),
+			MaxBackups: conf.MaxBackups,
+			Compress:   conf.Compress,
+		},
+	}, nil
+}
+
+func (w *FileWriter) Write(e *Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err = w.out.Write(line)
+	return err
+}
+
+func (w *FileWriter) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_ = w.out.Close()
+}
+
+// FileReader 逐行读取死信文件
+type FileReader struct {
+	f       *os.File
+	scanner *bufio.Scanner
+}
+
+func NewFileReader(path string) (*FileReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open dead letter file failed: %w", err)
+	}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 1<<20), 64<<20) // 单条 slot 消息可能很大
+	return &FileReader{f: f, scanner: scanner}, nil
+}
+
+func (r *FileReader) Next() (*Entry, error) {
+	for r.scanner.Scan() {
+		line := r.scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		e := &Entry{}
+		if err := json.Unmarshal(line, e); err != nil {
+			return nil, fmt.Errorf("parse dead letter line failed: %w", err)
+		}
+		return e, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+func (r *FileReader) Commit(*Entry) error {
+	return nil
+}
+
+func (r *FileReader) Close() {
+	_ = r.f.Close()
+}
@@ -0,0 +1,158 @@
+package dlq
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+const (
+	headerRouter    = "dlq-router"
+	headerTopic     = "dlq-topic"
+	headerPartition = "dlq-partition"
+	headerOffset    = "dlq-offset"
+	headerReason    = "dlq-reason"
+	headerError     = "dlq-error"
+	headerTime      = "dlq-time"
+
+	kafkaFlushTimeoutMs = 10000
+	kafkaReadTimeout    = 5 * time.Second // 超过该时间没有新消息即视为读完
+)
+
+// KafkaWriter 将死信写入 Kafka topic：value 为原始字节，来源信息放在 headers 中
+type KafkaWriter struct {
+	producer *kafka.Producer
+	topic    string
+}
+
+func NewKafkaWriter(brokers []string, topic string) (*KafkaWriter, error) {
+	if topic == "" {
+		return nil, fmt.Errorf("dead_letter.kafka.topic is required")
+	}
+	p, err := kafka.NewProducer(&kafka.ConfigMap{
+		"bootstrap.servers":  strings.Join(brokers, ","),
+		"acks":               "all",
+		"enable.idempotence": true,
+		"message.max.bytes":  64 << 20,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create dead letter producer failed: %w", err)
+	}
+	return &KafkaWriter{producer: p, topic: topic}, nil
+}
+
+// Write 同步写入一条死信，等待 broker 确认
+func (w *KafkaWriter) Write(e *Entry) error {
+	deliveryCh := make(chan kafka.Event, 1)
+	err := w.producer.Produce(&kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &w.topic, Partition: kafka.PartitionAny},
+		Key:            []byte(fmt.Sprintf("%s-%d-%d", e.Topic, e.Partition, e.Offset)),
+		Value:          e.Value,
+		Headers: []kafka.Header{
+			{Key: headerRouter, Value: []byte(e.Router)},
+			{Key: headerTopic, Value: []byte(e.Topic)},
+			{Key: headerPartition, Value: []byte(strconv.FormatInt(int64(e.Partition), 10))},
+			{Key: headerOffset, Value: []byte(strconv.FormatInt(e.Offset, 10))},
+			{Key: headerReason, Value: []byte(e.Reason)},
+			{Key: headerError, Value: []byte(e.Error)},
+			{Key: headerTime, Value: []byte(e.Time.Format(time.RFC3339Nano))},
+		},
+	}, deliveryCh)
+	if err != nil {
+		return err
+	}
+
+	ev := <-deliveryCh
+	if m, ok := ev.(*kafka.Message); ok && m.TopicPartition.Error != nil {
+		return m.TopicPartition.Error
+	}
+	return nil
+}
+
+func (w *KafkaWriter) Close() {
+	w.producer.Flush(kafkaFlushTimeoutMs)
+	w.producer.Close()
+}
+
+// KafkaReader 从死信 topic 读取，使用独立的消费者组记录重放进度。
+// 重放逐条顺序处理，只保留最近一次读取的消息，跳过或失败的条目不会残留。
+type KafkaReader struct {
+	consumer *kafka.Consumer
+	entry    *Entry
+	msg      *kafka.Message
+}
+
+func NewKafkaReader(brokers []string, topic, groupID string) (*KafkaReader, error) {
+	if topic == "" {
+		return nil, fmt.Errorf("dead_letter.kafka.topic is required")
+	}
+	c, err := kafka.NewConsumer(&kafka.ConfigMap{
+		"bootstrap.servers":  strings.Join(brokers, ","),
+		"group.id":           groupID,
+		"auto.offset.reset":  "earliest",
+		"enable.auto.commit": false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create dead letter consumer failed: %w", err)
+	}
+	if err = c.SubscribeTopics([]string{topic}, nil); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("subscribe dead letter topic failed: %w", err)
+	}
+	return &KafkaReader{consumer: c}, nil
+}
+
+func (r *KafkaReader) Next() (*Entry, error) {
+	r.entry, r.msg = nil, nil
+	msg, err := r.consumer.ReadMessage(kafkaReadTimeout)
+	if err != nil {
+		if kafkaErr, ok := err.(kafka.Error); ok && kafkaErr.Code() == kafka.ErrTimedOut {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+
+	e := &Entry{Value: msg.Value}
+	for _, h := range msg.Headers {
+		v := string(h.Value)
+		switch h.Key {
+		case headerRouter:
+			e.Router = v
+		case headerTopic:
+			e.Topic = v
+		case headerPartition:
+			p, _ := strconv.ParseInt(v, 10, 32)
+			e.Partition = int32(p)
+		case headerOffset:
+			e.Offset, _ = strconv.ParseInt(v, 10, 64)
+		case headerReason:
+			e.Reason = v
+		case headerError:
+			e.Error = v
+		case headerTime:
+			e.Time, _ = time.Parse(time.RFC3339Nano, v)
+		}
+	}
+	r.entry, r.msg = e, msg
+	return e, nil
+}
+
+// Commit 提交该条死信在死信 topic 上的 offset，下次重放不再重复读取。
+// 只能确认最近一次 Next 返回的条目，其它条目忽略。
+func (r *KafkaReader) Commit(e *Entry) error {
+	if e == nil || e != r.entry {
+		return nil
+	}
+	msg := r.msg
+	r.entry, r.msg = nil, nil
+	_, err := r.consumer.CommitMessage(msg)
+	return err
+}
+
+func (r *KafkaReader) Close() {
+	_ = r.consumer.Close()
+}
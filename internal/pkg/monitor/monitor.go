@@ -7,9 +7,51 @@ import (
 	"fmt"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"net/http"
+	"sort"
+	"sync"
 	"time"
 )
 
+var (
+	registryMu sync.RWMutex
+	checks     = make(map[string]func() error)
+	handlers   = make(map[string]http.Handler)
+)
+
+// RegisterHandler 在监控服务上挂载额外的 HTTP 接口（如诊断信息）
+func RegisterHandler(path string, h http.Handler) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	handlers[path] = h
+}
+
+// RegisterHealthCheck 注册健康检查项，任一检查返回 error 时 /healthz 与 readiness 返回 503
+func RegisterHealthCheck(name string, fn func() error) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	checks[name] = fn
+}
+
+// runHealthChecks 返回失败检查项的错误描述（按名称排序）
+func runHealthChecks() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var failed []string
+	for _, name := range names {
+		if err := checks[name](); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	return failed
+}
+
 type MonitorServer struct {
 	port   int
 	server *http.Server
@@ -51,8 +93,24 @@ func (s *ProfileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch r.URL.Path {
 	case "/healthz", "/health/readiness", "/health/liveness":
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
 
+		// liveness 只表示进程存活，不受下游故障影响，避免被反复重启
+		var failed []string
+		if r.URL.Path != "/health/liveness" {
+			failed = runHealthChecks()
+		}
+		if len(failed) > 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			resp := map[string]interface{}{
+				"status":    "DOWN",
+				"checkTime": formatLocalDateTime(),
+				"details":   failed,
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
 		resp := map[string]interface{}{
 			"status":    "UP",
 			"checkTime": formatLocalDateTime(),
@@ -60,6 +118,13 @@ func (s *ProfileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 		_ = json.NewEncoder(w).Encode(resp)
 	default:
+		registryMu.RLock()
+		h, ok := handlers[r.URL.Path]
+		registryMu.RUnlock()
+		if ok {
+			h.ServeHTTP(w, r)
+			return
+		}
 		http.DefaultServeMux.ServeHTTP(w, r)
 	}
 }
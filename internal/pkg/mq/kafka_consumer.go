@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
@@ -25,6 +26,8 @@ type KafkaConsumerConf struct {
 	ReconnectBackoffMs    int      `json:"reconnect_backoff_ms" yaml:"reconnect_backoff_ms"`         // 第一次重连延迟
 	ReconnectBackoffMaxMs int      `json:"reconnect_backoff_max_ms" yaml:"reconnect_backoff_max_ms"` // 最大重连间隔
 	RetryBackoffMs        int      `json:"retry_backoff_ms" yaml:"retry_backoff_ms"`                 // 拉取失败重试间隔
+
+	Start KafkaStartConf `json:"start" yaml:"start"` // 消费起始位置（事故恢复回放用）
 }
 
 type Handler func(msg *kafka.Message)
@@ -35,6 +38,13 @@ type KafkaConsumer struct {
 	Conf     *KafkaConsumerConf
 	Handler  Handler
 	Done     chan struct{}
+
+	// Rebalance 分区分配/回收回调（可选），在 ReadMessage 所在 goroutine 中同步执行。
+	// 回调中未调用 Assign/Unassign 时由客户端自动完成分配。
+	Rebalance kafka.RebalanceCb
+
+	stopOnce sync.Once
+	stopped  chan struct{} // 主循环退出后关闭（未启动时为 nil）
 }
 
 func buildClientID(service string) string {
@@ -79,14 +89,16 @@ func NewKafkaConsumer(conf *KafkaConsumerConf, handler Handler) (*KafkaConsumer,
 
 // Start 启动消费者主循环（不自动提交 offset）
 func (kc *KafkaConsumer) Start(ctx context.Context) error {
-	err := kc.Consumer.SubscribeTopics([]string{kc.Conf.Topic}, nil)
+	err := kc.Consumer.SubscribeTopics([]string{kc.Conf.Topic}, kc.Rebalance)
 	if err != nil {
 		logger.Errorf("kafka subscribe topic error: %v", err)
 		return err
 	}
 	logger.Infof("kafka consumer subscribed, topic=%s", kc.Conf.Topic)
 
+	kc.stopped = make(chan struct{})
 	go func() {
+		defer close(kc.stopped)
 		for {
 			select {
 			case <-kc.Done:
@@ -139,9 +151,22 @@ func (kc *KafkaConsumer) Commit(messages ...*kafka.Message) error {
 	return firstErr
 }
 
-// Close 优雅关闭消费者
+// StopFetching 停止拉取新消息，等待主循环退出（最多一个 read_timeout_ms），可重复调用
+func (kc *KafkaConsumer) StopFetching() {
+	kc.stopOnce.Do(func() {
+		close(kc.Done)
+	})
+	if kc.stopped != nil {
+		<-kc.stopped
+	}
+}
+
+// Close 优雅关闭消费者：先停止拉取，再离开消费者组
 func (kc *KafkaConsumer) Close() {
-	close(kc.Done)
-	kc.Consumer.Close()
+	kc.StopFetching()
+	if err := kc.Consumer.Close(); err != nil {
+		logger.Errorf("kafka consumer close error: %v", err)
+		return
+	}
 	logger.Infof("kafka consumer closed")
 }
@@ -0,0 +1,309 @@
+package mq
+
+import (
+	"dex-ingest-sol/internal/pkg/logger"
+	"fmt"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// 消费起始位置模式
+const (
+	StartFromCommitted  = "committed"  // 从消费者组已提交的 offset 继续（默认）
+	StartFromEarliest   = "earliest"   // 从每个分区最早的消息开始
+	StartFromTime       = "time"       // 从指定时间之后的第一条消息开始（offsets-for-times）
+	StartFromSlot       = "slot"       // 从 slot >= 目标 slot 的第一条消息开始（按分区二分查找）
+	StartFromCheckpoint = "checkpoint" // 从数据库 ingest_checkpoint 记录的位置继续（与已提交 offset 取较大者）
+)
+
+// 元数据查询、offset 查询的超时时间
+const offsetQueryTimeoutMs = 10000
+
+// KafkaStartConf 定义消费起始位置，用于事故恢复时回放数据
+type KafkaStartConf struct {
+	Mode string `json:"mode" yaml:"mode"` // committed / earliest / time / slot / checkpoint，留空等同 committed
+	Time string `json:"time" yaml:"time"` // mode=time 时的起始时间（RFC3339，如 "2025-06-01T08:00:00+08:00"）
+	Slot uint64 `json:"slot" yaml:"slot"` // mode=slot 时的目标 slot
+}
+
+// SlotDecoder 从消息中解析 slot，用于按 slot 定位 offset
+type SlotDecoder func(msg *kafka.Message) (uint64, error)
+
+// CheckpointLoader 读取 topic 各分区下一条待消费消息的 offset（来自存储中的 checkpoint）
+type CheckpointLoader func(topic string) (map[int32]int64, error)
+
+// StartMarker 记录回放类起始位置（earliest / time / slot）是否已经执行过，
+// 配置未改回 committed 时重启（如崩溃循环）不会反复回放
+type StartMarker interface {
+	Applied(topic, spec string) (bool, error)
+	MarkApplied(topic, spec string) error
+}
+
+// Spec 返回起始位置的唯一标识，相同标识的回放只执行一次
+func (c *KafkaStartConf) Spec(groupID string) string {
+	switch c.Mode {
+	case StartFromTime:
+		return fmt.Sprintf("%s|%s|%s", groupID, c.Mode, c.Time)
+	case StartFromSlot:
+		return fmt.Sprintf("%s|%s|%d", groupID, c.Mode, c.Slot)
+	default:
+		return groupID + "|" + c.Mode
+	}
+}
+
+// rewinds 是否为回放类模式（checkpoint 只会向前跳过已写入的消息，可重复执行）
+func (c *KafkaStartConf) rewinds() bool {
+	return c.Mode == StartFromEarliest || c.Mode == StartFromTime || c.Mode == StartFromSlot
+}
+
+// Validate 校验起始位置配置
+func (c *KafkaStartConf) Validate() error {
+	switch c.Mode {
+	case "", StartFromCommitted, StartFromEarliest, StartFromCheckpoint:
+		return nil
+	case StartFromTime:
+		if _, err := time.Parse(time.RFC3339, c.Time); err != nil {
+			return fmt.Errorf("invalid start time %q: %w", c.Time, err)
+		}
+		return nil
+	case StartFromSlot:
+		if c.Slot == 0 {
+			return fmt.Errorf("start slot is required when mode=%s", StartFromSlot)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid start mode: %s (must be committed/earliest/time/slot/checkpoint)", c.Mode)
+	}
+}
+
+// ResetOffsets 在订阅前按起始位置计算每个分区的 offset 并提交到消费者组，
+// 之后正常订阅即从新的位置开始消费，rebalance 后其它实例也会从该位置继续。
+// 回放类模式成功后通过 marker 记录，之后以相同配置启动时跳过重置。
+// 注意：消费者组内不能有其它活跃成员，否则 broker 会拒绝提交。
+func (kc *KafkaConsumer) ResetOffsets(start *KafkaStartConf, decodeSlot SlotDecoder, loadCheckpoint CheckpointLoader, marker StartMarker) error {
+	if start.Mode == "" || start.Mode == StartFromCommitted {
+		return nil
+	}
+	if err := start.Validate(); err != nil {
+		return err
+	}
+
+	spec := start.Spec(kc.Conf.GroupID)
+	if start.rewinds() && marker != nil {
+		applied, err := marker.Applied(kc.Conf.Topic, spec)
+		if err != nil {
+			return fmt.Errorf("load start marker failed: %w", err)
+		}
+		if applied {
+			logger.Warnf("kafka start offsets already reset once (%s), skipped; set start.mode back to committed, "+
+				"or change the start position to replay again", spec)
+			return nil
+		}
+	}
+
+	partitions, err := kc.topicPartitions()
+	if err != nil {
+		return err
+	}
+
+	var offsets []kafka.TopicPartition
+	switch start.Mode {
+	case StartFromEarliest:
+		offsets, err = kc.earliestOffsets(partitions)
+	case StartFromTime:
+		startTime, _ := time.Parse(time.RFC3339, start.Time)
+		offsets, err = kc.offsetsForTime(partitions, startTime)
+	case StartFromSlot:
+		offsets, err = kc.offsetsForSlot(partitions, start.Slot, decodeSlot)
+	case StartFromCheckpoint:
+		offsets, err = kc.offsetsForCheckpoint(partitions, loadCheckpoint)
+	}
+	if err != nil {
+		return err
+	}
+	if len(offsets) == 0 {
+		logger.Infof("kafka start offsets unchanged, mode=%s", start.Mode)
+		return nil
+	}
+
+	committed, err := kc.Consumer.CommitOffsets(offsets)
+	if err != nil {
+		return fmt.Errorf("commit start offsets failed (make sure no other consumer in group %s is running): %w",
+			kc.Conf.GroupID, err)
+	}
+	for _, tp := range committed {
+		if tp.Error != nil {
+			return fmt.Errorf("commit start offset failed, partition=%d: %w", tp.Partition, tp.Error)
+		}
+		logger.Infof("kafka start offset reset, topic=%s, partition=%d, offset=%v, mode=%s",
+			kc.Conf.Topic, tp.Partition, tp.Offset, start.Mode)
+	}
+	if start.rewinds() && marker != nil {
+		if err = marker.MarkApplied(kc.Conf.Topic, spec); err != nil {
+			return fmt.Errorf("save start marker failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// topicPartitions 查询 topic 的全部分区
+func (kc *KafkaConsumer) topicPartitions() ([]kafka.TopicPartition, error) {
+	topic := kc.Conf.Topic
+	md, err := kc.Consumer.GetMetadata(&topic, false, offsetQueryTimeoutMs)
+	if err != nil {
+		return nil, fmt.Errorf("get topic metadata failed: %w", err)
+	}
+	tm, ok := md.Topics[topic]
+	if !ok || len(tm.Partitions) == 0 {
+		return nil, fmt.Errorf("topic %s not found or has no partitions", topic)
+	}
+	partitions := make([]kafka.TopicPartition, 0, len(tm.Partitions))
+	for _, p := range tm.Partitions {
+		partitions = append(partitions, kafka.TopicPartition{Topic: &topic, Partition: p.ID})
+	}
+	return partitions, nil
+}
+
+func (kc *KafkaConsumer) earliestOffsets(partitions []kafka.TopicPartition) ([]kafka.TopicPartition, error) {
+	offsets := make([]kafka.TopicPartition, 0, len(partitions))
+	for _, tp := range partitions {
+		low, _, err := kc.Consumer.QueryWatermarkOffsets(*tp.Topic, tp.Partition, offsetQueryTimeoutMs)
+		if err != nil {
+			return nil, fmt.Errorf("query watermark failed, partition=%d: %w", tp.Partition, err)
+		}
+		tp.Offset = kafka.Offset(low)
+		offsets = append(offsets, tp)
+	}
+	return offsets, nil
+}
+
+func (kc *KafkaConsumer) offsetsForTime(partitions []kafka.TopicPartition, startTime time.Time) ([]kafka.TopicPartition, error) {
+	query := make([]kafka.TopicPartition, 0, len(partitions))
+	for _, tp := range partitions {
+		tp.Offset = kafka.Offset(startTime.UnixMilli())
+		query = append(query, tp)
+	}
+	offsets, err := kc.Consumer.OffsetsForTimes(query, offsetQueryTimeoutMs)
+	if err != nil {
+		return nil, fmt.Errorf("offsets for times failed: %w", err)
+	}
+
+	for i, tp := range offsets {
+		if tp.Error != nil {
+			return nil, fmt.Errorf("offsets for times failed, partition=%d: %w", tp.Partition, tp.Error)
+		}
+		// 该时间之后没有消息：从分区末尾开始
+		if tp.Offset < 0 {
+			_, high, err := kc.Consumer.QueryWatermarkOffsets(*tp.Topic, tp.Partition, offsetQueryTimeoutMs)
+			if err != nil {
+				return nil, fmt.Errorf("query watermark failed, partition=%d: %w", tp.Partition, err)
+			}
+			offsets[i].Offset = kafka.Offset(high)
+		}
+	}
+	return offsets, nil
+}
+
+// offsetsForSlot 在每个分区内二分查找第一条 slot >= target 的消息，
+// 前提是分区内消息按 slot 基本递增写入。
+func (kc *KafkaConsumer) offsetsForSlot(partitions []kafka.TopicPartition, target uint64, decodeSlot SlotDecoder) ([]kafka.TopicPartition, error) {
+	if decodeSlot == nil {
+		return nil, fmt.Errorf("slot decoder is required when mode=%s", StartFromSlot)
+	}
+	defer func() {
+		_ = kc.Consumer.Unassign()
+	}()
+
+	offsets := make([]kafka.TopicPartition, 0, len(partitions))
+	for _, tp := range partitions {
+		low, high, err := kc.Consumer.QueryWatermarkOffsets(*tp.Topic, tp.Partition, offsetQueryTimeoutMs)
+		if err != nil {
+			return nil, fmt.Errorf("query watermark failed, partition=%d: %w", tp.Partition, err)
+		}
+
+		// 在 [low, high) 中查找，找不到时 lo 停在 high（分区末尾）
+		lo, hi := low, high
+		for lo < hi {
+			mid := lo + (hi-lo)/2
+			msg, err := kc.readAt(tp, mid)
+			if err != nil {
+				return nil, err
+			}
+			slot, err := decodeSlot(msg)
+			if err != nil {
+				return nil, fmt.Errorf("decode slot failed, partition=%d, offset=%v: %w",
+					tp.Partition, msg.TopicPartition.Offset, err)
+			}
+			if slot >= target {
+				hi = mid
+			} else {
+				// 读到的消息 offset 可能大于 mid（日志压缩、事务标记）
+				lo = int64(msg.TopicPartition.Offset) + 1
+			}
+		}
+		logger.Infof("kafka slot search done, partition=%d, target_slot=%d, offset=%d (low=%d, high=%d)",
+			tp.Partition, target, lo, low, high)
+
+		tp.Offset = kafka.Offset(lo)
+		offsets = append(offsets, tp)
+	}
+	return offsets, nil
+}
+
+// offsetsForCheckpoint 使用存储中的 checkpoint 定位：checkpoint 领先于已提交 offset 时
+// （数据已写入但 offset 未提交就崩溃）跳过已写入的消息；没有 checkpoint 或落后的分区保持原位置。
+func (kc *KafkaConsumer) offsetsForCheckpoint(partitions []kafka.TopicPartition, loadCheckpoint CheckpointLoader) ([]kafka.TopicPartition, error) {
+	if loadCheckpoint == nil {
+		return nil, fmt.Errorf("checkpoint loader is required when mode=%s", StartFromCheckpoint)
+	}
+	checkpoints, err := loadCheckpoint(kc.Conf.Topic)
+	if err != nil {
+		return nil, fmt.Errorf("load checkpoints failed: %w", err)
+	}
+
+	committed, err := kc.Consumer.Committed(partitions, offsetQueryTimeoutMs)
+	if err != nil {
+		return nil, fmt.Errorf("query committed offsets failed: %w", err)
+	}
+
+	offsets := make([]kafka.TopicPartition, 0, len(committed))
+	for _, tp := range committed {
+		next, ok := checkpoints[tp.Partition]
+		if !ok {
+			continue
+		}
+		if tp.Offset >= 0 && int64(tp.Offset) >= next {
+			logger.Infof("kafka checkpoint behind committed offset, partition=%d, checkpoint=%d, committed=%v",
+				tp.Partition, next, tp.Offset)
+			continue
+		}
+		logger.Infof("kafka seek to checkpoint, partition=%d, checkpoint=%d, committed=%v", tp.Partition, next, tp.Offset)
+		tp.Offset = kafka.Offset(next)
+		offsets = append(offsets, tp)
+	}
+	return offsets, nil
+}
+
+// readAt 读取分区中 offset 处（或之后第一条）的消息
+func (kc *KafkaConsumer) readAt(tp kafka.TopicPartition, offset int64) (*kafka.Message, error) {
+	tp.Offset = kafka.Offset(offset)
+	if err := kc.Consumer.Assign([]kafka.TopicPartition{tp}); err != nil {
+		return nil, fmt.Errorf("assign partition %d failed: %w", tp.Partition, err)
+	}
+
+	deadline := time.Now().Add(offsetQueryTimeoutMs * time.Millisecond)
+	for time.Now().Before(deadline) {
+		msg, err := kc.Consumer.ReadMessage(time.Duration(kc.Conf.ReadTimeoutMs) * time.Millisecond)
+		if err != nil {
+			if kafkaErr, ok := err.(kafka.Error); ok && kafkaErr.Code() == kafka.ErrTimedOut {
+				continue
+			}
+			return nil, fmt.Errorf("read message failed, partition=%d, offset=%d: %w", tp.Partition, offset, err)
+		}
+		if msg.TopicPartition.Partition == tp.Partition && int64(msg.TopicPartition.Offset) >= offset {
+			return msg, nil
+		}
+	}
+	return nil, fmt.Errorf("read message timeout, partition=%d, offset=%d", tp.Partition, offset)
+}
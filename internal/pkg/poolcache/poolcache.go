@@ -0,0 +1,85 @@
+// Package poolcache 定义 Redis 热点池子缓存的 key 和数据格式，ingest 写入、query 读取共用。
+//
+//   - pool:{pool_address}:events  有序集合，保存池子最近 N 条连续事件。score 为 slot，
+//     member 为 8 字节大端 event_id + ChainEvent protobuf，同一 slot 内按 member 字典序即 event_id 排序。
+//     可能出现缺口时（写入失败、补块、回滚失败）ingest 删除整个 key；key 从建立起最多保留 ttl，不随写入续期
+//   - pool:{pool_address}:state   哈希，保存最近一笔交易的 price_usd / last_trade_time / last_event_id，
+//     query 读取池子信息时用于覆盖 pool_state 中较旧的成交价
+package poolcache
+
+import (
+	"dex-ingest-sol/pb"
+	"encoding/binary"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	FieldPriceUsd      = "price_usd"
+	FieldLastTradeTime = "last_trade_time"
+	FieldLastEventID   = "last_event_id"
+)
+
+// EventTypes 缓存的事件类型，与 QueryEventsByPool 的默认查询类型一致
+var EventTypes = []uint32{
+	uint32(pb.EventType_TRADE_BUY),
+	uint32(pb.EventType_TRADE_SELL),
+	uint32(pb.EventType_ADD_LIQUIDITY),
+	uint32(pb.EventType_REMOVE_LIQUIDITY),
+	uint32(pb.EventType_BURN),
+	uint32(pb.EventType_MIGRATE),
+}
+
+func EventsKey(pool string) string {
+	return "pool:" + pool + ":events"
+}
+
+func StateKey(pool string) string {
+	return "pool:" + pool + ":state"
+}
+
+// IsCachedType 事件类型是否写入缓存
+func IsCachedType(eventType uint32) bool {
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Score 返回事件在有序集合中的 score（slot，float64 可精确表示）
+func Score(eventID uint64) float64 {
+	return float64(eventID >> 32)
+}
+
+// EncodeEventID 编码为定长字符串，Lua 脚本中按字符串比较大小（event_id 超出 double 精度）
+func EncodeEventID(eventID uint64) string {
+	return fmt.Sprintf("%020d", eventID)
+}
+
+// EncodeEvent 编码有序集合的 member。相同事件必须编码出相同的 member，重试写入时才不会重复，
+// 因此不保存 create_at（写库时间每次不同）。
+func EncodeEvent(ev *pb.ChainEvent) (string, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(ev)
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, 8+len(data))
+	binary.BigEndian.PutUint64(buf, ev.EventId)
+	copy(buf[8:], data)
+	return string(buf), nil
+}
+
+// DecodeEvent 解码有序集合的 member
+func DecodeEvent(member string) (*pb.ChainEvent, error) {
+	if len(member) < 8 {
+		return nil, fmt.Errorf("invalid pool cache member (len=%d)", len(member))
+	}
+	ev := &pb.ChainEvent{}
+	if err := proto.Unmarshal([]byte(member[8:]), ev); err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
@@ -21,6 +21,13 @@ func EventIdHash(eventId uint64) int32 {
 	return int32((part1 << 24) | (part2 << 16) | (part3 << 8) | part4)
 }
 
+// MirrorEventIdHash 返回 event_id 的镜像哈希（按位取反，必为负数）。
+// EventIdHash 的结果恒为非负，镜像行与正常行的主键永不冲突，
+// 用于同一事件需要在 chain_event 中按另一个 pool_address 再落一行的场景（如 MIGRATE 的来源池）。
+func MirrorEventIdHash(eventId uint64) int32 {
+	return ^EventIdHash(eventId)
+}
+
 // TxHashToString 将 64 字节交易哈希转为 base58 字符串表示
 func TxHashToString(hash []byte) string {
 	if len(hash) != 64 {
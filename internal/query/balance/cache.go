@@ -9,7 +9,7 @@ import (
 const (
 	balancesByAccountsTTL = 5 * time.Second
 	balancesByOwnerTTL    = 10 * time.Second
-	holderCountTTL        = 30 * time.Second
+	holderCountTTL        = 5 * time.Second // token_holder_stats 点查
 	topHoldersByTokenTTL  = 60 * time.Second
 )
 
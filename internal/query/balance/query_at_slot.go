@@ -0,0 +1,137 @@
+package balance
+
+import (
+	"context"
+	"dex-ingest-sol/internal/pkg/logger"
+	"dex-ingest-sol/internal/pkg/utils"
+	"dex-ingest-sol/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+// balanceAtSlot 某个账户在指定 slot 之前的最后一次变更
+type balanceAtSlot struct {
+	eventID   int64
+	balance   uint64
+	blockTime uint32
+}
+
+func (s *QueryBalanceService) QueryBalanceAtSlot(ctx context.Context, req *pb.BalanceAtSlotReq) (_ *pb.BalanceAtSlotResp, err error) {
+	const (
+		ErrCodeBase        = 61800
+		ErrCodePanic       = ErrCodeBase + 32
+		ErrCodeInvalidArg  = ErrCodeBase + 1
+		ErrCodeQueryFailed = ErrCodeBase + 2
+		ErrCodeScanFailed  = ErrCodeBase + 3
+		ErrCodeRowsIter    = ErrCodeBase + 4
+	)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("panic in QueryBalanceAtSlot: %v", r)
+			err = status.Errorf(codes.Internal, "[%d] server panic", ErrCodePanic)
+		}
+	}()
+
+	const maxAccounts = 100 // 防御性限制，同一 owner 同一 token 的账户数
+
+	where, params, ok := historyFilter(req.AccountAddress, req.OwnerAddress, req.TokenAddress)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "[%d] account_address or owner_address + token_address is required", ErrCodeInvalidArg)
+	}
+	if req.Slot >= 1<<31-1 {
+		return nil, status.Errorf(codes.Internal, "[%d] invalid slot %d", ErrCodeInvalidArg, req.Slot)
+	}
+	// event_id 高 32 位为 slot，小于下一个 slot 的起始 event_id 即为该 slot 处理完后的状态
+	params = append(params, int64((req.Slot+1)<<32))
+
+	// 第一步：每个账户在该 slot 之前的最后一次变更
+	query := "SELECT account_address, MAX(event_id) FROM balance_history WHERE " + where +
+		" AND event_id < ? GROUP BY account_address LIMIT ?"
+	params = append(params, maxAccounts)
+
+	rows, err := s.DB.QueryContext(ctx, query, params...)
+	if err != nil {
+		logger.Errorf("QueryBalanceAtSlot query failed: %v", err)
+		return nil, status.Errorf(codes.Internal, "[%d] query error", ErrCodeQueryFailed)
+	}
+	latest := make(map[string]int64)
+	for rows.Next() {
+		var (
+			account string
+			eventID int64
+		)
+		if scanErr := rows.Scan(&account, &eventID); scanErr != nil {
+			rows.Close()
+			logger.Errorf("QueryBalanceAtSlot row scan failed: %v", scanErr)
+			return nil, status.Errorf(codes.Internal, "[%d] data scan failed", ErrCodeScanFailed)
+		}
+		latest[account] = eventID
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		logger.Errorf("QueryBalanceAtSlot rows iteration error: %v", err)
+		return nil, status.Errorf(codes.Internal, "[%d] rows iteration error", ErrCodeRowsIter)
+	}
+	if len(latest) == 0 {
+		return &pb.BalanceAtSlotResp{}, nil
+	}
+
+	// 第二步：读取这些变更的余额，用 account IN 和 event_id IN 查询后按 (account, event_id) 过滤
+	args := make([]any, 0, len(latest)*2)
+	for account := range latest {
+		args = append(args, account)
+	}
+	for _, eventID := range latest {
+		args = append(args, eventID)
+	}
+	placeholders := strings.Repeat("?,", len(latest))
+	placeholders = placeholders[:len(placeholders)-1] // 去掉最后一个逗号
+	query = "SELECT account_address, event_id, post_balance, block_time FROM balance_history WHERE account_address IN (" +
+		placeholders + ") AND event_id IN (" + placeholders + ")"
+
+	rows, err = s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Errorf("QueryBalanceAtSlot query failed: %v", err)
+		return nil, status.Errorf(codes.Internal, "[%d] query error", ErrCodeQueryFailed)
+	}
+	defer rows.Close()
+
+	var (
+		total uint64
+		last  *balanceAtSlot
+	)
+	for rows.Next() {
+		var (
+			account, post string
+			b             = &balanceAtSlot{}
+		)
+		if scanErr := rows.Scan(&account, &b.eventID, &post, &b.blockTime); scanErr != nil {
+			logger.Errorf("QueryBalanceAtSlot row scan failed: %v", scanErr)
+			return nil, status.Errorf(codes.Internal, "[%d] data scan failed", ErrCodeScanFailed)
+		}
+		if latest[account] != b.eventID {
+			continue
+		}
+		b.balance = utils.ParseUint64(post)
+		total += b.balance
+		if last == nil || b.eventID > last.eventID {
+			last = b
+		}
+	}
+	if err = rows.Err(); err != nil {
+		logger.Errorf("QueryBalanceAtSlot rows iteration error: %v", err)
+		return nil, status.Errorf(codes.Internal, "[%d] rows iteration error", ErrCodeRowsIter)
+	}
+	if last == nil {
+		return &pb.BalanceAtSlotResp{}, nil
+	}
+
+	return &pb.BalanceAtSlotResp{
+		Balance:     &total,
+		LastEventId: uint64(last.eventID),
+		BlockTime:   last.blockTime,
+	}, nil
+}
@@ -0,0 +1,109 @@
+package balance
+
+import (
+	"context"
+	"dex-ingest-sol/internal/pkg/logger"
+	"dex-ingest-sol/internal/pkg/utils"
+	"dex-ingest-sol/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+func (s *QueryBalanceService) QueryBalanceHistory(ctx context.Context, req *pb.BalanceHistoryReq) (_ *pb.BalanceHistoryResp, err error) {
+	const (
+		ErrCodeBase        = 61700
+		ErrCodePanic       = ErrCodeBase + 32
+		ErrCodeInvalidArg  = ErrCodeBase + 1
+		ErrCodeQueryFailed = ErrCodeBase + 2
+		ErrCodeScanFailed  = ErrCodeBase + 3
+		ErrCodeRowsIter    = ErrCodeBase + 4
+	)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("panic in QueryBalanceHistory: %v", r)
+			err = status.Errorf(codes.Internal, "[%d] server panic", ErrCodePanic)
+		}
+	}()
+
+	const (
+		DefaultLimit = 50
+		MaxLimit     = 500
+	)
+
+	where, params, ok := historyFilter(req.AccountAddress, req.OwnerAddress, req.TokenAddress)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "[%d] account_address or owner_address + token_address is required", ErrCodeInvalidArg)
+	}
+
+	var query strings.Builder
+	query.WriteString(`
+		SELECT account_address, event_id, owner_address, token_address,
+		       pre_balance, post_balance, delta, block_time
+		FROM balance_history
+		WHERE `)
+	query.WriteString(where)
+
+	// 分页游标（可选）
+	if req.EventId != nil && *req.EventId > 0 {
+		query.WriteString(" AND event_id < ?")
+		params = append(params, *req.EventId)
+	}
+
+	limit := DefaultLimit
+	if req.Limit != nil && *req.Limit > 0 {
+		limit = min(int(*req.Limit), MaxLimit)
+	}
+	query.WriteString(" ORDER BY event_id DESC LIMIT ?")
+	params = append(params, limit)
+
+	rows, err := s.DB.QueryContext(ctx, query.String(), params...)
+	if err != nil {
+		logger.Errorf("QueryBalanceHistory query failed: %v", err)
+		return nil, status.Errorf(codes.Internal, "[%d] query error", ErrCodeQueryFailed)
+	}
+	defer rows.Close()
+
+	changes := make([]*pb.BalanceChange, 0, limit)
+	for rows.Next() {
+		var (
+			pre, post string
+			c         = &pb.BalanceChange{}
+		)
+		if scanErr := rows.Scan(
+			&c.AccountAddress, &c.EventId, &c.OwnerAddress, &c.TokenAddress,
+			&pre, &post, &c.Delta, &c.BlockTime,
+		); scanErr != nil {
+			logger.Errorf("QueryBalanceHistory row scan failed: %v", scanErr)
+			return nil, status.Errorf(codes.Internal, "[%d] data scan failed", ErrCodeScanFailed)
+		}
+		c.PreBalance = utils.ParseUint64(pre)
+		c.PostBalance = utils.ParseUint64(post)
+		c.TokenAddress = utils.DecodeTokenAddress(c.TokenAddress)
+		changes = append(changes, c)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Errorf("QueryBalanceHistory rows iteration error: %v", err)
+		return nil, status.Errorf(codes.Internal, "[%d] rows iteration error", ErrCodeRowsIter)
+	}
+
+	return &pb.BalanceHistoryResp{Changes: changes}, nil
+}
+
+// historyFilter 生成 balance_history 的查询条件：优先按账户，否则需要同时指定 owner 和 token
+func historyFilter(account, owner, token *string) (string, []any, bool) {
+	if account != nil {
+		if addr := strings.TrimSpace(*account); addr != "" {
+			return "account_address = ?", []any{addr}, true
+		}
+	}
+	if owner == nil || token == nil {
+		return "", nil, false
+	}
+	ownerAddr, tokenAddr := strings.TrimSpace(*owner), strings.TrimSpace(*token)
+	if ownerAddr == "" || tokenAddr == "" {
+		return "", nil, false
+	}
+	return "owner_address = ? AND token_address = ?", []any{ownerAddr, utils.EncodeTokenAddress(tokenAddr)}, true
+}
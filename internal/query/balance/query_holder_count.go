@@ -2,10 +2,12 @@ package balance
 
 import (
 	"context"
+	"database/sql"
 	"dex-ingest-sol/internal/pkg/db"
 	"dex-ingest-sol/internal/pkg/logger"
 	"dex-ingest-sol/internal/pkg/utils"
 	"dex-ingest-sol/pb"
+	"errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"strings"
@@ -32,11 +34,6 @@ func (s *QueryBalanceService) QueryHolderCountByToken(ctx context.Context, req *
 	}
 
 	encoded := utils.EncodeTokenAddress(token)
-	// 对于已知常用 Token（如 sol/usdc/usdt），由于持有人过多，统计代价高、实际用处不大，直接跳过
-	if utils.IsKnownToken(encoded) {
-		return &pb.HolderCountResp{Count: 0}, nil
-	}
-
 	resp, localErr := holderCountCache.Do(encoded, false, func(e *db.Entry, onlyReady bool) (resp any, localErr error) {
 		defer func() {
 			if r := recover(); r != nil {
@@ -56,19 +53,19 @@ func (s *QueryBalanceService) QueryHolderCountByToken(ctx context.Context, req *
 			return nil, status.Errorf(codes.NotFound, "cache not ready")
 		}
 
+		// 读取 ingest 增量维护的 token_holder_stats，尚未对账的 token 同样返回增量计数（由 ingest 对账补齐），
+		// 没有记录的 token 还没有持有人变化，返回 0
 		var count int64
-		queryErr := s.DB.QueryRowContext(ctx, `
-			SELECT COUNT(DISTINCT owner_address)
-			FROM balance
-			WHERE token_address = ?
-		`, encoded).Scan(&count)
-		if queryErr != nil {
+		queryErr := s.DB.QueryRowContext(ctx,
+			"SELECT holder_count FROM token_holder_stats WHERE token = ?", encoded,
+		).Scan(&count)
+		if queryErr != nil && !errors.Is(queryErr, sql.ErrNoRows) {
 			logger.Errorf("QueryHolderCountByToken failed: token=%s, err=%v", token, queryErr)
 			return nil, status.Errorf(codes.Internal, "[%d] query holder count failed", ErrCodeQueryFailed)
 		}
 
 		e.Result = count
-		e.SetValidAt(time.Now().Add(getHolderCountTTL(count)))
+		e.SetValidAt(time.Now().Add(holderCountTTL))
 		return &pb.HolderCountResp{Count: uint64(count)}, nil
 	})
 
@@ -77,22 +74,3 @@ func (s *QueryBalanceService) QueryHolderCountByToken(ctx context.Context, req *
 	}
 	return nil, localErr
 }
-
-func getHolderCountTTL(count int64) time.Duration {
-	switch {
-	case count < 500:
-		return 5 * time.Second
-	case count < 1_000:
-		return 10 * time.Second
-	case count < 5_000:
-		return 15 * time.Second
-	case count < 10_000:
-		return 30 * time.Second
-	case count < 20_000:
-		return 45 * time.Second
-	case count < 50_000:
-		return 1 * time.Minute
-	default:
-		return 5 * time.Minute
-	}
-}
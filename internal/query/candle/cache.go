@@ -0,0 +1,17 @@
+package candle
+
+import (
+	"dex-ingest-sol/internal/pkg/db"
+	"time"
+)
+
+// 缓存 TTL 设置
+const (
+	candlesTTL      = 3 * time.Second  // 最新一根 K 线随成交持续变化，TTL 不宜过长
+	candlesEmptyTTL = 10 * time.Second // 空结果 TTL，防止穿透
+)
+
+// 缓存实例
+var (
+	candlesCache = db.NewLockCache(500)
+)
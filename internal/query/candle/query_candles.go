@@ -0,0 +1,132 @@
+package candle
+
+import (
+	"context"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/logger"
+	"dex-ingest-sol/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxCandles 单次请求最多返回的 K 线数量
+const MaxCandles = 1500
+
+// intervalSeconds 支持的 K 线周期，与落库时的 handler.CandleIntervals 一致
+var intervalSeconds = map[string]int64{
+	"1m":  60,
+	"5m":  300,
+	"15m": 900,
+	"1h":  3600,
+	"4h":  14400,
+	"1d":  86400,
+}
+
+func (s *QueryCandleService) QueryCandles(ctx context.Context, req *pb.CandlesReq) (_ *pb.CandlesResp, err error) {
+	const (
+		ErrCodeBase         = 61400
+		ErrCodePanic        = ErrCodeBase + 32
+		ErrCodeInvalidArg   = ErrCodeBase + 1
+		ErrCodeRangeTooWide = ErrCodeBase + 2
+		ErrCodeQueryFailed  = ErrCodeBase + 3
+		ErrCodeScanFailed   = ErrCodeBase + 4
+		ErrCodeRowsIter     = ErrCodeBase + 5
+	)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("panic in QueryCandles: %v", r)
+			err = status.Errorf(codes.Internal, "[%d] server panic", ErrCodePanic)
+		}
+	}()
+
+	pool := strings.TrimSpace(req.PoolAddress)
+	if pool == "" {
+		return nil, status.Errorf(codes.Internal, "[%d] pool_address is required", ErrCodeInvalidArg)
+	}
+	interval, ok := intervalSeconds[req.Interval]
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "[%d] unsupported interval: %s", ErrCodeInvalidArg, req.Interval)
+	}
+
+	to := req.To
+	if to <= 0 {
+		to = time.Now().Unix()
+	}
+	from := req.From - req.From%interval
+	if from < 0 || from > to {
+		return nil, status.Errorf(codes.Internal, "[%d] invalid time range [%d, %d]", ErrCodeInvalidArg, req.From, to)
+	}
+	if (to-from)/interval+1 > MaxCandles {
+		return nil, status.Errorf(codes.Internal, "[%d] at most %d candles are allowed in a single request", ErrCodeRangeTooWide, MaxCandles)
+	}
+
+	query := `
+		SELECT open_time, token, quote_token, open_price, high_price, low_price, close_price,
+		       volume_usd, base_volume, quote_volume, buy_count, sell_count
+		FROM candle
+		WHERE pool_address = ? AND interval_sec = ? AND open_time >= ? AND open_time <= ?
+		ORDER BY open_time`
+	params := []any{pool, interval, from, to}
+	key := pool + ":" + req.Interval + ":" + strconv.FormatInt(from, 16) + ":" + strconv.FormatInt(req.To, 16)
+
+	resp, localErr := candlesCache.Do(key, false, func(e *db.Entry, onlyReady bool) (resp any, localErr error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Errorf("panic in QueryCandles cache func: %v", r)
+				localErr = status.Errorf(codes.Internal, "[%d] server panic", ErrCodePanic)
+				resp = nil
+			}
+		}()
+
+		if !e.IsExpired() {
+			if cached, ok := e.Result.(*pb.CandlesResp); ok {
+				return cached, nil
+			}
+		}
+		if onlyReady {
+			return nil, status.Errorf(codes.NotFound, "cache not ready")
+		}
+
+		rows, queryErr := s.DB.QueryContext(ctx, query, params...)
+		if queryErr != nil {
+			logger.Errorf("QueryCandles query failed, req=%+v, err=%v", req, queryErr)
+			return nil, status.Errorf(codes.Internal, "[%d] query failed", ErrCodeQueryFailed)
+		}
+		defer rows.Close()
+
+		result := &pb.CandlesResp{}
+		for rows.Next() {
+			c := &pb.Candle{}
+			if queryErr = rows.Scan(
+				&c.OpenTime, &result.Token, &result.QuoteToken, &c.Open, &c.High, &c.Low, &c.Close,
+				&c.VolumeUsd, &c.BaseVolume, &c.QuoteVolume, &c.BuyCount, &c.SellCount,
+			); queryErr != nil {
+				logger.Errorf("QueryCandles row scan failed: %v", queryErr)
+				return nil, status.Errorf(codes.Internal, "[%d] data scan failed", ErrCodeScanFailed)
+			}
+			result.Candles = append(result.Candles, c)
+		}
+
+		if queryErr = rows.Err(); queryErr != nil {
+			logger.Errorf("QueryCandles rows iteration error: %v", queryErr)
+			return nil, status.Errorf(codes.Internal, "[%d] rows iteration error", ErrCodeRowsIter)
+		}
+
+		e.Result = result
+		if len(result.Candles) == 0 {
+			e.SetValidAt(time.Now().Add(candlesEmptyTTL))
+		} else {
+			e.SetValidAt(time.Now().Add(candlesTTL))
+		}
+		return result, nil
+	})
+
+	if r, ok := resp.(*pb.CandlesResp); ok {
+		return r, nil
+	}
+	return nil, localErr
+}
@@ -0,0 +1,11 @@
+package candle
+
+import "database/sql"
+
+type QueryCandleService struct {
+	DB *sql.DB
+}
+
+func NewQueryCandleService(db *sql.DB) *QueryCandleService {
+	return &QueryCandleService{DB: db}
+}
@@ -10,6 +10,7 @@ const (
 	chainEventsByIDsTTL       = 5 * time.Second
 	chainEventsByPoolTTL      = 10 * time.Second
 	chainEventsByPoolEmptyTTL = 3 * time.Second
+	chainEventsByPoolHotTTL   = 2 * time.Second // 来自 Redis 热点缓存的首页
 	chainEventsByUserTTL      = 30 * time.Second
 	transferEventsTTL         = 30 * time.Second
 )
@@ -0,0 +1,50 @@
+package chainevent
+
+import (
+	"context"
+	"dex-ingest-sol/internal/pkg/poolcache"
+	"dex-ingest-sol/pb"
+)
+
+// queryPoolEventsFromRedis 从 ingest 维护的热点池子缓存读取首页事件。
+// 缓存保存池子最近的连续事件，过滤后仍有 limit 条时才能代替查库，否则返回 false 回退到数据库。
+func (s *QueryChainEventService) queryPoolEventsFromRedis(ctx context.Context, pool string, eventTypes []uint32, limit int) ([]*pb.ChainEvent, bool, error) {
+	wanted := make(map[uint32]struct{}, len(eventTypes))
+	for _, et := range eventTypes {
+		if !poolcache.IsCachedType(et) {
+			return nil, false, nil
+		}
+		wanted[et] = struct{}{}
+	}
+
+	// 查询全部缓存类型时只需读取前 limit 条，否则读取整个集合后过滤
+	stop := int64(-1)
+	if len(wanted) == len(poolcache.EventTypes) {
+		stop = int64(limit - 1)
+	}
+	members, err := s.Redis.ZRevRange(ctx, poolcache.EventsKey(pool), 0, stop).Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	result := make([]*pb.ChainEvent, 0, limit)
+	for _, member := range members {
+		ev, err := poolcache.DecodeEvent(member)
+		if err != nil {
+			return nil, false, err
+		}
+		if _, ok := wanted[ev.EventType]; !ok {
+			continue
+		}
+		if ev.Signer == "" {
+			ev.Signer = ev.UserWallet
+		}
+		// 缓存中不保存写库时间，用区块时间代替
+		ev.CreateAt = ev.BlockTime
+		result = append(result, ev)
+		if len(result) == limit {
+			return result, true, nil
+		}
+	}
+	return nil, false, nil
+}
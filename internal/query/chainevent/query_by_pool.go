@@ -65,6 +65,7 @@ func (s *QueryChainEventService) QueryEventsByPool(ctx context.Context, req *pb.
 			uint32(pb.EventType_ADD_LIQUIDITY),
 			uint32(pb.EventType_REMOVE_LIQUIDITY),
 			uint32(pb.EventType_BURN),
+			uint32(pb.EventType_MIGRATE),
 		}
 	}
 
@@ -120,6 +121,18 @@ func (s *QueryChainEventService) QueryEventsByPool(ctx context.Context, req *pb.
 			return nil, status.Errorf(codes.NotFound, "cache not ready")
 		}
 
+		// 首页优先读取 Redis 热点缓存，读取失败或数据不足时回退到数据库
+		if s.Redis != nil && (req.EventId == nil || *req.EventId == 0) {
+			hot, ok, redisErr := s.queryPoolEventsFromRedis(ctx, req.PoolAddress, eventTypes, limit)
+			if redisErr != nil {
+				logger.Warnf("QueryEventsByPool read pool cache failed, pool=%s, err=%v", req.PoolAddress, redisErr)
+			} else if ok {
+				e.Result = hot
+				e.SetValidAt(time.Now().Add(chainEventsByPoolHotTTL))
+				return &pb.EventResp{Events: hot}, nil
+			}
+		}
+
 		// 执行查询
 		rows, queryErr := s.DB.QueryContext(ctx, query.String(), params...)
 		if queryErr != nil {
@@ -1,11 +1,16 @@
 package chainevent
 
-import "database/sql"
+import (
+	"database/sql"
+
+	"github.com/redis/go-redis/v9"
+)
 
 type QueryChainEventService struct {
-	DB *sql.DB
+	DB    *sql.DB
+	Redis redis.UniversalClient // 热点池子缓存，为 nil 时直接查库
 }
 
-func NewQueryChainEventService(db *sql.DB) *QueryChainEventService {
-	return &QueryChainEventService{DB: db}
+func NewQueryChainEventService(db *sql.DB, rdb redis.UniversalClient) *QueryChainEventService {
+	return &QueryChainEventService{DB: db, Redis: rdb}
 }
@@ -0,0 +1,71 @@
+package checkpoint
+
+import (
+	"context"
+	"dex-ingest-sol/internal/pkg/logger"
+	"dex-ingest-sol/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// QueryIndexedSlot 返回各 router 的索引进度。indexed_slot 取所有分区 max_slot 的最小值，
+// 同一 slot 的数据可能分布在多个分区，只有最慢的分区越过该 slot 后才能保证数据完整。
+func (s *QueryCheckpointService) QueryIndexedSlot(ctx context.Context, req *pb.IndexedSlotReq) (resp *pb.IndexedSlotResp, err error) {
+	const (
+		ErrCodeBase        = 61200
+		ErrCodePanic       = ErrCodeBase + 32
+		ErrCodeQueryFailed = ErrCodeBase + 1
+		ErrCodeScanFailed  = ErrCodeBase + 2
+		ErrCodeRowsIter    = ErrCodeBase + 3
+	)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("panic in QueryIndexedSlot: %v", r)
+			err = status.Errorf(codes.Internal, "[%d] server panic", ErrCodePanic)
+		}
+	}()
+
+	query := `
+		SELECT router, topic, partition_id, kafka_offset, max_slot, update_at
+		FROM ingest_checkpoint
+		WHERE partition_id >= 0`
+	var args []any
+	if req.Router != "" {
+		query += " AND router = ?"
+		args = append(args, req.Router)
+	}
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Errorf("QueryIndexedSlot query failed: %v", err)
+		return nil, status.Errorf(codes.Internal, "[%d] query failed", ErrCodeQueryFailed)
+	}
+	defer rows.Close()
+
+	resp = &pb.IndexedSlotResp{}
+	watermarks := make(map[string]*pb.RouterWatermark)
+	for rows.Next() {
+		var router string
+		cp := &pb.PartitionCheckpoint{}
+		if err := rows.Scan(&router, &cp.Topic, &cp.Partition, &cp.KafkaOffset, &cp.MaxSlot, &cp.UpdateAt); err != nil {
+			logger.Errorf("QueryIndexedSlot row scan failed: %v", err)
+			return nil, status.Errorf(codes.Internal, "[%d] data scan failed", ErrCodeScanFailed)
+		}
+
+		wm, ok := watermarks[router]
+		if !ok {
+			wm = &pb.RouterWatermark{Router: router, IndexedSlot: cp.MaxSlot}
+			watermarks[router] = wm
+			resp.Routers = append(resp.Routers, wm)
+		}
+		wm.IndexedSlot = min(wm.IndexedSlot, cp.MaxSlot)
+		wm.Partitions = append(wm.Partitions, cp)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Errorf("QueryIndexedSlot rows iteration error: %v", err)
+		return nil, status.Errorf(codes.Internal, "[%d] rows iteration error", ErrCodeRowsIter)
+	}
+	return resp, nil
+}
@@ -0,0 +1,11 @@
+package checkpoint
+
+import "database/sql"
+
+type QueryCheckpointService struct {
+	DB *sql.DB
+}
+
+func NewQueryCheckpointService(db *sql.DB) *QueryCheckpointService {
+	return &QueryCheckpointService{DB: db}
+}
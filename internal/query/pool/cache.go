@@ -11,10 +11,12 @@ const (
 	poolsByAddressEmptyTTL = 20 * time.Second  // 空结果 TTL，防止穿透
 	poolsByTokenTTL        = 60 * time.Second
 	poolsByTokenEmptyTTL   = 20 * time.Second // 空结果 TTL，防止穿透
+	poolStateTTL           = 3 * time.Second  // 储备量随每笔交易变化，单独短 TTL 缓存
 )
 
 // 缓存实例
 var (
 	poolsByAddressCache = db.NewLockCache(300)
 	poolsByTokenCache   = db.NewLockCache(300)
+	poolStateCache      = db.NewLockCache(1000)
 )
@@ -0,0 +1,53 @@
+package pool
+
+import (
+	"context"
+	"dex-ingest-sol/internal/pkg/poolcache"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// hotPoolState 为 ingest 在 Redis 中维护的池子最近一笔交易
+type hotPoolState struct {
+	priceUsd      float64
+	lastTradeTime uint32
+}
+
+// loadHotPoolStates 从热点池子缓存批量读取最近一笔交易的价格和时间，没有缓存的池子不在结果中
+func (s *QueryPoolService) loadHotPoolStates(ctx context.Context, addresses []string) (map[string]*hotPoolState, error) {
+	cmds := make([]*redis.SliceCmd, len(addresses))
+	_, err := s.Redis.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, addr := range addresses {
+			cmds[i] = p.HMGet(ctx, poolcache.StateKey(addr), poolcache.FieldPriceUsd, poolcache.FieldLastTradeTime)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]*hotPoolState, len(addresses))
+	for i, cmd := range cmds {
+		vals := cmd.Val()
+		if len(vals) != 2 {
+			continue
+		}
+		price, ok1 := vals[0].(string)
+		tradeTime, ok2 := vals[1].(string)
+		if !ok1 || !ok2 {
+			continue
+		}
+		st := &hotPoolState{}
+		if st.priceUsd, err = strconv.ParseFloat(price, 64); err != nil {
+			continue
+		}
+		t, err := strconv.ParseUint(tradeTime, 10, 32)
+		if err != nil {
+			continue
+		}
+		st.lastTradeTime = uint32(t)
+		states[addresses[i]] = st
+	}
+	return states, nil
+}
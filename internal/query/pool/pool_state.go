@@ -0,0 +1,148 @@
+package pool
+
+import (
+	"context"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/logger"
+	"dex-ingest-sol/internal/pkg/utils"
+	"dex-ingest-sol/pb"
+	"google.golang.org/protobuf/proto"
+	"strings"
+	"time"
+)
+
+// poolState 为 pool_state 表中对外返回的字段
+type poolState struct {
+	tokenReserve  uint64
+	quoteReserve  uint64
+	priceUsd      float64
+	liquidityUsd  float64
+	lastTradeTime uint32
+}
+
+// withPoolStates 为池子补充最新储备量和流动性，返回新的切片，不修改缓存中的对象。
+// 最近成交价和成交时间优先取 Redis 热点池子缓存中较新的值（pool_state 结果有本地缓存，可能落后几秒）。
+// pool_state 或 Redis 查询失败时只记录日志，返回可用的部分。
+func (s *QueryPoolService) withPoolStates(ctx context.Context, pools []*pb.Pool) []*pb.Pool {
+	if len(pools) == 0 {
+		return pools
+	}
+
+	addresses := make([]string, 0, len(pools))
+	for _, p := range pools {
+		addresses = append(addresses, p.PoolAddress)
+	}
+	states, err := s.loadPoolStates(ctx, addresses)
+	if err != nil {
+		logger.Errorf("load pool_state failed: %v", err)
+		states = nil
+	}
+	var hot map[string]*hotPoolState
+	if s.Redis != nil {
+		if hot, err = s.loadHotPoolStates(ctx, addresses); err != nil {
+			logger.Warnf("load pool state from redis failed: %v", err)
+		}
+	}
+
+	result := make([]*pb.Pool, 0, len(pools))
+	for _, p := range pools {
+		st, ok := states[p.PoolAddress]
+		h, hasHot := hot[p.PoolAddress]
+		if !ok && !hasHot {
+			result = append(result, p)
+			continue
+		}
+		withState := proto.Clone(p).(*pb.Pool)
+		if ok {
+			withState.TokenReserve = &st.tokenReserve
+			withState.QuoteReserve = &st.quoteReserve
+			withState.PriceUsd = &st.priceUsd
+			withState.LiquidityUsd = &st.liquidityUsd
+			withState.LastTradeTime = &st.lastTradeTime
+		}
+		if hasHot && (!ok || h.lastTradeTime >= st.lastTradeTime) {
+			withState.PriceUsd = &h.priceUsd
+			withState.LastTradeTime = &h.lastTradeTime
+		}
+		result = append(result, withState)
+	}
+	return result
+}
+
+// loadPoolStates 先读缓存，未命中的池子批量查询 pool_state（不存在的池子也缓存，防止穿透）
+func (s *QueryPoolService) loadPoolStates(ctx context.Context, addresses []string) (map[string]*poolState, error) {
+	states := make(map[string]*poolState, len(addresses))
+	missing := make([]string, 0, len(addresses))
+	seen := make(map[string]struct{}, len(addresses))
+	for _, addr := range addresses {
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
+
+		found := false
+		poolStateCache.DoRead(addr, func(e *db.Entry) {
+			if cached, ok := e.Result.(*poolState); ok {
+				if cached != nil {
+					states[addr] = cached
+				}
+				found = true
+			}
+		})
+		if !found {
+			missing = append(missing, addr)
+		}
+	}
+	if len(missing) == 0 {
+		return states, nil
+	}
+
+	query := `
+		SELECT pool_address, token_reserve, quote_reserve, price_usd, liquidity_usd, last_trade_time
+		FROM pool_state
+		WHERE pool_address IN (`
+	placeholders := strings.Repeat("?,", len(missing))
+	query += placeholders[:len(placeholders)-1] + ")"
+
+	args := make([]any, len(missing))
+	for i, addr := range missing {
+		args[i] = addr
+	}
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			addr                       string
+			tokenReserve, quoteReserve string
+			st                         = &poolState{}
+		)
+		if err := rows.Scan(&addr, &tokenReserve, &quoteReserve, &st.priceUsd, &st.liquidityUsd, &st.lastTradeTime); err != nil {
+			return nil, err
+		}
+		st.tokenReserve = utils.ParseUint64(tokenReserve)
+		st.quoteReserve = utils.ParseUint64(quoteReserve)
+		states[addr] = st
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, addr := range missing {
+		setPoolStateCache(addr, states[addr])
+	}
+	return states, nil
+}
+
+// setPoolStateCache 写入缓存，value 为 nil 表示该池子还没有状态
+func setPoolStateCache(key string, value *poolState) {
+	poolStateCache.Do(key, true, func(e *db.Entry, onlyReady bool) (resp any, localErr error) {
+		e.Result = value
+		e.SetValidAt(time.Now().Add(poolStateTTL))
+		return value, nil
+	})
+}
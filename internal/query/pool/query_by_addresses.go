@@ -59,7 +59,7 @@ func (s *QueryPoolService) QueryPoolsByAddresses(ctx context.Context, req *pb.Po
 		}
 	}
 	if len(missingAddrs) == 0 {
-		return makeResult(poolMap, addresses), nil
+		return s.makeResultWithStates(ctx, poolMap, addresses), nil
 	}
 
 	// 构建查询语句
@@ -119,7 +119,22 @@ func (s *QueryPoolService) QueryPoolsByAddresses(ctx context.Context, req *pb.Po
 			setPoolsCache(addr, []*pb.Pool{})
 		}
 	}
-	return makeResult(poolMap, addresses), nil
+	return s.makeResultWithStates(ctx, poolMap, addresses), nil
+}
+
+// makeResultWithStates 构建结果并补充池子最新状态（状态缓存 TTL 较短，与池子静态信息分开缓存）
+func (s *QueryPoolService) makeResultWithStates(ctx context.Context, poolMap map[string][]*pb.Pool, addresses []string) *pb.PoolListResp {
+	var all []*pb.Pool
+	for _, pools := range poolMap {
+		all = append(all, pools...)
+	}
+	withStates := s.withPoolStates(ctx, all)
+
+	stateMap := make(map[string][]*pb.Pool, len(poolMap))
+	for _, p := range withStates {
+		stateMap[p.PoolAddress] = append(stateMap[p.PoolAddress], p)
+	}
+	return makeResult(stateMap, addresses)
 }
 
 func setPoolsCache(key string, value []*pb.Pool) {
@@ -124,7 +124,7 @@ func (s *QueryPoolService) QueryPoolsByToken(ctx context.Context, req *pb.PoolTo
 	})
 
 	if r, ok := resp.(*pb.PoolResp); ok {
-		return r, nil
+		return &pb.PoolResp{Pools: s.withPoolStates(ctx, r.Pools)}, nil
 	}
 	return nil, localErr
 }
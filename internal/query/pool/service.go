@@ -1,11 +1,16 @@
 package pool
 
-import "database/sql"
+import (
+	"database/sql"
+
+	"github.com/redis/go-redis/v9"
+)
 
 type QueryPoolService struct {
-	DB *sql.DB
+	DB    *sql.DB
+	Redis redis.UniversalClient // 热点池子缓存，为 nil 时只读 pool_state
 }
 
-func NewQueryPoolService(db *sql.DB) *QueryPoolService {
-	return &QueryPoolService{DB: db}
+func NewQueryPoolService(db *sql.DB, rdb redis.UniversalClient) *QueryPoolService {
+	return &QueryPoolService{DB: db, Redis: rdb}
 }
@@ -0,0 +1,18 @@
+package quoteprice
+
+import (
+	"dex-ingest-sol/internal/pkg/db"
+	"time"
+)
+
+// 缓存 TTL 设置
+const (
+	quotePriceTTL       = 60 * time.Second // 指定 slot / 时间的历史价格基本不变
+	quotePriceLatestTTL = 2 * time.Second  // 最新价格每个 slot 都会变化
+	quotePriceEmptyTTL  = 10 * time.Second // 空结果 TTL，防止穿透
+)
+
+// 缓存实例
+var (
+	quotePriceCache = db.NewLockCache(1000)
+)
@@ -0,0 +1,104 @@
+package quoteprice
+
+import (
+	"context"
+	"database/sql"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/logger"
+	"dex-ingest-sol/internal/pkg/utils"
+	"dex-ingest-sol/pb"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryQuotePrice 查询报价币在指定 slot 或时间点（含）之前最近一次记录的 USD 价格
+func (s *QueryQuotePriceService) QueryQuotePrice(ctx context.Context, req *pb.QuotePriceReq) (_ *pb.QuotePriceResp, err error) {
+	const (
+		ErrCodeBase        = 61300
+		ErrCodePanic       = ErrCodeBase + 32
+		ErrCodeInvalidArg  = ErrCodeBase + 1
+		ErrCodeQueryFailed = ErrCodeBase + 2
+	)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("panic in QueryQuotePrice: %v", r)
+			err = status.Errorf(codes.Internal, "[%d] server panic", ErrCodePanic)
+		}
+	}()
+
+	token := strings.TrimSpace(req.Token)
+	if token == "" {
+		return nil, status.Errorf(codes.Internal, "[%d] token is required", ErrCodeInvalidArg)
+	}
+	encoded := utils.EncodeTokenAddress(token)
+
+	query := `
+		SELECT slot, block_time, price, decimals
+		FROM quote_price
+		WHERE token = ?`
+	params := []any{encoded}
+	key := encoded
+	ttl := quotePriceLatestTTL
+
+	switch {
+	case req.Slot != nil:
+		query += " AND slot <= ? ORDER BY slot DESC LIMIT 1"
+		params = append(params, *req.Slot)
+		key += ":slot:" + strconv.FormatUint(*req.Slot, 10)
+		ttl = quotePriceTTL
+	case req.BlockTime != nil:
+		query += " AND block_time <= ? ORDER BY block_time DESC LIMIT 1"
+		params = append(params, *req.BlockTime)
+		key += ":time:" + strconv.FormatInt(*req.BlockTime, 10)
+		ttl = quotePriceTTL
+	default:
+		query += " ORDER BY slot DESC LIMIT 1"
+	}
+
+	resp, localErr := quotePriceCache.Do(key, false, func(e *db.Entry, onlyReady bool) (resp any, localErr error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Errorf("panic in QueryQuotePrice cache func: %v", r)
+				localErr = status.Errorf(codes.Internal, "[%d] server panic", ErrCodePanic)
+				resp = nil
+			}
+		}()
+
+		if !e.IsExpired() {
+			if cached, ok := e.Result.(*pb.QuotePriceResp); ok {
+				return cached, nil
+			}
+		}
+		if onlyReady {
+			return nil, status.Errorf(codes.NotFound, "cache not ready")
+		}
+
+		p := &pb.QuotePrice{Token: token}
+		queryErr := s.DB.QueryRowContext(ctx, query, params...).Scan(&p.Slot, &p.BlockTime, &p.Price, &p.Decimals)
+		if errors.Is(queryErr, sql.ErrNoRows) {
+			result := &pb.QuotePriceResp{}
+			e.Result = result
+			e.SetValidAt(time.Now().Add(quotePriceEmptyTTL))
+			return result, nil
+		}
+		if queryErr != nil {
+			logger.Errorf("QueryQuotePrice query failed: %v", queryErr)
+			return nil, status.Errorf(codes.Internal, "[%d] query failed", ErrCodeQueryFailed)
+		}
+
+		result := &pb.QuotePriceResp{Price: p}
+		e.Result = result
+		e.SetValidAt(time.Now().Add(ttl))
+		return result, nil
+	})
+
+	if r, ok := resp.(*pb.QuotePriceResp); ok {
+		return r, nil
+	}
+	return nil, localErr
+}
@@ -0,0 +1,11 @@
+package quoteprice
+
+import "database/sql"
+
+type QueryQuotePriceService struct {
+	DB *sql.DB
+}
+
+func NewQueryQuotePriceService(db *sql.DB) *QueryQuotePriceService {
+	return &QueryQuotePriceService{DB: db}
+}
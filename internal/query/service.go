@@ -4,9 +4,16 @@ import (
 	"context"
 	"database/sql"
 	"dex-ingest-sol/internal/query/balance"
+	"dex-ingest-sol/internal/query/candle"
 	"dex-ingest-sol/internal/query/chainevent"
+	"dex-ingest-sol/internal/query/checkpoint"
 	"dex-ingest-sol/internal/query/pool"
+	"dex-ingest-sol/internal/query/quoteprice"
+	"dex-ingest-sol/internal/query/token"
+	"dex-ingest-sol/internal/query/wallet"
 	"dex-ingest-sol/pb"
+
+	"github.com/redis/go-redis/v9"
 )
 
 type QueryService struct {
@@ -15,13 +22,23 @@ type QueryService struct {
 	balanceService    *balance.QueryBalanceService
 	chainEventService *chainevent.QueryChainEventService
 	poolService       *pool.QueryPoolService
+	tokenService      *token.QueryTokenService
+	candleService     *candle.QueryCandleService
+	quotePriceService *quoteprice.QueryQuotePriceService
+	walletService     *wallet.QueryWalletService
+	checkpointService *checkpoint.QueryCheckpointService
 }
 
-func NewQueryService(db *sql.DB) *QueryService {
+func NewQueryService(db *sql.DB, rdb redis.UniversalClient) *QueryService {
 	return &QueryService{
 		balanceService:    balance.NewQueryBalanceService(db),
-		chainEventService: chainevent.NewQueryChainEventService(db),
-		poolService:       pool.NewQueryPoolService(db),
+		chainEventService: chainevent.NewQueryChainEventService(db, rdb),
+		poolService:       pool.NewQueryPoolService(db, rdb),
+		tokenService:      token.NewQueryTokenService(db),
+		candleService:     candle.NewQueryCandleService(db),
+		quotePriceService: quoteprice.NewQueryQuotePriceService(db),
+		walletService:     wallet.NewQueryWalletService(db),
+		checkpointService: checkpoint.NewQueryCheckpointService(db),
 	}
 }
 
@@ -42,6 +59,14 @@ func (s *QueryService) QueryHolderCountByToken(ctx context.Context, req *pb.Toke
 	return s.balanceService.QueryHolderCountByToken(ctx, req)
 }
 
+func (s *QueryService) QueryBalanceHistory(ctx context.Context, req *pb.BalanceHistoryReq) (*pb.BalanceHistoryResp, error) {
+	return s.balanceService.QueryBalanceHistory(ctx, req)
+}
+
+func (s *QueryService) QueryBalanceAtSlot(ctx context.Context, req *pb.BalanceAtSlotReq) (*pb.BalanceAtSlotResp, error) {
+	return s.balanceService.QueryBalanceAtSlot(ctx, req)
+}
+
 // Event 相关
 func (s *QueryService) QueryEventsByIDs(ctx context.Context, req *pb.EventIDsReq) (*pb.EventListResp, error) {
 	return s.chainEventService.QueryEventsByIDs(ctx, req)
@@ -67,3 +92,32 @@ func (s *QueryService) QueryPoolsByAddresses(ctx context.Context, req *pb.PoolAd
 func (s *QueryService) QueryPoolsByToken(ctx context.Context, req *pb.PoolTokenReq) (*pb.PoolResp, error) {
 	return s.poolService.QueryPoolsByToken(ctx, req)
 }
+
+// Token 相关
+func (s *QueryService) QueryTokensByAddresses(ctx context.Context, req *pb.TokenAddressesReq) (*pb.TokenListResp, error) {
+	return s.tokenService.QueryTokensByAddresses(ctx, req)
+}
+
+func (s *QueryService) QueryTokenStats(ctx context.Context, req *pb.TokenStatsReq) (*pb.TokenStatsResp, error) {
+	return s.tokenService.QueryTokenStats(ctx, req)
+}
+
+// K 线相关
+func (s *QueryService) QueryCandles(ctx context.Context, req *pb.CandlesReq) (*pb.CandlesResp, error) {
+	return s.candleService.QueryCandles(ctx, req)
+}
+
+// 报价币价格相关
+func (s *QueryService) QueryQuotePrice(ctx context.Context, req *pb.QuotePriceReq) (*pb.QuotePriceResp, error) {
+	return s.quotePriceService.QueryQuotePrice(ctx, req)
+}
+
+// 钱包持仓相关
+func (s *QueryService) QueryWalletPositions(ctx context.Context, req *pb.WalletPositionsReq) (*pb.WalletPositionsResp, error) {
+	return s.walletService.QueryWalletPositions(ctx, req)
+}
+
+// 索引进度相关
+func (s *QueryService) QueryIndexedSlot(ctx context.Context, req *pb.IndexedSlotReq) (*pb.IndexedSlotResp, error) {
+	return s.checkpointService.QueryIndexedSlot(ctx, req)
+}
@@ -0,0 +1,19 @@
+package token
+
+import (
+	"dex-ingest-sol/internal/pkg/db"
+	"time"
+)
+
+// 缓存 TTL 设置
+const (
+	tokensByAddressTTL      = 300 * time.Second // token 元数据基本不变，TTL 可以放长
+	tokensByAddressEmptyTTL = 20 * time.Second  // 空结果 TTL，防止穿透
+	tokenStatsTTL           = 5 * time.Second   // 统计每隔十几秒刷新一次
+)
+
+// 缓存实例
+var (
+	tokensByAddressCache = db.NewLockCache(1000)
+	tokenStatsCache      = db.NewLockCache(1000)
+)
@@ -0,0 +1,141 @@
+package token
+
+import (
+	"context"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/logger"
+	"dex-ingest-sol/internal/pkg/utils"
+	"dex-ingest-sol/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+	"time"
+)
+
+func (s *QueryTokenService) QueryTokensByAddresses(ctx context.Context, req *pb.TokenAddressesReq) (resp *pb.TokenListResp, err error) {
+	const (
+		ErrCodeBase         = 61100
+		ErrCodePanic        = ErrCodeBase + 32
+		ErrCodeParamTooMany = ErrCodeBase + 1
+		ErrCodeQueryFailed  = ErrCodeBase + 2
+		ErrCodeScanFailed   = ErrCodeBase + 3
+		ErrCodeRowsIter     = ErrCodeBase + 4
+	)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("panic in QueryTokensByAddresses: %v", r)
+			err = status.Errorf(codes.Internal, "[%d] server panic", ErrCodePanic)
+		}
+	}()
+
+	const maxAddresses = 500
+
+	addresses := req.TokenAddresses
+	if len(addresses) == 0 {
+		return &pb.TokenListResp{}, nil
+	}
+	if len(addresses) > maxAddresses {
+		return nil, status.Errorf(codes.Internal, "[%d] at most %d token addresses are allowed in a single request", ErrCodeParamTooMany, maxAddresses)
+	}
+
+	var (
+		missingAddrs = make([]string, 0, len(addresses))
+		tokenMap     = make(map[string]*pb.Token, len(addresses)) // key 为编码后的地址
+	)
+
+	// 先从缓存中获取（缓存 key 与数据库一致，使用编码后的地址）
+	for _, addr := range addresses {
+		encoded := utils.EncodeTokenAddress(addr)
+		if _, ok := tokenMap[encoded]; ok {
+			continue
+		}
+		found := false
+		tokensByAddressCache.DoRead(encoded, func(e *db.Entry) {
+			if cached, ok := e.Result.(*pb.Token); ok {
+				tokenMap[encoded] = cached
+				found = true
+			}
+		})
+		if !found {
+			missingAddrs = append(missingAddrs, encoded)
+		}
+	}
+	if len(missingAddrs) == 0 {
+		return makeResult(tokenMap, addresses), nil
+	}
+
+	// 构建查询语句
+	query := `
+		SELECT token_address, decimals, source, total_supply, name, symbol, uri, creator, create_at, update_at
+		FROM token
+		WHERE token_address IN (`
+	placeholders := strings.Repeat("?,", len(missingAddrs))
+	query += placeholders[:len(placeholders)-1] + ")"
+
+	args := make([]any, len(missingAddrs))
+	for i, addr := range missingAddrs {
+		args[i] = addr
+	}
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Errorf("QueryTokensByAddresses query failed: %v", err)
+		return nil, status.Errorf(codes.Internal, "[%d] query failed", ErrCodeQueryFailed)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t := &pb.Token{}
+		var totalSupply string
+		if err := rows.Scan(
+			&t.TokenAddress, &t.Decimals, &t.Source, &totalSupply,
+			&t.Name, &t.Symbol, &t.Uri, &t.Creator, &t.CreateAt, &t.UpdateAt,
+		); err != nil {
+			logger.Errorf("QueryTokensByAddresses row scan failed: %v", err)
+			return nil, status.Errorf(codes.Internal, "[%d] data scan failed", ErrCodeScanFailed)
+		}
+		encoded := t.TokenAddress
+		t.TokenAddress = utils.DecodeTokenAddress(t.TokenAddress)
+		t.TotalSupply = utils.ParseUint64(totalSupply)
+		tokenMap[encoded] = t
+		setTokenCache(encoded, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Errorf("QueryTokensByAddresses rows iteration error: %v", err)
+		return nil, status.Errorf(codes.Internal, "[%d] rows iteration error", ErrCodeRowsIter)
+	}
+
+	// 空缓存优先级较低，仅在整体处理完成后写入，用于防止缓存穿透
+	for _, addr := range missingAddrs {
+		if _, ok := tokenMap[addr]; !ok {
+			setTokenCache(addr, nil)
+		}
+	}
+	return makeResult(tokenMap, addresses), nil
+}
+
+// setTokenCache 写入缓存，value 为 nil 表示数据库中不存在该 token
+func setTokenCache(key string, value *pb.Token) {
+	tokensByAddressCache.Do(key, true, func(e *db.Entry, onlyReady bool) (resp any, localErr error) {
+		e.Result = value
+		if value == nil {
+			e.SetValidAt(time.Now().Add(tokensByAddressEmptyTTL))
+		} else {
+			e.SetValidAt(time.Now().Add(tokensByAddressTTL))
+		}
+		return value, nil
+	})
+}
+
+func makeResult(tokenMap map[string]*pb.Token, addresses []string) *pb.TokenListResp {
+	results := make([]*pb.TokenResult, 0, len(addresses))
+	for _, addr := range addresses {
+		results = append(results, &pb.TokenResult{
+			TokenAddress: addr,
+			Token:        tokenMap[utils.EncodeTokenAddress(addr)],
+		})
+	}
+	return &pb.TokenListResp{Results: results}
+}
@@ -0,0 +1,151 @@
+package token
+
+import (
+	"context"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/logger"
+	"dex-ingest-sol/internal/pkg/utils"
+	"dex-ingest-sol/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sort"
+	"strings"
+	"time"
+)
+
+// windowNames 窗口长度（秒）与对外名称，与落库时的 tokenStatsWindows 一致
+var windowNames = map[int32]string{
+	300:   "5m",
+	3600:  "1h",
+	21600: "6h",
+	86400: "24h",
+}
+
+func (s *QueryTokenService) QueryTokenStats(ctx context.Context, req *pb.TokenStatsReq) (resp *pb.TokenStatsResp, err error) {
+	const (
+		ErrCodeBase         = 61500
+		ErrCodePanic        = ErrCodeBase + 32
+		ErrCodeParamTooMany = ErrCodeBase + 1
+		ErrCodeQueryFailed  = ErrCodeBase + 2
+		ErrCodeScanFailed   = ErrCodeBase + 3
+		ErrCodeRowsIter     = ErrCodeBase + 4
+	)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("panic in QueryTokenStats: %v", r)
+			err = status.Errorf(codes.Internal, "[%d] server panic", ErrCodePanic)
+		}
+	}()
+
+	const maxAddresses = 100
+
+	addresses := req.TokenAddresses
+	if len(addresses) == 0 {
+		return &pb.TokenStatsResp{}, nil
+	}
+	if len(addresses) > maxAddresses {
+		return nil, status.Errorf(codes.Internal, "[%d] at most %d token addresses are allowed in a single request", ErrCodeParamTooMany, maxAddresses)
+	}
+
+	var (
+		missingAddrs = make([]string, 0, len(addresses))
+		statsMap     = make(map[string][]*pb.TokenStatsWindow, len(addresses)) // key 为编码后的地址
+	)
+
+	// 先从缓存中获取（缓存 key 与数据库一致，使用编码后的地址）
+	for _, addr := range addresses {
+		encoded := utils.EncodeTokenAddress(addr)
+		if _, ok := statsMap[encoded]; ok {
+			continue
+		}
+		found := false
+		tokenStatsCache.DoRead(encoded, func(e *db.Entry) {
+			if cached, ok := e.Result.([]*pb.TokenStatsWindow); ok {
+				statsMap[encoded] = cached
+				found = true
+			}
+		})
+		if !found {
+			missingAddrs = append(missingAddrs, encoded)
+		}
+	}
+
+	if len(missingAddrs) > 0 {
+		query := `
+			SELECT token, window_sec, volume_usd, trade_count, buy_count, sell_count, unique_traders,
+			       price_open, price_close, price_change, tracked_since, update_at
+			FROM token_stats
+			WHERE token IN (`
+		placeholders := strings.Repeat("?,", len(missingAddrs))
+		query += placeholders[:len(placeholders)-1] + ")"
+
+		args := make([]any, len(missingAddrs))
+		for i, addr := range missingAddrs {
+			args[i] = addr
+		}
+
+		rows, err := s.DB.QueryContext(ctx, query, args...)
+		if err != nil {
+			logger.Errorf("QueryTokenStats query failed: %v", err)
+			return nil, status.Errorf(codes.Internal, "[%d] query failed", ErrCodeQueryFailed)
+		}
+		defer rows.Close()
+
+		windowSecs := make(map[*pb.TokenStatsWindow]int32)
+		for rows.Next() {
+			var (
+				token     string
+				windowSec int32
+				w         = &pb.TokenStatsWindow{}
+			)
+			if err := rows.Scan(
+				&token, &windowSec, &w.VolumeUsd, &w.TradeCount, &w.BuyCount, &w.SellCount, &w.UniqueTraders,
+				&w.PriceOpen, &w.PriceClose, &w.PriceChange, &w.TrackedSince, &w.UpdateAt,
+			); err != nil {
+				logger.Errorf("QueryTokenStats row scan failed: %v", err)
+				return nil, status.Errorf(codes.Internal, "[%d] data scan failed", ErrCodeScanFailed)
+			}
+			name, ok := windowNames[windowSec]
+			if !ok {
+				continue
+			}
+			w.Window = name
+			windowSecs[w] = windowSec
+			statsMap[token] = append(statsMap[token], w)
+		}
+
+		if err := rows.Err(); err != nil {
+			logger.Errorf("QueryTokenStats rows iteration error: %v", err)
+			return nil, status.Errorf(codes.Internal, "[%d] rows iteration error", ErrCodeRowsIter)
+		}
+
+		for _, addr := range missingAddrs {
+			windows := statsMap[addr]
+			sort.Slice(windows, func(i, j int) bool { return windowSecs[windows[i]] < windowSecs[windows[j]] })
+			setTokenStatsCache(addr, windows)
+		}
+	}
+
+	results := make([]*pb.TokenStatsResult, 0, len(addresses))
+	for _, addr := range addresses {
+		windows := statsMap[utils.EncodeTokenAddress(addr)]
+		if windows == nil {
+			windows = make([]*pb.TokenStatsWindow, 0) // 显式设为空数组，避免 nil
+		}
+		results = append(results, &pb.TokenStatsResult{TokenAddress: addr, Windows: windows})
+	}
+	return &pb.TokenStatsResp{Results: results}, nil
+}
+
+// setTokenStatsCache 写入缓存，空切片表示该 token 没有统计（同样缓存，防止穿透）
+func setTokenStatsCache(key string, value []*pb.TokenStatsWindow) {
+	if value == nil {
+		value = make([]*pb.TokenStatsWindow, 0)
+	}
+	tokenStatsCache.Do(key, true, func(e *db.Entry, onlyReady bool) (resp any, localErr error) {
+		e.Result = value
+		e.SetValidAt(time.Now().Add(tokenStatsTTL))
+		return value, nil
+	})
+}
@@ -0,0 +1,11 @@
+package token
+
+import "database/sql"
+
+type QueryTokenService struct {
+	DB *sql.DB
+}
+
+func NewQueryTokenService(db *sql.DB) *QueryTokenService {
+	return &QueryTokenService{DB: db}
+}
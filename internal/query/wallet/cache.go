@@ -0,0 +1,19 @@
+package wallet
+
+import (
+	"dex-ingest-sol/internal/pkg/db"
+	"time"
+)
+
+// 缓存 TTL 设置
+const (
+	walletPositionsTTL      = 5 * time.Second  // 持仓随每笔交易变化
+	walletPositionsEmptyTTL = 10 * time.Second // 空结果 TTL，防止穿透
+	tokenPriceTTL           = 5 * time.Second  // token 最新成交价，没有价格的 token 同样缓存
+)
+
+// 缓存实例
+var (
+	walletPositionsCache = db.NewLockCache(500)
+	tokenPriceCache      = db.NewLockCache(2000)
+)
@@ -0,0 +1,143 @@
+package wallet
+
+import (
+	"context"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/logger"
+	"dex-ingest-sol/internal/pkg/utils"
+	"dex-ingest-sol/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+func (s *QueryWalletService) QueryWalletPositions(ctx context.Context, req *pb.WalletPositionsReq) (_ *pb.WalletPositionsResp, err error) {
+	const (
+		ErrCodeBase        = 61600
+		ErrCodePanic       = ErrCodeBase + 32
+		ErrCodeInvalidArg  = ErrCodeBase + 1
+		ErrCodeQueryFailed = ErrCodeBase + 2
+		ErrCodeScanFailed  = ErrCodeBase + 3
+		ErrCodeRowsIter    = ErrCodeBase + 4
+	)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("panic in QueryWalletPositions: %v", r)
+			err = status.Errorf(codes.Internal, "[%d] server panic", ErrCodePanic)
+		}
+	}()
+
+	const maxResultLimit = 1000 // 防御性限制，避免钱包交易过太多 token
+
+	owner := strings.TrimSpace(req.OwnerAddress)
+	if owner == "" {
+		return nil, status.Errorf(codes.Internal, "[%d] owner_address cannot be empty", ErrCodeInvalidArg)
+	}
+
+	query := `
+		SELECT token, decimals, position, avg_cost_usd, bought_amount, sold_amount,
+		       bought_usd, sold_usd, realized_pnl, buy_count, sell_count, last_trade_time
+		FROM wallet_position
+		WHERE owner = ?
+		LIMIT ?`
+
+	resp, localErr := walletPositionsCache.Do(owner, false, func(e *db.Entry, onlyReady bool) (resp any, localErr error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Errorf("panic in QueryWalletPositions cache func: %v", r)
+				localErr = status.Errorf(codes.Internal, "[%d] server panic", ErrCodePanic)
+				resp = nil
+			}
+		}()
+
+		if !e.IsExpired() {
+			if cached, ok := e.Result.([]*pb.WalletPosition); ok {
+				return cached, nil
+			}
+		}
+		if onlyReady {
+			return nil, status.Errorf(codes.NotFound, "cache not ready")
+		}
+
+		rows, queryErr := s.DB.QueryContext(ctx, query, owner, maxResultLimit)
+		if queryErr != nil {
+			logger.Errorf("QueryWalletPositions query failed: %v", queryErr)
+			return nil, status.Errorf(codes.Internal, "[%d] query error", ErrCodeQueryFailed)
+		}
+		defer rows.Close()
+
+		positions := make([]*pb.WalletPosition, 0, 10)
+		for rows.Next() {
+			p := &pb.WalletPosition{}
+			if scanErr := rows.Scan(
+				&p.TokenAddress, &p.Decimals, &p.Position, &p.AvgCostUsd, &p.BoughtAmount, &p.SoldAmount,
+				&p.BoughtUsd, &p.SoldUsd, &p.RealizedPnl, &p.BuyCount, &p.SellCount, &p.LastTradeTime,
+			); scanErr != nil {
+				logger.Errorf("QueryWalletPositions row scan failed: %v", scanErr)
+				return nil, status.Errorf(codes.Internal, "[%d] data scan failed", ErrCodeScanFailed)
+			}
+			positions = append(positions, p)
+		}
+		if queryErr = rows.Err(); queryErr != nil {
+			logger.Errorf("QueryWalletPositions rows iteration error: %v", queryErr)
+			return nil, status.Errorf(codes.Internal, "[%d] rows iteration error", ErrCodeRowsIter)
+		}
+
+		sort.Slice(positions, func(i, j int) bool { return positions[i].LastTradeTime > positions[j].LastTradeTime })
+
+		e.Result = positions
+		if len(positions) == 0 {
+			e.SetValidAt(time.Now().Add(walletPositionsEmptyTTL))
+		} else {
+			e.SetValidAt(time.Now().Add(walletPositionsTTL))
+		}
+		return positions, nil
+	})
+
+	if positions, ok := resp.([]*pb.WalletPosition); ok {
+		return &pb.WalletPositionsResp{Positions: s.withUnrealizedPnl(ctx, positions)}, nil
+	}
+	return nil, localErr
+}
+
+// withUnrealizedPnl 用各 token 的最新成交价计算浮动盈亏，返回新的切片，不修改缓存中的对象。
+// 价格查询失败时只记录日志，返回不带浮动盈亏的持仓。
+func (s *QueryWalletService) withUnrealizedPnl(ctx context.Context, positions []*pb.WalletPosition) []*pb.WalletPosition {
+	if len(positions) == 0 {
+		return positions
+	}
+
+	tokens := make([]string, 0, len(positions))
+	for _, p := range positions {
+		tokens = append(tokens, p.TokenAddress)
+	}
+	prices, err := s.loadTokenPrices(ctx, tokens)
+	if err != nil {
+		logger.Errorf("load token prices failed: %v", err)
+		prices = nil
+	}
+
+	result := make([]*pb.WalletPosition, 0, len(positions))
+	for _, p := range positions {
+		withPrice := proto.Clone(p).(*pb.WalletPosition)
+		withPrice.TokenAddress = utils.DecodeTokenAddress(p.TokenAddress)
+		if price, ok := prices[p.TokenAddress]; ok {
+			amount, _ := new(big.Float).SetString(p.Position)
+			var holding float64
+			if amount != nil {
+				holding, _ = amount.Float64()
+			}
+			holding /= utils.Pow10(p.Decimals)
+			unrealized := holding * (price - p.AvgCostUsd)
+			withPrice.PriceUsd = &price
+			withPrice.UnrealizedPnl = &unrealized
+		}
+		result = append(result, withPrice)
+	}
+	return result
+}
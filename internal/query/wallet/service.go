@@ -0,0 +1,11 @@
+package wallet
+
+import "database/sql"
+
+type QueryWalletService struct {
+	DB *sql.DB
+}
+
+func NewQueryWalletService(db *sql.DB) *QueryWalletService {
+	return &QueryWalletService{DB: db}
+}
@@ -0,0 +1,154 @@
+package wallet
+
+import (
+	"context"
+	"dex-ingest-sol/internal/pkg/db"
+	"strings"
+	"time"
+)
+
+const tokenPriceBatchSize = 200
+
+// loadTokenPrices 返回各 token（编码后地址）的最新成交价：取该 token 所有池子中最近一笔交易的 price_usd。
+// 先读缓存，未命中的 token 依次查询 pool 和 pool_state（没有价格的 token 也缓存，防止穿透）。
+func (s *QueryWalletService) loadTokenPrices(ctx context.Context, tokens []string) (map[string]float64, error) {
+	prices := make(map[string]float64, len(tokens))
+	missing := make([]string, 0, len(tokens))
+	seen := make(map[string]struct{}, len(tokens))
+	for _, token := range tokens {
+		if _, ok := seen[token]; ok {
+			continue
+		}
+		seen[token] = struct{}{}
+
+		found := false
+		tokenPriceCache.DoRead(token, func(e *db.Entry) {
+			if cached, ok := e.Result.(float64); ok {
+				if cached > 0 {
+					prices[token] = cached
+				}
+				found = true
+			}
+		})
+		if !found {
+			missing = append(missing, token)
+		}
+	}
+
+	for i := 0; i < len(missing); i += tokenPriceBatchSize {
+		batch := missing[i:min(i+tokenPriceBatchSize, len(missing))]
+
+		poolTokens, err := s.queryPoolsOfTokens(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		latest, err := s.queryLatestPrices(ctx, poolTokens)
+		if err != nil {
+			return nil, err
+		}
+		for _, token := range batch {
+			price := latest[token]
+			if price > 0 {
+				prices[token] = price
+			}
+			setTokenPriceCache(token, price)
+		}
+	}
+	return prices, nil
+}
+
+// queryPoolsOfTokens 查询 token 作为 base 的全部池子，返回 池子地址 → token
+func (s *QueryWalletService) queryPoolsOfTokens(ctx context.Context, tokens []string) (map[string]string, error) {
+	placeholders := strings.Repeat("?,", len(tokens))
+	query := "SELECT pool_address, token_address FROM pool WHERE token_address IN (" + placeholders[:len(placeholders)-1] + ")"
+
+	args := make([]any, len(tokens))
+	for i, token := range tokens {
+		args[i] = token
+	}
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	poolTokens := make(map[string]string)
+	for rows.Next() {
+		var pool, token string
+		if err := rows.Scan(&pool, &token); err != nil {
+			return nil, err
+		}
+		poolTokens[pool] = token
+	}
+	return poolTokens, rows.Err()
+}
+
+// queryLatestPrices 读取池子的最新状态，每个 token 取 last_trade_time 最大的池子的价格
+func (s *QueryWalletService) queryLatestPrices(ctx context.Context, poolTokens map[string]string) (map[string]float64, error) {
+	type tokenPrice struct {
+		price     float64
+		tradeTime int32
+	}
+	latest := make(map[string]tokenPrice)
+
+	pools := make([]string, 0, len(poolTokens))
+	for pool := range poolTokens {
+		pools = append(pools, pool)
+	}
+	for i := 0; i < len(pools); i += tokenPriceBatchSize {
+		batch := pools[i:min(i+tokenPriceBatchSize, len(pools))]
+
+		placeholders := strings.Repeat("?,", len(batch))
+		query := "SELECT pool_address, price_usd, last_trade_time FROM pool_state WHERE pool_address IN (" + placeholders[:len(placeholders)-1] + ")"
+
+		args := make([]any, len(batch))
+		for j, pool := range batch {
+			args[j] = pool
+		}
+
+		err := func() error {
+			rows, err := s.DB.QueryContext(ctx, query, args...)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+
+			for rows.Next() {
+				var (
+					pool string
+					cur  tokenPrice
+				)
+				if err := rows.Scan(&pool, &cur.price, &cur.tradeTime); err != nil {
+					return err
+				}
+				if cur.price <= 0 {
+					continue
+				}
+				token := poolTokens[pool]
+				if prev, ok := latest[token]; !ok || cur.tradeTime > prev.tradeTime {
+					latest[token] = cur
+				}
+			}
+			return rows.Err()
+		}()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := make(map[string]float64, len(latest))
+	for token, p := range latest {
+		result[token] = p.price
+	}
+	return result, nil
+}
+
+// setTokenPriceCache 写入缓存，price 为 0 表示该 token 还没有成交价
+func setTokenPriceCache(token string, price float64) {
+	tokenPriceCache.Do(token, true, func(e *db.Entry, onlyReady bool) (resp any, localErr error) {
+		e.Result = price
+		e.SetValidAt(time.Now().Add(tokenPriceTTL))
+		return price, nil
+	})
+}
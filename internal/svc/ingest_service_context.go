@@ -3,20 +3,33 @@ package svc
 import (
 	"database/sql"
 	"dex-ingest-sol/internal/config"
+	"dex-ingest-sol/internal/pkg/db"
+	"dex-ingest-sol/internal/pkg/dlq"
+	"fmt"
 
 	"github.com/redis/go-redis/v9"
 )
 
 type IngestServiceContext struct {
-	Cfg   *config.IngestConfig
-	DB    *sql.DB
-	Redis *redis.Client
+	Cfg        *config.IngestConfig
+	DB         *sql.DB
+	Dialect    db.DBType
+	Redis      redis.UniversalClient
+	DeadLetter dlq.Writer
 }
 
 func NewIngestServiceContext(c *config.IngestConfig) *IngestServiceContext {
+	deadLetter, err := dlq.NewWriter(&c.DeadLetter, c.KafkaConsumer.Brokers)
+	if err != nil {
+		panic(fmt.Sprintf("failed to init dead letter: %v", err))
+	}
+
+	conn, dialect := MustInitStorage(c.Storage, c.Lindorm, c.Postgres)
 	return &IngestServiceContext{
-		Cfg:   c,
-		DB:    MustInitLindorm(c.Lindorm),
-		Redis: nil,
+		Cfg:        c,
+		DB:         conn,
+		Dialect:    dialect,
+		Redis:      MustInitRedis(&c.Redis),
+		DeadLetter: deadLetter,
 	}
 }
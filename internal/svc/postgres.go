@@ -0,0 +1,57 @@
+package svc
+
+import (
+	"database/sql"
+	"dex-ingest-sol/internal/config"
+	"dex-ingest-sol/internal/pkg/db"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// MustInitPostgres 初始化 PostgreSQL 连接，失败时 panic
+func MustInitPostgres(conf config.PostgresConf) *sql.DB {
+	sslMode := conf.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	port := conf.Port
+	if port == 0 {
+		port = 5432
+	}
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(conf.User, conf.Password),
+		Host:     fmt.Sprintf("%s:%d", conf.Host, port),
+		Path:     "/" + conf.Database,
+		RawQuery: fmt.Sprintf("sslmode=%s&connect_timeout=%d", sslMode, conf.ConnectTimeout),
+	}
+
+	conn, err := db.OpenPostgres(dsn.String())
+	if err != nil {
+		panic(fmt.Sprintf("failed to connect to PostgreSQL: %v", err))
+	}
+
+	conn.SetMaxOpenConns(conf.MaxOpenConns)
+	conn.SetMaxIdleConns(conf.MaxIdleConns)
+
+	if dur, err := time.ParseDuration(conf.ConnMaxIdleTime); err == nil {
+		conn.SetConnMaxIdleTime(dur)
+	} else {
+		panic(fmt.Sprintf("invalid conn_max_idle_time: %v", err))
+	}
+
+	return conn
+}
+
+// MustInitStorage 按 storage 配置初始化 Lindorm 或 PostgreSQL 连接，返回连接和 SQL 方言
+func MustInitStorage(storage string, lindorm config.LindormConf, postgres config.PostgresConf) (*sql.DB, db.DBType) {
+	dialect, err := db.ParseDBType(storage)
+	if err != nil {
+		panic(fmt.Sprintf("配置错误: %v", err))
+	}
+	if dialect == db.PG {
+		return MustInitPostgres(postgres), dialect
+	}
+	return MustInitLindorm(lindorm), dialect
+}
@@ -6,17 +6,19 @@ import (
 	"dex-ingest-sol/internal/pkg/utils"
 	"fmt"
 	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
+	"github.com/redis/go-redis/v9"
 )
 
 type QueryServiceContext struct {
 	Cfg         *config.QueryConfig
 	DB          *sql.DB
+	Redis       redis.UniversalClient // 热点池子缓存（可为 nil）
 	NacosClient naming_client.INamingClient
 }
 
 func NewQueryServiceContext(c *config.QueryConfig) *QueryServiceContext {
-	// 初始化 Lindorm 数据库
-	db := MustInitLindorm(c.Lindorm)
+	// 初始化数据库（Lindorm / PostgreSQL）
+	db, _ := MustInitStorage(c.Storage, c.Lindorm, c.Postgres)
 	if db == nil {
 		return nil
 	}
@@ -30,6 +32,7 @@ func NewQueryServiceContext(c *config.QueryConfig) *QueryServiceContext {
 	return &QueryServiceContext{
 		Cfg:         c,
 		DB:          db,
+		Redis:       MustInitRedis(&c.Redis),
 		NacosClient: nacosClient,
 	}
 }
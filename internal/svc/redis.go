@@ -0,0 +1,19 @@
+package svc
+
+import (
+	"dex-ingest-sol/internal/pkg/xredis"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MustInitRedis 初始化 Redis 连接，未配置地址时返回 nil，连接失败时 panic
+func MustInitRedis(conf *xredis.RedisConfig) redis.UniversalClient {
+	if len(conf.Addr) == 0 {
+		return nil
+	}
+	if err := xredis.SetupRedisFromConfigStruct(conf); err != nil {
+		panic(fmt.Sprintf("failed to connect to Redis: %v", err))
+	}
+	return xredis.GetClient()
+}
@@ -1179,28 +1179,1653 @@ func (x *HolderCountResp) GetCount() uint64 {
 	return 0
 }
 
+type BalanceHistoryReq struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AccountAddress *string                `protobuf:"bytes,1,opt,name=account_address,json=accountAddress,proto3,oneof" json:"account_address,omitempty"` // 按账户查询，与 owner_address + token_address 二选一
+	OwnerAddress   *string                `protobuf:"bytes,2,opt,name=owner_address,json=ownerAddress,proto3,oneof" json:"owner_address,omitempty"`
+	TokenAddress   *string                `protobuf:"bytes,3,opt,name=token_address,json=tokenAddress,proto3,oneof" json:"token_address,omitempty"`
+	EventId        *uint64                `protobuf:"varint,4,opt,name=event_id,json=eventId,proto3,oneof" json:"event_id,omitempty"` // 分页游标，查询 event_id 之前的数据（不含）
+	Limit          *uint32                `protobuf:"varint,5,opt,name=limit,proto3,oneof" json:"limit,omitempty"`                    // 限制返回条数，默认 50，最大 500
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *BalanceHistoryReq) Reset() {
+	*x = BalanceHistoryReq{}
+	mi := &file_ingest_query_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BalanceHistoryReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BalanceHistoryReq) ProtoMessage() {}
+
+func (x *BalanceHistoryReq) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BalanceHistoryReq.ProtoReflect.Descriptor instead.
+func (*BalanceHistoryReq) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *BalanceHistoryReq) GetAccountAddress() string {
+	if x != nil && x.AccountAddress != nil {
+		return *x.AccountAddress
+	}
+	return ""
+}
+
+func (x *BalanceHistoryReq) GetOwnerAddress() string {
+	if x != nil && x.OwnerAddress != nil {
+		return *x.OwnerAddress
+	}
+	return ""
+}
+
+func (x *BalanceHistoryReq) GetTokenAddress() string {
+	if x != nil && x.TokenAddress != nil {
+		return *x.TokenAddress
+	}
+	return ""
+}
+
+func (x *BalanceHistoryReq) GetEventId() uint64 {
+	if x != nil && x.EventId != nil {
+		return *x.EventId
+	}
+	return 0
+}
+
+func (x *BalanceHistoryReq) GetLimit() uint32 {
+	if x != nil && x.Limit != nil {
+		return *x.Limit
+	}
+	return 0
+}
+
+type BalanceChange struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AccountAddress string                 `protobuf:"bytes,1,opt,name=account_address,json=accountAddress,proto3" json:"account_address,omitempty"`
+	OwnerAddress   string                 `protobuf:"bytes,2,opt,name=owner_address,json=ownerAddress,proto3" json:"owner_address,omitempty"`
+	TokenAddress   string                 `protobuf:"bytes,3,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"`
+	EventId        uint64                 `protobuf:"varint,4,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	PreBalance     uint64                 `protobuf:"varint,5,opt,name=pre_balance,json=preBalance,proto3" json:"pre_balance,omitempty"`
+	PostBalance    uint64                 `protobuf:"varint,6,opt,name=post_balance,json=postBalance,proto3" json:"post_balance,omitempty"`
+	Delta          string                 `protobuf:"bytes,7,opt,name=delta,proto3" json:"delta,omitempty"` // post - pre，可能为负
+	BlockTime      uint32                 `protobuf:"varint,8,opt,name=block_time,json=blockTime,proto3" json:"block_time,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *BalanceChange) Reset() {
+	*x = BalanceChange{}
+	mi := &file_ingest_query_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BalanceChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BalanceChange) ProtoMessage() {}
+
+func (x *BalanceChange) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BalanceChange.ProtoReflect.Descriptor instead.
+func (*BalanceChange) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *BalanceChange) GetAccountAddress() string {
+	if x != nil {
+		return x.AccountAddress
+	}
+	return ""
+}
+
+func (x *BalanceChange) GetOwnerAddress() string {
+	if x != nil {
+		return x.OwnerAddress
+	}
+	return ""
+}
+
+func (x *BalanceChange) GetTokenAddress() string {
+	if x != nil {
+		return x.TokenAddress
+	}
+	return ""
+}
+
+func (x *BalanceChange) GetEventId() uint64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+func (x *BalanceChange) GetPreBalance() uint64 {
+	if x != nil {
+		return x.PreBalance
+	}
+	return 0
+}
+
+func (x *BalanceChange) GetPostBalance() uint64 {
+	if x != nil {
+		return x.PostBalance
+	}
+	return 0
+}
+
+func (x *BalanceChange) GetDelta() string {
+	if x != nil {
+		return x.Delta
+	}
+	return ""
+}
+
+func (x *BalanceChange) GetBlockTime() uint32 {
+	if x != nil {
+		return x.BlockTime
+	}
+	return 0
+}
+
+type BalanceHistoryResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Changes       []*BalanceChange       `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"` // 按 event_id 倒序
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BalanceHistoryResp) Reset() {
+	*x = BalanceHistoryResp{}
+	mi := &file_ingest_query_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BalanceHistoryResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BalanceHistoryResp) ProtoMessage() {}
+
+func (x *BalanceHistoryResp) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BalanceHistoryResp.ProtoReflect.Descriptor instead.
+func (*BalanceHistoryResp) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *BalanceHistoryResp) GetChanges() []*BalanceChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+type BalanceAtSlotReq struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AccountAddress *string                `protobuf:"bytes,1,opt,name=account_address,json=accountAddress,proto3,oneof" json:"account_address,omitempty"` // 按账户查询，与 owner_address + token_address 二选一
+	OwnerAddress   *string                `protobuf:"bytes,2,opt,name=owner_address,json=ownerAddress,proto3,oneof" json:"owner_address,omitempty"`
+	TokenAddress   *string                `protobuf:"bytes,3,opt,name=token_address,json=tokenAddress,proto3,oneof" json:"token_address,omitempty"`
+	Slot           uint64                 `protobuf:"varint,4,opt,name=slot,proto3" json:"slot,omitempty"` // 返回该 slot 处理完后的余额
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *BalanceAtSlotReq) Reset() {
+	*x = BalanceAtSlotReq{}
+	mi := &file_ingest_query_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BalanceAtSlotReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BalanceAtSlotReq) ProtoMessage() {}
+
+func (x *BalanceAtSlotReq) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BalanceAtSlotReq.ProtoReflect.Descriptor instead.
+func (*BalanceAtSlotReq) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *BalanceAtSlotReq) GetAccountAddress() string {
+	if x != nil && x.AccountAddress != nil {
+		return *x.AccountAddress
+	}
+	return ""
+}
+
+func (x *BalanceAtSlotReq) GetOwnerAddress() string {
+	if x != nil && x.OwnerAddress != nil {
+		return *x.OwnerAddress
+	}
+	return ""
+}
+
+func (x *BalanceAtSlotReq) GetTokenAddress() string {
+	if x != nil && x.TokenAddress != nil {
+		return *x.TokenAddress
+	}
+	return ""
+}
+
+func (x *BalanceAtSlotReq) GetSlot() uint64 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+type BalanceAtSlotResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Balance       *uint64                `protobuf:"varint,1,opt,name=balance,proto3,oneof" json:"balance,omitempty"`                        // 按 owner + token 查询时为各账户余额之和；该 slot 前没有变更记录时为空
+	LastEventId   uint64                 `protobuf:"varint,2,opt,name=last_event_id,json=lastEventId,proto3" json:"last_event_id,omitempty"` // 决定该余额的最后一次变更
+	BlockTime     uint32                 `protobuf:"varint,3,opt,name=block_time,json=blockTime,proto3" json:"block_time,omitempty"`         // 最后一次变更的区块时间
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BalanceAtSlotResp) Reset() {
+	*x = BalanceAtSlotResp{}
+	mi := &file_ingest_query_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BalanceAtSlotResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BalanceAtSlotResp) ProtoMessage() {}
+
+func (x *BalanceAtSlotResp) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BalanceAtSlotResp.ProtoReflect.Descriptor instead.
+func (*BalanceAtSlotResp) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *BalanceAtSlotResp) GetBalance() uint64 {
+	if x != nil && x.Balance != nil {
+		return *x.Balance
+	}
+	return 0
+}
+
+func (x *BalanceAtSlotResp) GetLastEventId() uint64 {
+	if x != nil {
+		return x.LastEventId
+	}
+	return 0
+}
+
+func (x *BalanceAtSlotResp) GetBlockTime() uint32 {
+	if x != nil {
+		return x.BlockTime
+	}
+	return 0
+}
+
 type PoolAddressesReq struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PoolAddresses []string               `protobuf:"bytes,1,rep,name=pool_addresses,json=poolAddresses,proto3" json:"pool_addresses,omitempty"`
+	PoolAddresses []string               `protobuf:"bytes,1,rep,name=pool_addresses,json=poolAddresses,proto3" json:"pool_addresses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PoolAddressesReq) Reset() {
+	*x = PoolAddressesReq{}
+	mi := &file_ingest_query_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PoolAddressesReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PoolAddressesReq) ProtoMessage() {}
+
+func (x *PoolAddressesReq) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PoolAddressesReq.ProtoReflect.Descriptor instead.
+func (*PoolAddressesReq) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *PoolAddressesReq) GetPoolAddresses() []string {
+	if x != nil {
+		return x.PoolAddresses
+	}
+	return nil
+}
+
+type PoolTokenReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseToken     string                 `protobuf:"bytes,1,opt,name=base_token,json=baseToken,proto3" json:"base_token,omitempty"`
+	QuoteToken    *string                `protobuf:"bytes,2,opt,name=quote_token,json=quoteToken,proto3,oneof" json:"quote_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PoolTokenReq) Reset() {
+	*x = PoolTokenReq{}
+	mi := &file_ingest_query_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PoolTokenReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PoolTokenReq) ProtoMessage() {}
+
+func (x *PoolTokenReq) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PoolTokenReq.ProtoReflect.Descriptor instead.
+func (*PoolTokenReq) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *PoolTokenReq) GetBaseToken() string {
+	if x != nil {
+		return x.BaseToken
+	}
+	return ""
+}
+
+func (x *PoolTokenReq) GetQuoteToken() string {
+	if x != nil && x.QuoteToken != nil {
+		return *x.QuoteToken
+	}
+	return ""
+}
+
+type Pool struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	PoolAddress  string                 `protobuf:"bytes,1,opt,name=pool_address,json=poolAddress,proto3" json:"pool_address,omitempty"`
+	Dex          uint32                 `protobuf:"varint,2,opt,name=dex,proto3" json:"dex,omitempty"`
+	TokenAddress string                 `protobuf:"bytes,3,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"`
+	QuoteAddress string                 `protobuf:"bytes,4,opt,name=quote_address,json=quoteAddress,proto3" json:"quote_address,omitempty"`
+	TokenAccount string                 `protobuf:"bytes,5,opt,name=token_account,json=tokenAccount,proto3" json:"token_account,omitempty"`
+	QuoteAccount string                 `protobuf:"bytes,6,opt,name=quote_account,json=quoteAccount,proto3" json:"quote_account,omitempty"`
+	CreateAt     uint32                 `protobuf:"varint,7,opt,name=create_at,json=createAt,proto3" json:"create_at,omitempty"`
+	UpdateAt     uint32                 `protobuf:"varint,8,opt,name=update_at,json=updateAt,proto3" json:"update_at,omitempty"`
+	// 以下为池子最新状态（来自 pool_state），池子还没有交易 / 流动性事件时为空
+	TokenReserve  *uint64  `protobuf:"varint,9,opt,name=token_reserve,json=tokenReserve,proto3,oneof" json:"token_reserve,omitempty"`       // 池子 base token 余额（原生单位）
+	QuoteReserve  *uint64  `protobuf:"varint,10,opt,name=quote_reserve,json=quoteReserve,proto3,oneof" json:"quote_reserve,omitempty"`      // 池子 quote token 余额（原生单位）
+	PriceUsd      *float64 `protobuf:"fixed64,11,opt,name=price_usd,json=priceUsd,proto3,oneof" json:"price_usd,omitempty"`                 // 最近一笔交易的 base token 单价（USD）
+	LiquidityUsd  *float64 `protobuf:"fixed64,12,opt,name=liquidity_usd,json=liquidityUsd,proto3,oneof" json:"liquidity_usd,omitempty"`     // 流动性估值（USD）
+	LastTradeTime *uint32  `protobuf:"varint,13,opt,name=last_trade_time,json=lastTradeTime,proto3,oneof" json:"last_trade_time,omitempty"` // 最近一笔交易的区块时间（秒级）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Pool) Reset() {
+	*x = Pool{}
+	mi := &file_ingest_query_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pool) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pool) ProtoMessage() {}
+
+func (x *Pool) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pool.ProtoReflect.Descriptor instead.
+func (*Pool) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *Pool) GetPoolAddress() string {
+	if x != nil {
+		return x.PoolAddress
+	}
+	return ""
+}
+
+func (x *Pool) GetDex() uint32 {
+	if x != nil {
+		return x.Dex
+	}
+	return 0
+}
+
+func (x *Pool) GetTokenAddress() string {
+	if x != nil {
+		return x.TokenAddress
+	}
+	return ""
+}
+
+func (x *Pool) GetQuoteAddress() string {
+	if x != nil {
+		return x.QuoteAddress
+	}
+	return ""
+}
+
+func (x *Pool) GetTokenAccount() string {
+	if x != nil {
+		return x.TokenAccount
+	}
+	return ""
+}
+
+func (x *Pool) GetQuoteAccount() string {
+	if x != nil {
+		return x.QuoteAccount
+	}
+	return ""
+}
+
+func (x *Pool) GetCreateAt() uint32 {
+	if x != nil {
+		return x.CreateAt
+	}
+	return 0
+}
+
+func (x *Pool) GetUpdateAt() uint32 {
+	if x != nil {
+		return x.UpdateAt
+	}
+	return 0
+}
+
+func (x *Pool) GetTokenReserve() uint64 {
+	if x != nil && x.TokenReserve != nil {
+		return *x.TokenReserve
+	}
+	return 0
+}
+
+func (x *Pool) GetQuoteReserve() uint64 {
+	if x != nil && x.QuoteReserve != nil {
+		return *x.QuoteReserve
+	}
+	return 0
+}
+
+func (x *Pool) GetPriceUsd() float64 {
+	if x != nil && x.PriceUsd != nil {
+		return *x.PriceUsd
+	}
+	return 0
+}
+
+func (x *Pool) GetLiquidityUsd() float64 {
+	if x != nil && x.LiquidityUsd != nil {
+		return *x.LiquidityUsd
+	}
+	return 0
+}
+
+func (x *Pool) GetLastTradeTime() uint32 {
+	if x != nil && x.LastTradeTime != nil {
+		return *x.LastTradeTime
+	}
+	return 0
+}
+
+type PoolResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PoolAddress   string                 `protobuf:"bytes,1,opt,name=pool_address,json=poolAddress,proto3" json:"pool_address,omitempty"`
+	Pools         []*Pool                `protobuf:"bytes,2,rep,name=pools,proto3" json:"pools,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PoolResult) Reset() {
+	*x = PoolResult{}
+	mi := &file_ingest_query_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PoolResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PoolResult) ProtoMessage() {}
+
+func (x *PoolResult) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PoolResult.ProtoReflect.Descriptor instead.
+func (*PoolResult) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *PoolResult) GetPoolAddress() string {
+	if x != nil {
+		return x.PoolAddress
+	}
+	return ""
+}
+
+func (x *PoolResult) GetPools() []*Pool {
+	if x != nil {
+		return x.Pools
+	}
+	return nil
+}
+
+type PoolListResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*PoolResult          `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PoolListResp) Reset() {
+	*x = PoolListResp{}
+	mi := &file_ingest_query_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PoolListResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PoolListResp) ProtoMessage() {}
+
+func (x *PoolListResp) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PoolListResp.ProtoReflect.Descriptor instead.
+func (*PoolListResp) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *PoolListResp) GetResults() []*PoolResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type PoolResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pools         []*Pool                `protobuf:"bytes,1,rep,name=pools,proto3" json:"pools,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PoolResp) Reset() {
+	*x = PoolResp{}
+	mi := &file_ingest_query_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PoolResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PoolResp) ProtoMessage() {}
+
+func (x *PoolResp) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PoolResp.ProtoReflect.Descriptor instead.
+func (*PoolResp) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *PoolResp) GetPools() []*Pool {
+	if x != nil {
+		return x.Pools
+	}
+	return nil
+}
+
+type TokenAddressesReq struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TokenAddresses []string               `protobuf:"bytes,1,rep,name=token_addresses,json=tokenAddresses,proto3" json:"token_addresses,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TokenAddressesReq) Reset() {
+	*x = TokenAddressesReq{}
+	mi := &file_ingest_query_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenAddressesReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenAddressesReq) ProtoMessage() {}
+
+func (x *TokenAddressesReq) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenAddressesReq.ProtoReflect.Descriptor instead.
+func (*TokenAddressesReq) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *TokenAddressesReq) GetTokenAddresses() []string {
+	if x != nil {
+		return x.TokenAddresses
+	}
+	return nil
+}
+
+type Token struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TokenAddress  string                 `protobuf:"bytes,1,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"`
+	Decimals      uint32                 `protobuf:"varint,2,opt,name=decimals,proto3" json:"decimals,omitempty"`
+	Source        uint32                 `protobuf:"varint,3,opt,name=source,proto3" json:"source,omitempty"`                              // 来源 DEX 编号（0 表示仅由流动性事件补全 decimals）
+	TotalSupply   uint64                 `protobuf:"varint,4,opt,name=total_supply,json=totalSupply,proto3" json:"total_supply,omitempty"` // 初始总发行量（原生单位）
+	Name          string                 `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
+	Symbol        string                 `protobuf:"bytes,6,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Uri           string                 `protobuf:"bytes,7,opt,name=uri,proto3" json:"uri,omitempty"`
+	Creator       string                 `protobuf:"bytes,8,opt,name=creator,proto3" json:"creator,omitempty"`
+	CreateAt      uint32                 `protobuf:"varint,9,opt,name=create_at,json=createAt,proto3" json:"create_at,omitempty"`
+	UpdateAt      uint32                 `protobuf:"varint,10,opt,name=update_at,json=updateAt,proto3" json:"update_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Token) Reset() {
+	*x = Token{}
+	mi := &file_ingest_query_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Token) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Token) ProtoMessage() {}
+
+func (x *Token) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Token.ProtoReflect.Descriptor instead.
+func (*Token) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *Token) GetTokenAddress() string {
+	if x != nil {
+		return x.TokenAddress
+	}
+	return ""
+}
+
+func (x *Token) GetDecimals() uint32 {
+	if x != nil {
+		return x.Decimals
+	}
+	return 0
+}
+
+func (x *Token) GetSource() uint32 {
+	if x != nil {
+		return x.Source
+	}
+	return 0
+}
+
+func (x *Token) GetTotalSupply() uint64 {
+	if x != nil {
+		return x.TotalSupply
+	}
+	return 0
+}
+
+func (x *Token) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Token) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Token) GetUri() string {
+	if x != nil {
+		return x.Uri
+	}
+	return ""
+}
+
+func (x *Token) GetCreator() string {
+	if x != nil {
+		return x.Creator
+	}
+	return ""
+}
+
+func (x *Token) GetCreateAt() uint32 {
+	if x != nil {
+		return x.CreateAt
+	}
+	return 0
+}
+
+func (x *Token) GetUpdateAt() uint32 {
+	if x != nil {
+		return x.UpdateAt
+	}
+	return 0
+}
+
+type TokenResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TokenAddress  string                 `protobuf:"bytes,1,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"`
+	Token         *Token                 `protobuf:"bytes,2,opt,name=token,proto3,oneof" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokenResult) Reset() {
+	*x = TokenResult{}
+	mi := &file_ingest_query_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenResult) ProtoMessage() {}
+
+func (x *TokenResult) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenResult.ProtoReflect.Descriptor instead.
+func (*TokenResult) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *TokenResult) GetTokenAddress() string {
+	if x != nil {
+		return x.TokenAddress
+	}
+	return ""
+}
+
+func (x *TokenResult) GetToken() *Token {
+	if x != nil {
+		return x.Token
+	}
+	return nil
+}
+
+type TokenListResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*TokenResult         `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokenListResp) Reset() {
+	*x = TokenListResp{}
+	mi := &file_ingest_query_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenListResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenListResp) ProtoMessage() {}
+
+func (x *TokenListResp) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenListResp.ProtoReflect.Descriptor instead.
+func (*TokenListResp) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *TokenListResp) GetResults() []*TokenResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type TokenStatsReq struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TokenAddresses []string               `protobuf:"bytes,1,rep,name=token_addresses,json=tokenAddresses,proto3" json:"token_addresses,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TokenStatsReq) Reset() {
+	*x = TokenStatsReq{}
+	mi := &file_ingest_query_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenStatsReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenStatsReq) ProtoMessage() {}
+
+func (x *TokenStatsReq) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenStatsReq.ProtoReflect.Descriptor instead.
+func (*TokenStatsReq) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *TokenStatsReq) GetTokenAddresses() []string {
+	if x != nil {
+		return x.TokenAddresses
+	}
+	return nil
+}
+
+type TokenStatsWindow struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Window        string                 `protobuf:"bytes,1,opt,name=window,proto3" json:"window,omitempty"` // 5m / 1h / 6h / 24h
+	VolumeUsd     float64                `protobuf:"fixed64,2,opt,name=volume_usd,json=volumeUsd,proto3" json:"volume_usd,omitempty"`
+	TradeCount    uint32                 `protobuf:"varint,3,opt,name=trade_count,json=tradeCount,proto3" json:"trade_count,omitempty"`
+	BuyCount      uint32                 `protobuf:"varint,4,opt,name=buy_count,json=buyCount,proto3" json:"buy_count,omitempty"`
+	SellCount     uint32                 `protobuf:"varint,5,opt,name=sell_count,json=sellCount,proto3" json:"sell_count,omitempty"`
+	UniqueTraders uint32                 `protobuf:"varint,6,opt,name=unique_traders,json=uniqueTraders,proto3" json:"unique_traders,omitempty"`
+	PriceOpen     float64                `protobuf:"fixed64,7,opt,name=price_open,json=priceOpen,proto3" json:"price_open,omitempty"`          // 窗口内第一笔交易价格（USD）
+	PriceClose    float64                `protobuf:"fixed64,8,opt,name=price_close,json=priceClose,proto3" json:"price_close,omitempty"`       // 窗口内最后一笔交易价格（USD）
+	PriceChange   float64                `protobuf:"fixed64,9,opt,name=price_change,json=priceChange,proto3" json:"price_change,omitempty"`    // (price_close - price_open) / price_open
+	TrackedSince  uint32                 `protobuf:"varint,10,opt,name=tracked_since,json=trackedSince,proto3" json:"tracked_since,omitempty"` // 统计开始时间，晚于窗口起点时该窗口数据不完整
+	UpdateAt      uint32                 `protobuf:"varint,11,opt,name=update_at,json=updateAt,proto3" json:"update_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokenStatsWindow) Reset() {
+	*x = TokenStatsWindow{}
+	mi := &file_ingest_query_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenStatsWindow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenStatsWindow) ProtoMessage() {}
+
+func (x *TokenStatsWindow) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenStatsWindow.ProtoReflect.Descriptor instead.
+func (*TokenStatsWindow) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *TokenStatsWindow) GetWindow() string {
+	if x != nil {
+		return x.Window
+	}
+	return ""
+}
+
+func (x *TokenStatsWindow) GetVolumeUsd() float64 {
+	if x != nil {
+		return x.VolumeUsd
+	}
+	return 0
+}
+
+func (x *TokenStatsWindow) GetTradeCount() uint32 {
+	if x != nil {
+		return x.TradeCount
+	}
+	return 0
+}
+
+func (x *TokenStatsWindow) GetBuyCount() uint32 {
+	if x != nil {
+		return x.BuyCount
+	}
+	return 0
+}
+
+func (x *TokenStatsWindow) GetSellCount() uint32 {
+	if x != nil {
+		return x.SellCount
+	}
+	return 0
+}
+
+func (x *TokenStatsWindow) GetUniqueTraders() uint32 {
+	if x != nil {
+		return x.UniqueTraders
+	}
+	return 0
+}
+
+func (x *TokenStatsWindow) GetPriceOpen() float64 {
+	if x != nil {
+		return x.PriceOpen
+	}
+	return 0
+}
+
+func (x *TokenStatsWindow) GetPriceClose() float64 {
+	if x != nil {
+		return x.PriceClose
+	}
+	return 0
+}
+
+func (x *TokenStatsWindow) GetPriceChange() float64 {
+	if x != nil {
+		return x.PriceChange
+	}
+	return 0
+}
+
+func (x *TokenStatsWindow) GetTrackedSince() uint32 {
+	if x != nil {
+		return x.TrackedSince
+	}
+	return 0
+}
+
+func (x *TokenStatsWindow) GetUpdateAt() uint32 {
+	if x != nil {
+		return x.UpdateAt
+	}
+	return 0
+}
+
+type TokenStatsResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TokenAddress  string                 `protobuf:"bytes,1,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"`
+	Windows       []*TokenStatsWindow    `protobuf:"bytes,2,rep,name=windows,proto3" json:"windows,omitempty"` // 按窗口从小到大排列，没有统计时为空
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokenStatsResult) Reset() {
+	*x = TokenStatsResult{}
+	mi := &file_ingest_query_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenStatsResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenStatsResult) ProtoMessage() {}
+
+func (x *TokenStatsResult) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenStatsResult.ProtoReflect.Descriptor instead.
+func (*TokenStatsResult) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *TokenStatsResult) GetTokenAddress() string {
+	if x != nil {
+		return x.TokenAddress
+	}
+	return ""
+}
+
+func (x *TokenStatsResult) GetWindows() []*TokenStatsWindow {
+	if x != nil {
+		return x.Windows
+	}
+	return nil
+}
+
+type TokenStatsResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*TokenStatsResult    `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"` // 按输入顺序原样返回
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokenStatsResp) Reset() {
+	*x = TokenStatsResp{}
+	mi := &file_ingest_query_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenStatsResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenStatsResp) ProtoMessage() {}
+
+func (x *TokenStatsResp) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenStatsResp.ProtoReflect.Descriptor instead.
+func (*TokenStatsResp) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *TokenStatsResp) GetResults() []*TokenStatsResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type CandlesReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PoolAddress   string                 `protobuf:"bytes,1,opt,name=pool_address,json=poolAddress,proto3" json:"pool_address,omitempty"`
+	Interval      string                 `protobuf:"bytes,2,opt,name=interval,proto3" json:"interval,omitempty"` // 1m / 5m / 15m / 1h / 4h / 1d
+	From          int64                  `protobuf:"varint,3,opt,name=from,proto3" json:"from,omitempty"`        // 开始时间（秒级，含），按周期向下对齐
+	To            int64                  `protobuf:"varint,4,opt,name=to,proto3" json:"to,omitempty"`            // 结束时间（秒级，含），0 表示当前时间
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CandlesReq) Reset() {
+	*x = CandlesReq{}
+	mi := &file_ingest_query_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CandlesReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CandlesReq) ProtoMessage() {}
+
+func (x *CandlesReq) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CandlesReq.ProtoReflect.Descriptor instead.
+func (*CandlesReq) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *CandlesReq) GetPoolAddress() string {
+	if x != nil {
+		return x.PoolAddress
+	}
+	return ""
+}
+
+func (x *CandlesReq) GetInterval() string {
+	if x != nil {
+		return x.Interval
+	}
+	return ""
+}
+
+func (x *CandlesReq) GetFrom() int64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *CandlesReq) GetTo() int64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+type Candle struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OpenTime      int64                  `protobuf:"varint,1,opt,name=open_time,json=openTime,proto3" json:"open_time,omitempty"` // 周期开始时间（秒级）
+	Open          float64                `protobuf:"fixed64,2,opt,name=open,proto3" json:"open,omitempty"`                        // price_usd
+	High          float64                `protobuf:"fixed64,3,opt,name=high,proto3" json:"high,omitempty"`
+	Low           float64                `protobuf:"fixed64,4,opt,name=low,proto3" json:"low,omitempty"`
+	Close         float64                `protobuf:"fixed64,5,opt,name=close,proto3" json:"close,omitempty"`
+	VolumeUsd     float64                `protobuf:"fixed64,6,opt,name=volume_usd,json=volumeUsd,proto3" json:"volume_usd,omitempty"`
+	BaseVolume    string                 `protobuf:"bytes,7,opt,name=base_volume,json=baseVolume,proto3" json:"base_volume,omitempty"`    // base token 成交量（原生单位），可能超出 uint64
+	QuoteVolume   string                 `protobuf:"bytes,8,opt,name=quote_volume,json=quoteVolume,proto3" json:"quote_volume,omitempty"` // quote token 成交量（原生单位）
+	BuyCount      uint32                 `protobuf:"varint,9,opt,name=buy_count,json=buyCount,proto3" json:"buy_count,omitempty"`
+	SellCount     uint32                 `protobuf:"varint,10,opt,name=sell_count,json=sellCount,proto3" json:"sell_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Candle) Reset() {
+	*x = Candle{}
+	mi := &file_ingest_query_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Candle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Candle) ProtoMessage() {}
+
+func (x *Candle) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Candle.ProtoReflect.Descriptor instead.
+func (*Candle) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *Candle) GetOpenTime() int64 {
+	if x != nil {
+		return x.OpenTime
+	}
+	return 0
+}
+
+func (x *Candle) GetOpen() float64 {
+	if x != nil {
+		return x.Open
+	}
+	return 0
+}
+
+func (x *Candle) GetHigh() float64 {
+	if x != nil {
+		return x.High
+	}
+	return 0
+}
+
+func (x *Candle) GetLow() float64 {
+	if x != nil {
+		return x.Low
+	}
+	return 0
+}
+
+func (x *Candle) GetClose() float64 {
+	if x != nil {
+		return x.Close
+	}
+	return 0
+}
+
+func (x *Candle) GetVolumeUsd() float64 {
+	if x != nil {
+		return x.VolumeUsd
+	}
+	return 0
+}
+
+func (x *Candle) GetBaseVolume() string {
+	if x != nil {
+		return x.BaseVolume
+	}
+	return ""
+}
+
+func (x *Candle) GetQuoteVolume() string {
+	if x != nil {
+		return x.QuoteVolume
+	}
+	return ""
+}
+
+func (x *Candle) GetBuyCount() uint32 {
+	if x != nil {
+		return x.BuyCount
+	}
+	return 0
+}
+
+func (x *Candle) GetSellCount() uint32 {
+	if x != nil {
+		return x.SellCount
+	}
+	return 0
+}
+
+type CandlesResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	QuoteToken    string                 `protobuf:"bytes,2,opt,name=quote_token,json=quoteToken,proto3" json:"quote_token,omitempty"`
+	Candles       []*Candle              `protobuf:"bytes,3,rep,name=candles,proto3" json:"candles,omitempty"` // 按 open_time 升序，没有成交的周期不返回
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CandlesResp) Reset() {
+	*x = CandlesResp{}
+	mi := &file_ingest_query_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CandlesResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CandlesResp) ProtoMessage() {}
+
+func (x *CandlesResp) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CandlesResp.ProtoReflect.Descriptor instead.
+func (*CandlesResp) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *CandlesResp) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *CandlesResp) GetQuoteToken() string {
+	if x != nil {
+		return x.QuoteToken
+	}
+	return ""
+}
+
+func (x *CandlesResp) GetCandles() []*Candle {
+	if x != nil {
+		return x.Candles
+	}
+	return nil
+}
+
+type QuotePriceReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`                                 // 报价币地址（WSOL / USDC / USDT）
+	Slot          *uint64                `protobuf:"varint,2,opt,name=slot,proto3,oneof" json:"slot,omitempty"`                            // 返回 <= slot 的最近价格
+	BlockTime     *int64                 `protobuf:"varint,3,opt,name=block_time,json=blockTime,proto3,oneof" json:"block_time,omitempty"` // 返回区块时间 <= block_time 的最近价格（秒级），slot 与 block_time 同时指定时以 slot 为准
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuotePriceReq) Reset() {
+	*x = QuotePriceReq{}
+	mi := &file_ingest_query_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuotePriceReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuotePriceReq) ProtoMessage() {}
+
+func (x *QuotePriceReq) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuotePriceReq.ProtoReflect.Descriptor instead.
+func (*QuotePriceReq) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *QuotePriceReq) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *QuotePriceReq) GetSlot() uint64 {
+	if x != nil && x.Slot != nil {
+		return *x.Slot
+	}
+	return 0
+}
+
+func (x *QuotePriceReq) GetBlockTime() int64 {
+	if x != nil && x.BlockTime != nil {
+		return *x.BlockTime
+	}
+	return 0
+}
+
+type QuotePrice struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Slot          uint64                 `protobuf:"varint,2,opt,name=slot,proto3" json:"slot,omitempty"` // 价格所属 slot
+	BlockTime     int64                  `protobuf:"varint,3,opt,name=block_time,json=blockTime,proto3" json:"block_time,omitempty"`
+	Price         float64                `protobuf:"fixed64,4,opt,name=price,proto3" json:"price,omitempty"` // 每 1 个 token 的 USD 价格（非最小单位）
+	Decimals      uint32                 `protobuf:"varint,5,opt,name=decimals,proto3" json:"decimals,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuotePrice) Reset() {
+	*x = QuotePrice{}
+	mi := &file_ingest_query_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuotePrice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuotePrice) ProtoMessage() {}
+
+func (x *QuotePrice) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuotePrice.ProtoReflect.Descriptor instead.
+func (*QuotePrice) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *QuotePrice) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *QuotePrice) GetSlot() uint64 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+func (x *QuotePrice) GetBlockTime() int64 {
+	if x != nil {
+		return x.BlockTime
+	}
+	return 0
+}
+
+func (x *QuotePrice) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *QuotePrice) GetDecimals() uint32 {
+	if x != nil {
+		return x.Decimals
+	}
+	return 0
+}
+
+type QuotePriceResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Price         *QuotePrice            `protobuf:"bytes,1,opt,name=price,proto3,oneof" json:"price,omitempty"` // 未指定 slot / block_time 时返回最新价格，找不到时为空
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PoolAddressesReq) Reset() {
-	*x = PoolAddressesReq{}
-	mi := &file_ingest_query_proto_msgTypes[19]
+func (x *QuotePriceResp) Reset() {
+	*x = QuotePriceResp{}
+	mi := &file_ingest_query_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PoolAddressesReq) String() string {
+func (x *QuotePriceResp) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PoolAddressesReq) ProtoMessage() {}
+func (*QuotePriceResp) ProtoMessage() {}
 
-func (x *PoolAddressesReq) ProtoReflect() protoreflect.Message {
-	mi := &file_ingest_query_proto_msgTypes[19]
+func (x *QuotePriceResp) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1211,41 +2836,40 @@ func (x *PoolAddressesReq) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PoolAddressesReq.ProtoReflect.Descriptor instead.
-func (*PoolAddressesReq) Descriptor() ([]byte, []int) {
-	return file_ingest_query_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use QuotePriceResp.ProtoReflect.Descriptor instead.
+func (*QuotePriceResp) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{43}
 }
 
-func (x *PoolAddressesReq) GetPoolAddresses() []string {
+func (x *QuotePriceResp) GetPrice() *QuotePrice {
 	if x != nil {
-		return x.PoolAddresses
+		return x.Price
 	}
 	return nil
 }
 
-type PoolTokenReq struct {
+type WalletPositionsReq struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	BaseToken     string                 `protobuf:"bytes,1,opt,name=base_token,json=baseToken,proto3" json:"base_token,omitempty"`
-	QuoteToken    *string                `protobuf:"bytes,2,opt,name=quote_token,json=quoteToken,proto3,oneof" json:"quote_token,omitempty"`
+	OwnerAddress  string                 `protobuf:"bytes,1,opt,name=owner_address,json=ownerAddress,proto3" json:"owner_address,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PoolTokenReq) Reset() {
-	*x = PoolTokenReq{}
-	mi := &file_ingest_query_proto_msgTypes[20]
+func (x *WalletPositionsReq) Reset() {
+	*x = WalletPositionsReq{}
+	mi := &file_ingest_query_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PoolTokenReq) String() string {
+func (x *WalletPositionsReq) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PoolTokenReq) ProtoMessage() {}
+func (*WalletPositionsReq) ProtoMessage() {}
 
-func (x *PoolTokenReq) ProtoReflect() protoreflect.Message {
-	mi := &file_ingest_query_proto_msgTypes[20]
+func (x *WalletPositionsReq) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1256,54 +2880,53 @@ func (x *PoolTokenReq) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PoolTokenReq.ProtoReflect.Descriptor instead.
-func (*PoolTokenReq) Descriptor() ([]byte, []int) {
-	return file_ingest_query_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use WalletPositionsReq.ProtoReflect.Descriptor instead.
+func (*WalletPositionsReq) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{44}
 }
 
-func (x *PoolTokenReq) GetBaseToken() string {
+func (x *WalletPositionsReq) GetOwnerAddress() string {
 	if x != nil {
-		return x.BaseToken
-	}
-	return ""
-}
-
-func (x *PoolTokenReq) GetQuoteToken() string {
-	if x != nil && x.QuoteToken != nil {
-		return *x.QuoteToken
+		return x.OwnerAddress
 	}
 	return ""
 }
 
-type Pool struct {
+type WalletPosition struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PoolAddress   string                 `protobuf:"bytes,1,opt,name=pool_address,json=poolAddress,proto3" json:"pool_address,omitempty"`
-	Dex           uint32                 `protobuf:"varint,2,opt,name=dex,proto3" json:"dex,omitempty"`
-	TokenAddress  string                 `protobuf:"bytes,3,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"`
-	QuoteAddress  string                 `protobuf:"bytes,4,opt,name=quote_address,json=quoteAddress,proto3" json:"quote_address,omitempty"`
-	TokenAccount  string                 `protobuf:"bytes,5,opt,name=token_account,json=tokenAccount,proto3" json:"token_account,omitempty"`
-	QuoteAccount  string                 `protobuf:"bytes,6,opt,name=quote_account,json=quoteAccount,proto3" json:"quote_account,omitempty"`
-	CreateAt      uint32                 `protobuf:"varint,7,opt,name=create_at,json=createAt,proto3" json:"create_at,omitempty"`
-	UpdateAt      uint32                 `protobuf:"varint,8,opt,name=update_at,json=updateAt,proto3" json:"update_at,omitempty"`
+	TokenAddress  string                 `protobuf:"bytes,1,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"`
+	Decimals      uint32                 `protobuf:"varint,2,opt,name=decimals,proto3" json:"decimals,omitempty"`
+	Position      string                 `protobuf:"bytes,3,opt,name=position,proto3" json:"position,omitempty"`                             // 通过买卖累计的当前持仓（原生单位），可能超出 uint64
+	AvgCostUsd    float64                `protobuf:"fixed64,4,opt,name=avg_cost_usd,json=avgCostUsd,proto3" json:"avg_cost_usd,omitempty"`   // 当前持仓的平均成本（每 1 个 token，USD）
+	BoughtAmount  string                 `protobuf:"bytes,5,opt,name=bought_amount,json=boughtAmount,proto3" json:"bought_amount,omitempty"` // 累计买入数量（原生单位）
+	SoldAmount    string                 `protobuf:"bytes,6,opt,name=sold_amount,json=soldAmount,proto3" json:"sold_amount,omitempty"`       // 累计卖出数量（原生单位）
+	BoughtUsd     float64                `protobuf:"fixed64,7,opt,name=bought_usd,json=boughtUsd,proto3" json:"bought_usd,omitempty"`
+	SoldUsd       float64                `protobuf:"fixed64,8,opt,name=sold_usd,json=soldUsd,proto3" json:"sold_usd,omitempty"`
+	RealizedPnl   float64                `protobuf:"fixed64,9,opt,name=realized_pnl,json=realizedPnl,proto3" json:"realized_pnl,omitempty"`              // 已实现盈亏（USD）
+	PriceUsd      *float64               `protobuf:"fixed64,10,opt,name=price_usd,json=priceUsd,proto3,oneof" json:"price_usd,omitempty"`                // token 最新成交价，没有成交记录时为空
+	UnrealizedPnl *float64               `protobuf:"fixed64,11,opt,name=unrealized_pnl,json=unrealizedPnl,proto3,oneof" json:"unrealized_pnl,omitempty"` // 持仓按最新成交价计算的浮动盈亏，没有价格时为空
+	BuyCount      uint32                 `protobuf:"varint,12,opt,name=buy_count,json=buyCount,proto3" json:"buy_count,omitempty"`
+	SellCount     uint32                 `protobuf:"varint,13,opt,name=sell_count,json=sellCount,proto3" json:"sell_count,omitempty"`
+	LastTradeTime uint32                 `protobuf:"varint,14,opt,name=last_trade_time,json=lastTradeTime,proto3" json:"last_trade_time,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Pool) Reset() {
-	*x = Pool{}
-	mi := &file_ingest_query_proto_msgTypes[21]
+func (x *WalletPosition) Reset() {
+	*x = WalletPosition{}
+	mi := &file_ingest_query_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Pool) String() string {
+func (x *WalletPosition) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Pool) ProtoMessage() {}
+func (*WalletPosition) ProtoMessage() {}
 
-func (x *Pool) ProtoReflect() protoreflect.Message {
-	mi := &file_ingest_query_proto_msgTypes[21]
+func (x *WalletPosition) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1314,90 +2937,131 @@ func (x *Pool) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Pool.ProtoReflect.Descriptor instead.
-func (*Pool) Descriptor() ([]byte, []int) {
-	return file_ingest_query_proto_rawDescGZIP(), []int{21}
+// Deprecated: Use WalletPosition.ProtoReflect.Descriptor instead.
+func (*WalletPosition) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{45}
 }
 
-func (x *Pool) GetPoolAddress() string {
+func (x *WalletPosition) GetTokenAddress() string {
 	if x != nil {
-		return x.PoolAddress
+		return x.TokenAddress
 	}
 	return ""
 }
 
-func (x *Pool) GetDex() uint32 {
+func (x *WalletPosition) GetDecimals() uint32 {
 	if x != nil {
-		return x.Dex
+		return x.Decimals
 	}
 	return 0
 }
 
-func (x *Pool) GetTokenAddress() string {
+func (x *WalletPosition) GetPosition() string {
 	if x != nil {
-		return x.TokenAddress
+		return x.Position
 	}
 	return ""
 }
 
-func (x *Pool) GetQuoteAddress() string {
+func (x *WalletPosition) GetAvgCostUsd() float64 {
 	if x != nil {
-		return x.QuoteAddress
+		return x.AvgCostUsd
 	}
-	return ""
+	return 0
 }
 
-func (x *Pool) GetTokenAccount() string {
+func (x *WalletPosition) GetBoughtAmount() string {
 	if x != nil {
-		return x.TokenAccount
+		return x.BoughtAmount
 	}
 	return ""
 }
 
-func (x *Pool) GetQuoteAccount() string {
+func (x *WalletPosition) GetSoldAmount() string {
 	if x != nil {
-		return x.QuoteAccount
+		return x.SoldAmount
 	}
 	return ""
 }
 
-func (x *Pool) GetCreateAt() uint32 {
+func (x *WalletPosition) GetBoughtUsd() float64 {
 	if x != nil {
-		return x.CreateAt
+		return x.BoughtUsd
 	}
 	return 0
 }
 
-func (x *Pool) GetUpdateAt() uint32 {
+func (x *WalletPosition) GetSoldUsd() float64 {
 	if x != nil {
-		return x.UpdateAt
+		return x.SoldUsd
 	}
 	return 0
 }
 
-type PoolResult struct {
+func (x *WalletPosition) GetRealizedPnl() float64 {
+	if x != nil {
+		return x.RealizedPnl
+	}
+	return 0
+}
+
+func (x *WalletPosition) GetPriceUsd() float64 {
+	if x != nil && x.PriceUsd != nil {
+		return *x.PriceUsd
+	}
+	return 0
+}
+
+func (x *WalletPosition) GetUnrealizedPnl() float64 {
+	if x != nil && x.UnrealizedPnl != nil {
+		return *x.UnrealizedPnl
+	}
+	return 0
+}
+
+func (x *WalletPosition) GetBuyCount() uint32 {
+	if x != nil {
+		return x.BuyCount
+	}
+	return 0
+}
+
+func (x *WalletPosition) GetSellCount() uint32 {
+	if x != nil {
+		return x.SellCount
+	}
+	return 0
+}
+
+func (x *WalletPosition) GetLastTradeTime() uint32 {
+	if x != nil {
+		return x.LastTradeTime
+	}
+	return 0
+}
+
+type WalletPositionsResp struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PoolAddress   string                 `protobuf:"bytes,1,opt,name=pool_address,json=poolAddress,proto3" json:"pool_address,omitempty"`
-	Pools         []*Pool                `protobuf:"bytes,2,rep,name=pools,proto3" json:"pools,omitempty"`
+	Positions     []*WalletPosition      `protobuf:"bytes,1,rep,name=positions,proto3" json:"positions,omitempty"` // 按最后交易时间倒序
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PoolResult) Reset() {
-	*x = PoolResult{}
-	mi := &file_ingest_query_proto_msgTypes[22]
+func (x *WalletPositionsResp) Reset() {
+	*x = WalletPositionsResp{}
+	mi := &file_ingest_query_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PoolResult) String() string {
+func (x *WalletPositionsResp) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PoolResult) ProtoMessage() {}
+func (*WalletPositionsResp) ProtoMessage() {}
 
-func (x *PoolResult) ProtoReflect() protoreflect.Message {
-	mi := &file_ingest_query_proto_msgTypes[22]
+func (x *WalletPositionsResp) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1408,47 +3072,162 @@ func (x *PoolResult) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PoolResult.ProtoReflect.Descriptor instead.
-func (*PoolResult) Descriptor() ([]byte, []int) {
-	return file_ingest_query_proto_rawDescGZIP(), []int{22}
+// Deprecated: Use WalletPositionsResp.ProtoReflect.Descriptor instead.
+func (*WalletPositionsResp) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{46}
 }
 
-func (x *PoolResult) GetPoolAddress() string {
+func (x *WalletPositionsResp) GetPositions() []*WalletPosition {
 	if x != nil {
-		return x.PoolAddress
+		return x.Positions
+	}
+	return nil
+}
+
+type IndexedSlotReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Router        string                 `protobuf:"bytes,1,opt,name=router,proto3" json:"router,omitempty"` // event / balance，留空返回全部
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IndexedSlotReq) Reset() {
+	*x = IndexedSlotReq{}
+	mi := &file_ingest_query_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndexedSlotReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndexedSlotReq) ProtoMessage() {}
+
+func (x *IndexedSlotReq) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndexedSlotReq.ProtoReflect.Descriptor instead.
+func (*IndexedSlotReq) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *IndexedSlotReq) GetRouter() string {
+	if x != nil {
+		return x.Router
 	}
 	return ""
 }
 
-func (x *PoolResult) GetPools() []*Pool {
+type PartitionCheckpoint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topic         string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	Partition     int32                  `protobuf:"varint,2,opt,name=partition,proto3" json:"partition,omitempty"`
+	KafkaOffset   int64                  `protobuf:"varint,3,opt,name=kafka_offset,json=kafkaOffset,proto3" json:"kafka_offset,omitempty"` // 最后写入的消息 offset
+	MaxSlot       uint64                 `protobuf:"varint,4,opt,name=max_slot,json=maxSlot,proto3" json:"max_slot,omitempty"`             // 已写入的最大 slot
+	UpdateAt      uint32                 `protobuf:"varint,5,opt,name=update_at,json=updateAt,proto3" json:"update_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PartitionCheckpoint) Reset() {
+	*x = PartitionCheckpoint{}
+	mi := &file_ingest_query_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PartitionCheckpoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PartitionCheckpoint) ProtoMessage() {}
+
+func (x *PartitionCheckpoint) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[48]
 	if x != nil {
-		return x.Pools
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-type PoolListResp struct {
+// Deprecated: Use PartitionCheckpoint.ProtoReflect.Descriptor instead.
+func (*PartitionCheckpoint) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *PartitionCheckpoint) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *PartitionCheckpoint) GetPartition() int32 {
+	if x != nil {
+		return x.Partition
+	}
+	return 0
+}
+
+func (x *PartitionCheckpoint) GetKafkaOffset() int64 {
+	if x != nil {
+		return x.KafkaOffset
+	}
+	return 0
+}
+
+func (x *PartitionCheckpoint) GetMaxSlot() uint64 {
+	if x != nil {
+		return x.MaxSlot
+	}
+	return 0
+}
+
+func (x *PartitionCheckpoint) GetUpdateAt() uint32 {
+	if x != nil {
+		return x.UpdateAt
+	}
+	return 0
+}
+
+type RouterWatermark struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Results       []*PoolResult          `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	Router        string                 `protobuf:"bytes,1,opt,name=router,proto3" json:"router,omitempty"`
+	IndexedSlot   uint64                 `protobuf:"varint,2,opt,name=indexed_slot,json=indexedSlot,proto3" json:"indexed_slot,omitempty"` // 所有分区 max_slot 的最小值，<= 该 slot 的数据已全部写入
+	Partitions    []*PartitionCheckpoint `protobuf:"bytes,3,rep,name=partitions,proto3" json:"partitions,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PoolListResp) Reset() {
-	*x = PoolListResp{}
-	mi := &file_ingest_query_proto_msgTypes[23]
+func (x *RouterWatermark) Reset() {
+	*x = RouterWatermark{}
+	mi := &file_ingest_query_proto_msgTypes[49]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PoolListResp) String() string {
+func (x *RouterWatermark) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PoolListResp) ProtoMessage() {}
+func (*RouterWatermark) ProtoMessage() {}
 
-func (x *PoolListResp) ProtoReflect() protoreflect.Message {
-	mi := &file_ingest_query_proto_msgTypes[23]
+func (x *RouterWatermark) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[49]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1459,40 +3238,54 @@ func (x *PoolListResp) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PoolListResp.ProtoReflect.Descriptor instead.
-func (*PoolListResp) Descriptor() ([]byte, []int) {
-	return file_ingest_query_proto_rawDescGZIP(), []int{23}
+// Deprecated: Use RouterWatermark.ProtoReflect.Descriptor instead.
+func (*RouterWatermark) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{49}
 }
 
-func (x *PoolListResp) GetResults() []*PoolResult {
+func (x *RouterWatermark) GetRouter() string {
 	if x != nil {
-		return x.Results
+		return x.Router
+	}
+	return ""
+}
+
+func (x *RouterWatermark) GetIndexedSlot() uint64 {
+	if x != nil {
+		return x.IndexedSlot
+	}
+	return 0
+}
+
+func (x *RouterWatermark) GetPartitions() []*PartitionCheckpoint {
+	if x != nil {
+		return x.Partitions
 	}
 	return nil
 }
 
-type PoolResp struct {
+type IndexedSlotResp struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Pools         []*Pool                `protobuf:"bytes,1,rep,name=pools,proto3" json:"pools,omitempty"`
+	Routers       []*RouterWatermark     `protobuf:"bytes,1,rep,name=routers,proto3" json:"routers,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PoolResp) Reset() {
-	*x = PoolResp{}
-	mi := &file_ingest_query_proto_msgTypes[24]
+func (x *IndexedSlotResp) Reset() {
+	*x = IndexedSlotResp{}
+	mi := &file_ingest_query_proto_msgTypes[50]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PoolResp) String() string {
+func (x *IndexedSlotResp) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PoolResp) ProtoMessage() {}
+func (*IndexedSlotResp) ProtoMessage() {}
 
-func (x *PoolResp) ProtoReflect() protoreflect.Message {
-	mi := &file_ingest_query_proto_msgTypes[24]
+func (x *IndexedSlotResp) ProtoReflect() protoreflect.Message {
+	mi := &file_ingest_query_proto_msgTypes[50]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1503,14 +3296,14 @@ func (x *PoolResp) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PoolResp.ProtoReflect.Descriptor instead.
-func (*PoolResp) Descriptor() ([]byte, []int) {
-	return file_ingest_query_proto_rawDescGZIP(), []int{24}
+// Deprecated: Use IndexedSlotResp.ProtoReflect.Descriptor instead.
+func (*IndexedSlotResp) Descriptor() ([]byte, []int) {
+	return file_ingest_query_proto_rawDescGZIP(), []int{50}
 }
 
-func (x *PoolResp) GetPools() []*Pool {
+func (x *IndexedSlotResp) GetRouters() []*RouterWatermark {
 	if x != nil {
-		return x.Pools
+		return x.Routers
 	}
 	return nil
 }
@@ -1615,7 +3408,46 @@ const file_ingest_query_proto_rawDesc = "" +
 	"\aholders\x18\x01 \x03(\v2\n" +
 	".pb.HolderR\aholders\"'\n" +
 	"\x0fHolderCountResp\x12\x14\n" +
-	"\x05count\x18\x01 \x01(\x04R\x05count\"9\n" +
+	"\x05count\x18\x01 \x01(\x04R\x05count\"\x9f\x02\n" +
+	"\x11BalanceHistoryReq\x12,\n" +
+	"\x0faccount_address\x18\x01 \x01(\tH\x00R\x0eaccountAddress\x88\x01\x01\x12(\n" +
+	"\rowner_address\x18\x02 \x01(\tH\x01R\fownerAddress\x88\x01\x01\x12(\n" +
+	"\rtoken_address\x18\x03 \x01(\tH\x02R\ftokenAddress\x88\x01\x01\x12\x1e\n" +
+	"\bevent_id\x18\x04 \x01(\x04H\x03R\aeventId\x88\x01\x01\x12\x19\n" +
+	"\x05limit\x18\x05 \x01(\rH\x04R\x05limit\x88\x01\x01B\x12\n" +
+	"\x10_account_addressB\x10\n" +
+	"\x0e_owner_addressB\x10\n" +
+	"\x0e_token_addressB\v\n" +
+	"\t_event_idB\b\n" +
+	"\x06_limit\"\x96\x02\n" +
+	"\rBalanceChange\x12'\n" +
+	"\x0faccount_address\x18\x01 \x01(\tR\x0eaccountAddress\x12#\n" +
+	"\rowner_address\x18\x02 \x01(\tR\fownerAddress\x12#\n" +
+	"\rtoken_address\x18\x03 \x01(\tR\ftokenAddress\x12\x19\n" +
+	"\bevent_id\x18\x04 \x01(\x04R\aeventId\x12\x1f\n" +
+	"\vpre_balance\x18\x05 \x01(\x04R\n" +
+	"preBalance\x12!\n" +
+	"\fpost_balance\x18\x06 \x01(\x04R\vpostBalance\x12\x14\n" +
+	"\x05delta\x18\a \x01(\tR\x05delta\x12\x1d\n" +
+	"\n" +
+	"block_time\x18\b \x01(\rR\tblockTime\"A\n" +
+	"\x12BalanceHistoryResp\x12+\n" +
+	"\achanges\x18\x01 \x03(\v2\x11.pb.BalanceChangeR\achanges\"\xe0\x01\n" +
+	"\x10BalanceAtSlotReq\x12,\n" +
+	"\x0faccount_address\x18\x01 \x01(\tH\x00R\x0eaccountAddress\x88\x01\x01\x12(\n" +
+	"\rowner_address\x18\x02 \x01(\tH\x01R\fownerAddress\x88\x01\x01\x12(\n" +
+	"\rtoken_address\x18\x03 \x01(\tH\x02R\ftokenAddress\x88\x01\x01\x12\x12\n" +
+	"\x04slot\x18\x04 \x01(\x04R\x04slotB\x12\n" +
+	"\x10_account_addressB\x10\n" +
+	"\x0e_owner_addressB\x10\n" +
+	"\x0e_token_address\"\x81\x01\n" +
+	"\x11BalanceAtSlotResp\x12\x1d\n" +
+	"\abalance\x18\x01 \x01(\x04H\x00R\abalance\x88\x01\x01\x12\"\n" +
+	"\rlast_event_id\x18\x02 \x01(\x04R\vlastEventId\x12\x1d\n" +
+	"\n" +
+	"block_time\x18\x03 \x01(\rR\tblockTimeB\n" +
+	"\n" +
+	"\b_balance\"9\n" +
 	"\x10PoolAddressesReq\x12%\n" +
 	"\x0epool_addresses\x18\x01 \x03(\tR\rpoolAddresses\"c\n" +
 	"\fPoolTokenReq\x12\x1d\n" +
@@ -1623,7 +3455,7 @@ const file_ingest_query_proto_rawDesc = "" +
 	"base_token\x18\x01 \x01(\tR\tbaseToken\x12$\n" +
 	"\vquote_token\x18\x02 \x01(\tH\x00R\n" +
 	"quoteToken\x88\x01\x01B\x0e\n" +
-	"\f_quote_token\"\x89\x02\n" +
+	"\f_quote_token\"\xae\x04\n" +
 	"\x04Pool\x12!\n" +
 	"\fpool_address\x18\x01 \x01(\tR\vpoolAddress\x12\x10\n" +
 	"\x03dex\x18\x02 \x01(\rR\x03dex\x12#\n" +
@@ -1632,7 +3464,19 @@ const file_ingest_query_proto_rawDesc = "" +
 	"\rtoken_account\x18\x05 \x01(\tR\ftokenAccount\x12#\n" +
 	"\rquote_account\x18\x06 \x01(\tR\fquoteAccount\x12\x1b\n" +
 	"\tcreate_at\x18\a \x01(\rR\bcreateAt\x12\x1b\n" +
-	"\tupdate_at\x18\b \x01(\rR\bupdateAt\"O\n" +
+	"\tupdate_at\x18\b \x01(\rR\bupdateAt\x12(\n" +
+	"\rtoken_reserve\x18\t \x01(\x04H\x00R\ftokenReserve\x88\x01\x01\x12(\n" +
+	"\rquote_reserve\x18\n" +
+	" \x01(\x04H\x01R\fquoteReserve\x88\x01\x01\x12 \n" +
+	"\tprice_usd\x18\v \x01(\x01H\x02R\bpriceUsd\x88\x01\x01\x12(\n" +
+	"\rliquidity_usd\x18\f \x01(\x01H\x03R\fliquidityUsd\x88\x01\x01\x12+\n" +
+	"\x0flast_trade_time\x18\r \x01(\rH\x04R\rlastTradeTime\x88\x01\x01B\x10\n" +
+	"\x0e_token_reserveB\x10\n" +
+	"\x0e_quote_reserveB\f\n" +
+	"\n" +
+	"_price_usdB\x10\n" +
+	"\x0e_liquidity_usdB\x12\n" +
+	"\x10_last_trade_time\"O\n" +
 	"\n" +
 	"PoolResult\x12!\n" +
 	"\fpool_address\x18\x01 \x01(\tR\vpoolAddress\x12\x1e\n" +
@@ -1640,11 +3484,144 @@ const file_ingest_query_proto_rawDesc = "" +
 	"\fPoolListResp\x12(\n" +
 	"\aresults\x18\x01 \x03(\v2\x0e.pb.PoolResultR\aresults\"*\n" +
 	"\bPoolResp\x12\x1e\n" +
-	"\x05pools\x18\x01 \x03(\v2\b.pb.PoolR\x05pools*<\n" +
+	"\x05pools\x18\x01 \x03(\v2\b.pb.PoolR\x05pools\"<\n" +
+	"\x11TokenAddressesReq\x12'\n" +
+	"\x0ftoken_addresses\x18\x01 \x03(\tR\x0etokenAddresses\"\x95\x02\n" +
+	"\x05Token\x12#\n" +
+	"\rtoken_address\x18\x01 \x01(\tR\ftokenAddress\x12\x1a\n" +
+	"\bdecimals\x18\x02 \x01(\rR\bdecimals\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\rR\x06source\x12!\n" +
+	"\ftotal_supply\x18\x04 \x01(\x04R\vtotalSupply\x12\x12\n" +
+	"\x04name\x18\x05 \x01(\tR\x04name\x12\x16\n" +
+	"\x06symbol\x18\x06 \x01(\tR\x06symbol\x12\x10\n" +
+	"\x03uri\x18\a \x01(\tR\x03uri\x12\x18\n" +
+	"\acreator\x18\b \x01(\tR\acreator\x12\x1b\n" +
+	"\tcreate_at\x18\t \x01(\rR\bcreateAt\x12\x1b\n" +
+	"\tupdate_at\x18\n" +
+	" \x01(\rR\bupdateAt\"b\n" +
+	"\vTokenResult\x12#\n" +
+	"\rtoken_address\x18\x01 \x01(\tR\ftokenAddress\x12$\n" +
+	"\x05token\x18\x02 \x01(\v2\t.pb.TokenH\x00R\x05token\x88\x01\x01B\b\n" +
+	"\x06_token\":\n" +
+	"\rTokenListResp\x12)\n" +
+	"\aresults\x18\x01 \x03(\v2\x0f.pb.TokenResultR\aresults\"8\n" +
+	"\rTokenStatsReq\x12'\n" +
+	"\x0ftoken_addresses\x18\x01 \x03(\tR\x0etokenAddresses\"\xf2\x02\n" +
+	"\x10TokenStatsWindow\x12\x16\n" +
+	"\x06window\x18\x01 \x01(\tR\x06window\x12\x1d\n" +
+	"\n" +
+	"volume_usd\x18\x02 \x01(\x01R\tvolumeUsd\x12\x1f\n" +
+	"\vtrade_count\x18\x03 \x01(\rR\n" +
+	"tradeCount\x12\x1b\n" +
+	"\tbuy_count\x18\x04 \x01(\rR\bbuyCount\x12\x1d\n" +
+	"\n" +
+	"sell_count\x18\x05 \x01(\rR\tsellCount\x12%\n" +
+	"\x0eunique_traders\x18\x06 \x01(\rR\runiqueTraders\x12\x1d\n" +
+	"\n" +
+	"price_open\x18\a \x01(\x01R\tpriceOpen\x12\x1f\n" +
+	"\vprice_close\x18\b \x01(\x01R\n" +
+	"priceClose\x12!\n" +
+	"\fprice_change\x18\t \x01(\x01R\vpriceChange\x12#\n" +
+	"\rtracked_since\x18\n" +
+	" \x01(\rR\ftrackedSince\x12\x1b\n" +
+	"\tupdate_at\x18\v \x01(\rR\bupdateAt\"g\n" +
+	"\x10TokenStatsResult\x12#\n" +
+	"\rtoken_address\x18\x01 \x01(\tR\ftokenAddress\x12.\n" +
+	"\awindows\x18\x02 \x03(\v2\x14.pb.TokenStatsWindowR\awindows\"@\n" +
+	"\x0eTokenStatsResp\x12.\n" +
+	"\aresults\x18\x01 \x03(\v2\x14.pb.TokenStatsResultR\aresults\"o\n" +
+	"\n" +
+	"CandlesReq\x12!\n" +
+	"\fpool_address\x18\x01 \x01(\tR\vpoolAddress\x12\x1a\n" +
+	"\binterval\x18\x02 \x01(\tR\binterval\x12\x12\n" +
+	"\x04from\x18\x03 \x01(\x03R\x04from\x12\x0e\n" +
+	"\x02to\x18\x04 \x01(\x03R\x02to\"\x94\x02\n" +
+	"\x06Candle\x12\x1b\n" +
+	"\topen_time\x18\x01 \x01(\x03R\bopenTime\x12\x12\n" +
+	"\x04open\x18\x02 \x01(\x01R\x04open\x12\x12\n" +
+	"\x04high\x18\x03 \x01(\x01R\x04high\x12\x10\n" +
+	"\x03low\x18\x04 \x01(\x01R\x03low\x12\x14\n" +
+	"\x05close\x18\x05 \x01(\x01R\x05close\x12\x1d\n" +
+	"\n" +
+	"volume_usd\x18\x06 \x01(\x01R\tvolumeUsd\x12\x1f\n" +
+	"\vbase_volume\x18\a \x01(\tR\n" +
+	"baseVolume\x12!\n" +
+	"\fquote_volume\x18\b \x01(\tR\vquoteVolume\x12\x1b\n" +
+	"\tbuy_count\x18\t \x01(\rR\bbuyCount\x12\x1d\n" +
+	"\n" +
+	"sell_count\x18\n" +
+	" \x01(\rR\tsellCount\"j\n" +
+	"\vCandlesResp\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1f\n" +
+	"\vquote_token\x18\x02 \x01(\tR\n" +
+	"quoteToken\x12$\n" +
+	"\acandles\x18\x03 \x03(\v2\n" +
+	".pb.CandleR\acandles\"z\n" +
+	"\rQuotePriceReq\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x17\n" +
+	"\x04slot\x18\x02 \x01(\x04H\x00R\x04slot\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"block_time\x18\x03 \x01(\x03H\x01R\tblockTime\x88\x01\x01B\a\n" +
+	"\x05_slotB\r\n" +
+	"\v_block_time\"\x87\x01\n" +
+	"\n" +
+	"QuotePrice\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x12\n" +
+	"\x04slot\x18\x02 \x01(\x04R\x04slot\x12\x1d\n" +
+	"\n" +
+	"block_time\x18\x03 \x01(\x03R\tblockTime\x12\x14\n" +
+	"\x05price\x18\x04 \x01(\x01R\x05price\x12\x1a\n" +
+	"\bdecimals\x18\x05 \x01(\rR\bdecimals\"E\n" +
+	"\x0eQuotePriceResp\x12)\n" +
+	"\x05price\x18\x01 \x01(\v2\x0e.pb.QuotePriceH\x00R\x05price\x88\x01\x01B\b\n" +
+	"\x06_price\"9\n" +
+	"\x12WalletPositionsReq\x12#\n" +
+	"\rowner_address\x18\x01 \x01(\tR\fownerAddress\"\x85\x04\n" +
+	"\x0eWalletPosition\x12#\n" +
+	"\rtoken_address\x18\x01 \x01(\tR\ftokenAddress\x12\x1a\n" +
+	"\bdecimals\x18\x02 \x01(\rR\bdecimals\x12\x1a\n" +
+	"\bposition\x18\x03 \x01(\tR\bposition\x12 \n" +
+	"\favg_cost_usd\x18\x04 \x01(\x01R\n" +
+	"avgCostUsd\x12#\n" +
+	"\rbought_amount\x18\x05 \x01(\tR\fboughtAmount\x12\x1f\n" +
+	"\vsold_amount\x18\x06 \x01(\tR\n" +
+	"soldAmount\x12\x1d\n" +
+	"\n" +
+	"bought_usd\x18\a \x01(\x01R\tboughtUsd\x12\x19\n" +
+	"\bsold_usd\x18\b \x01(\x01R\asoldUsd\x12!\n" +
+	"\frealized_pnl\x18\t \x01(\x01R\vrealizedPnl\x12 \n" +
+	"\tprice_usd\x18\n" +
+	" \x01(\x01H\x00R\bpriceUsd\x88\x01\x01\x12*\n" +
+	"\x0eunrealized_pnl\x18\v \x01(\x01H\x01R\runrealizedPnl\x88\x01\x01\x12\x1b\n" +
+	"\tbuy_count\x18\f \x01(\rR\bbuyCount\x12\x1d\n" +
+	"\n" +
+	"sell_count\x18\r \x01(\rR\tsellCount\x12&\n" +
+	"\x0flast_trade_time\x18\x0e \x01(\rR\rlastTradeTimeB\f\n" +
+	"\n" +
+	"_price_usdB\x11\n" +
+	"\x0f_unrealized_pnl\"G\n" +
+	"\x13WalletPositionsResp\x120\n" +
+	"\tpositions\x18\x01 \x03(\v2\x12.pb.WalletPositionR\tpositions\"(\n" +
+	"\x0eIndexedSlotReq\x12\x16\n" +
+	"\x06router\x18\x01 \x01(\tR\x06router\"\xa4\x01\n" +
+	"\x13PartitionCheckpoint\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x1c\n" +
+	"\tpartition\x18\x02 \x01(\x05R\tpartition\x12!\n" +
+	"\fkafka_offset\x18\x03 \x01(\x03R\vkafkaOffset\x12\x19\n" +
+	"\bmax_slot\x18\x04 \x01(\x04R\amaxSlot\x12\x1b\n" +
+	"\tupdate_at\x18\x05 \x01(\rR\bupdateAt\"\x85\x01\n" +
+	"\x0fRouterWatermark\x12\x16\n" +
+	"\x06router\x18\x01 \x01(\tR\x06router\x12!\n" +
+	"\findexed_slot\x18\x02 \x01(\x04R\vindexedSlot\x127\n" +
+	"\n" +
+	"partitions\x18\x03 \x03(\v2\x17.pb.PartitionCheckpointR\n" +
+	"partitions\"@\n" +
+	"\x0fIndexedSlotResp\x12-\n" +
+	"\arouters\x18\x01 \x03(\v2\x13.pb.RouterWatermarkR\arouters*<\n" +
 	"\x11TransferQueryType\x12\a\n" +
 	"\x03ALL\x10\x00\x12\x0f\n" +
 	"\vFROM_WALLET\x10\x01\x12\r\n" +
-	"\tTO_WALLET\x10\x022\xe4\x04\n" +
+	"\tTO_WALLET\x10\x022\xdc\b\n" +
 	"\x12IngestQueryService\x126\n" +
 	"\x10QueryEventsByIDs\x12\x0f.pb.EventIDsReq\x1a\x11.pb.EventListResp\x124\n" +
 	"\x11QueryEventsByUser\x12\x10.pb.UserEventReq\x1a\r.pb.EventResp\x124\n" +
@@ -1653,9 +3630,17 @@ const file_ingest_query_proto_rawDesc = "" +
 	"\x16QueryTopHoldersByToken\x12\x0f.pb.TokenTopReq\x1a\x12.pb.HolderListResp\x12<\n" +
 	"\x17QueryHolderCountByToken\x12\f.pb.TokenReq\x1a\x13.pb.HolderCountResp\x125\n" +
 	"\x14QueryBalancesByOwner\x12\f.pb.OwnerReq\x1a\x0f.pb.BalanceResp\x12?\n" +
-	"\x17QueryBalancesByAccounts\x12\x0f.pb.AccountsReq\x1a\x13.pb.BalanceListResp\x12?\n" +
+	"\x17QueryBalancesByAccounts\x12\x0f.pb.AccountsReq\x1a\x13.pb.BalanceListResp\x12D\n" +
+	"\x13QueryBalanceHistory\x12\x15.pb.BalanceHistoryReq\x1a\x16.pb.BalanceHistoryResp\x12A\n" +
+	"\x12QueryBalanceAtSlot\x12\x14.pb.BalanceAtSlotReq\x1a\x15.pb.BalanceAtSlotResp\x12?\n" +
 	"\x15QueryPoolsByAddresses\x12\x14.pb.PoolAddressesReq\x1a\x10.pb.PoolListResp\x123\n" +
-	"\x11QueryPoolsByToken\x12\x10.pb.PoolTokenReq\x1a\f.pb.PoolRespB\x16Z\x14dex-ingest-sol/pb;pbb\x06proto3"
+	"\x11QueryPoolsByToken\x12\x10.pb.PoolTokenReq\x1a\f.pb.PoolResp\x12B\n" +
+	"\x16QueryTokensByAddresses\x12\x15.pb.TokenAddressesReq\x1a\x11.pb.TokenListResp\x128\n" +
+	"\x0fQueryTokenStats\x12\x11.pb.TokenStatsReq\x1a\x12.pb.TokenStatsResp\x12/\n" +
+	"\fQueryCandles\x12\x0e.pb.CandlesReq\x1a\x0f.pb.CandlesResp\x128\n" +
+	"\x0fQueryQuotePrice\x12\x11.pb.QuotePriceReq\x1a\x12.pb.QuotePriceResp\x12G\n" +
+	"\x14QueryWalletPositions\x12\x16.pb.WalletPositionsReq\x1a\x17.pb.WalletPositionsResp\x12;\n" +
+	"\x10QueryIndexedSlot\x12\x12.pb.IndexedSlotReq\x1a\x13.pb.IndexedSlotRespB\x16Z\x14dex-ingest-sol/pb;pbb\x06proto3"
 
 var (
 	file_ingest_query_proto_rawDescOnce sync.Once
@@ -1670,7 +3655,7 @@ func file_ingest_query_proto_rawDescGZIP() []byte {
 }
 
 var file_ingest_query_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_ingest_query_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
+var file_ingest_query_proto_msgTypes = make([]protoimpl.MessageInfo, 51)
 var file_ingest_query_proto_goTypes = []any{
 	(TransferQueryType)(0),        // 0: pb.TransferQueryType
 	(*EventIDsReq)(nil),           // 1: pb.EventIDsReq
@@ -1692,12 +3677,38 @@ var file_ingest_query_proto_goTypes = []any{
 	(*Holder)(nil),                // 17: pb.Holder
 	(*HolderListResp)(nil),        // 18: pb.HolderListResp
 	(*HolderCountResp)(nil),       // 19: pb.HolderCountResp
-	(*PoolAddressesReq)(nil),      // 20: pb.PoolAddressesReq
-	(*PoolTokenReq)(nil),          // 21: pb.PoolTokenReq
-	(*Pool)(nil),                  // 22: pb.Pool
-	(*PoolResult)(nil),            // 23: pb.PoolResult
-	(*PoolListResp)(nil),          // 24: pb.PoolListResp
-	(*PoolResp)(nil),              // 25: pb.PoolResp
+	(*BalanceHistoryReq)(nil),     // 20: pb.BalanceHistoryReq
+	(*BalanceChange)(nil),         // 21: pb.BalanceChange
+	(*BalanceHistoryResp)(nil),    // 22: pb.BalanceHistoryResp
+	(*BalanceAtSlotReq)(nil),      // 23: pb.BalanceAtSlotReq
+	(*BalanceAtSlotResp)(nil),     // 24: pb.BalanceAtSlotResp
+	(*PoolAddressesReq)(nil),      // 25: pb.PoolAddressesReq
+	(*PoolTokenReq)(nil),          // 26: pb.PoolTokenReq
+	(*Pool)(nil),                  // 27: pb.Pool
+	(*PoolResult)(nil),            // 28: pb.PoolResult
+	(*PoolListResp)(nil),          // 29: pb.PoolListResp
+	(*PoolResp)(nil),              // 30: pb.PoolResp
+	(*TokenAddressesReq)(nil),     // 31: pb.TokenAddressesReq
+	(*Token)(nil),                 // 32: pb.Token
+	(*TokenResult)(nil),           // 33: pb.TokenResult
+	(*TokenListResp)(nil),         // 34: pb.TokenListResp
+	(*TokenStatsReq)(nil),         // 35: pb.TokenStatsReq
+	(*TokenStatsWindow)(nil),      // 36: pb.TokenStatsWindow
+	(*TokenStatsResult)(nil),      // 37: pb.TokenStatsResult
+	(*TokenStatsResp)(nil),        // 38: pb.TokenStatsResp
+	(*CandlesReq)(nil),            // 39: pb.CandlesReq
+	(*Candle)(nil),                // 40: pb.Candle
+	(*CandlesResp)(nil),           // 41: pb.CandlesResp
+	(*QuotePriceReq)(nil),         // 42: pb.QuotePriceReq
+	(*QuotePrice)(nil),            // 43: pb.QuotePrice
+	(*QuotePriceResp)(nil),        // 44: pb.QuotePriceResp
+	(*WalletPositionsReq)(nil),    // 45: pb.WalletPositionsReq
+	(*WalletPosition)(nil),        // 46: pb.WalletPosition
+	(*WalletPositionsResp)(nil),   // 47: pb.WalletPositionsResp
+	(*IndexedSlotReq)(nil),        // 48: pb.IndexedSlotReq
+	(*PartitionCheckpoint)(nil),   // 49: pb.PartitionCheckpoint
+	(*RouterWatermark)(nil),       // 50: pb.RouterWatermark
+	(*IndexedSlotResp)(nil),       // 51: pb.IndexedSlotResp
 }
 var file_ingest_query_proto_depIdxs = []int32{
 	6,  // 0: pb.ChainEventResult.event:type_name -> pb.ChainEvent
@@ -1708,34 +3719,60 @@ var file_ingest_query_proto_depIdxs = []int32{
 	14, // 5: pb.BalanceListResp.results:type_name -> pb.BalanceResult
 	13, // 6: pb.BalanceResp.balances:type_name -> pb.Balance
 	17, // 7: pb.HolderListResp.holders:type_name -> pb.Holder
-	22, // 8: pb.PoolResult.pools:type_name -> pb.Pool
-	23, // 9: pb.PoolListResp.results:type_name -> pb.PoolResult
-	22, // 10: pb.PoolResp.pools:type_name -> pb.Pool
-	1,  // 11: pb.IngestQueryService.QueryEventsByIDs:input_type -> pb.EventIDsReq
-	4,  // 12: pb.IngestQueryService.QueryEventsByUser:input_type -> pb.UserEventReq
-	5,  // 13: pb.IngestQueryService.QueryEventsByPool:input_type -> pb.PoolEventReq
-	8,  // 14: pb.IngestQueryService.QueryTransferEvents:input_type -> pb.TransferEventQueryReq
-	10, // 15: pb.IngestQueryService.QueryTopHoldersByToken:input_type -> pb.TokenTopReq
-	9,  // 16: pb.IngestQueryService.QueryHolderCountByToken:input_type -> pb.TokenReq
-	11, // 17: pb.IngestQueryService.QueryBalancesByOwner:input_type -> pb.OwnerReq
-	12, // 18: pb.IngestQueryService.QueryBalancesByAccounts:input_type -> pb.AccountsReq
-	20, // 19: pb.IngestQueryService.QueryPoolsByAddresses:input_type -> pb.PoolAddressesReq
-	21, // 20: pb.IngestQueryService.QueryPoolsByToken:input_type -> pb.PoolTokenReq
-	3,  // 21: pb.IngestQueryService.QueryEventsByIDs:output_type -> pb.EventListResp
-	7,  // 22: pb.IngestQueryService.QueryEventsByUser:output_type -> pb.EventResp
-	7,  // 23: pb.IngestQueryService.QueryEventsByPool:output_type -> pb.EventResp
-	7,  // 24: pb.IngestQueryService.QueryTransferEvents:output_type -> pb.EventResp
-	18, // 25: pb.IngestQueryService.QueryTopHoldersByToken:output_type -> pb.HolderListResp
-	19, // 26: pb.IngestQueryService.QueryHolderCountByToken:output_type -> pb.HolderCountResp
-	16, // 27: pb.IngestQueryService.QueryBalancesByOwner:output_type -> pb.BalanceResp
-	15, // 28: pb.IngestQueryService.QueryBalancesByAccounts:output_type -> pb.BalanceListResp
-	24, // 29: pb.IngestQueryService.QueryPoolsByAddresses:output_type -> pb.PoolListResp
-	25, // 30: pb.IngestQueryService.QueryPoolsByToken:output_type -> pb.PoolResp
-	21, // [21:31] is the sub-list for method output_type
-	11, // [11:21] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+	21, // 8: pb.BalanceHistoryResp.changes:type_name -> pb.BalanceChange
+	27, // 9: pb.PoolResult.pools:type_name -> pb.Pool
+	28, // 10: pb.PoolListResp.results:type_name -> pb.PoolResult
+	27, // 11: pb.PoolResp.pools:type_name -> pb.Pool
+	32, // 12: pb.TokenResult.token:type_name -> pb.Token
+	33, // 13: pb.TokenListResp.results:type_name -> pb.TokenResult
+	36, // 14: pb.TokenStatsResult.windows:type_name -> pb.TokenStatsWindow
+	37, // 15: pb.TokenStatsResp.results:type_name -> pb.TokenStatsResult
+	40, // 16: pb.CandlesResp.candles:type_name -> pb.Candle
+	43, // 17: pb.QuotePriceResp.price:type_name -> pb.QuotePrice
+	46, // 18: pb.WalletPositionsResp.positions:type_name -> pb.WalletPosition
+	49, // 19: pb.RouterWatermark.partitions:type_name -> pb.PartitionCheckpoint
+	50, // 20: pb.IndexedSlotResp.routers:type_name -> pb.RouterWatermark
+	1,  // 21: pb.IngestQueryService.QueryEventsByIDs:input_type -> pb.EventIDsReq
+	4,  // 22: pb.IngestQueryService.QueryEventsByUser:input_type -> pb.UserEventReq
+	5,  // 23: pb.IngestQueryService.QueryEventsByPool:input_type -> pb.PoolEventReq
+	8,  // 24: pb.IngestQueryService.QueryTransferEvents:input_type -> pb.TransferEventQueryReq
+	10, // 25: pb.IngestQueryService.QueryTopHoldersByToken:input_type -> pb.TokenTopReq
+	9,  // 26: pb.IngestQueryService.QueryHolderCountByToken:input_type -> pb.TokenReq
+	11, // 27: pb.IngestQueryService.QueryBalancesByOwner:input_type -> pb.OwnerReq
+	12, // 28: pb.IngestQueryService.QueryBalancesByAccounts:input_type -> pb.AccountsReq
+	20, // 29: pb.IngestQueryService.QueryBalanceHistory:input_type -> pb.BalanceHistoryReq
+	23, // 30: pb.IngestQueryService.QueryBalanceAtSlot:input_type -> pb.BalanceAtSlotReq
+	25, // 31: pb.IngestQueryService.QueryPoolsByAddresses:input_type -> pb.PoolAddressesReq
+	26, // 32: pb.IngestQueryService.QueryPoolsByToken:input_type -> pb.PoolTokenReq
+	31, // 33: pb.IngestQueryService.QueryTokensByAddresses:input_type -> pb.TokenAddressesReq
+	35, // 34: pb.IngestQueryService.QueryTokenStats:input_type -> pb.TokenStatsReq
+	39, // 35: pb.IngestQueryService.QueryCandles:input_type -> pb.CandlesReq
+	42, // 36: pb.IngestQueryService.QueryQuotePrice:input_type -> pb.QuotePriceReq
+	45, // 37: pb.IngestQueryService.QueryWalletPositions:input_type -> pb.WalletPositionsReq
+	48, // 38: pb.IngestQueryService.QueryIndexedSlot:input_type -> pb.IndexedSlotReq
+	3,  // 39: pb.IngestQueryService.QueryEventsByIDs:output_type -> pb.EventListResp
+	7,  // 40: pb.IngestQueryService.QueryEventsByUser:output_type -> pb.EventResp
+	7,  // 41: pb.IngestQueryService.QueryEventsByPool:output_type -> pb.EventResp
+	7,  // 42: pb.IngestQueryService.QueryTransferEvents:output_type -> pb.EventResp
+	18, // 43: pb.IngestQueryService.QueryTopHoldersByToken:output_type -> pb.HolderListResp
+	19, // 44: pb.IngestQueryService.QueryHolderCountByToken:output_type -> pb.HolderCountResp
+	16, // 45: pb.IngestQueryService.QueryBalancesByOwner:output_type -> pb.BalanceResp
+	15, // 46: pb.IngestQueryService.QueryBalancesByAccounts:output_type -> pb.BalanceListResp
+	22, // 47: pb.IngestQueryService.QueryBalanceHistory:output_type -> pb.BalanceHistoryResp
+	24, // 48: pb.IngestQueryService.QueryBalanceAtSlot:output_type -> pb.BalanceAtSlotResp
+	29, // 49: pb.IngestQueryService.QueryPoolsByAddresses:output_type -> pb.PoolListResp
+	30, // 50: pb.IngestQueryService.QueryPoolsByToken:output_type -> pb.PoolResp
+	34, // 51: pb.IngestQueryService.QueryTokensByAddresses:output_type -> pb.TokenListResp
+	38, // 52: pb.IngestQueryService.QueryTokenStats:output_type -> pb.TokenStatsResp
+	41, // 53: pb.IngestQueryService.QueryCandles:output_type -> pb.CandlesResp
+	44, // 54: pb.IngestQueryService.QueryQuotePrice:output_type -> pb.QuotePriceResp
+	47, // 55: pb.IngestQueryService.QueryWalletPositions:output_type -> pb.WalletPositionsResp
+	51, // 56: pb.IngestQueryService.QueryIndexedSlot:output_type -> pb.IndexedSlotResp
+	39, // [39:57] is the sub-list for method output_type
+	21, // [21:39] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
 }
 
 func init() { file_ingest_query_proto_init() }
@@ -1750,14 +3787,22 @@ func file_ingest_query_proto_init() {
 	file_ingest_query_proto_msgTypes[9].OneofWrappers = []any{}
 	file_ingest_query_proto_msgTypes[10].OneofWrappers = []any{}
 	file_ingest_query_proto_msgTypes[13].OneofWrappers = []any{}
-	file_ingest_query_proto_msgTypes[20].OneofWrappers = []any{}
+	file_ingest_query_proto_msgTypes[19].OneofWrappers = []any{}
+	file_ingest_query_proto_msgTypes[22].OneofWrappers = []any{}
+	file_ingest_query_proto_msgTypes[23].OneofWrappers = []any{}
+	file_ingest_query_proto_msgTypes[25].OneofWrappers = []any{}
+	file_ingest_query_proto_msgTypes[26].OneofWrappers = []any{}
+	file_ingest_query_proto_msgTypes[32].OneofWrappers = []any{}
+	file_ingest_query_proto_msgTypes[41].OneofWrappers = []any{}
+	file_ingest_query_proto_msgTypes[43].OneofWrappers = []any{}
+	file_ingest_query_proto_msgTypes[45].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ingest_query_proto_rawDesc), len(file_ingest_query_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   25,
+			NumMessages:   51,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -27,8 +27,16 @@ const (
 	IngestQueryService_QueryHolderCountByToken_FullMethodName = "/pb.IngestQueryService/QueryHolderCountByToken"
 	IngestQueryService_QueryBalancesByOwner_FullMethodName    = "/pb.IngestQueryService/QueryBalancesByOwner"
 	IngestQueryService_QueryBalancesByAccounts_FullMethodName = "/pb.IngestQueryService/QueryBalancesByAccounts"
+	IngestQueryService_QueryBalanceHistory_FullMethodName     = "/pb.IngestQueryService/QueryBalanceHistory"
+	IngestQueryService_QueryBalanceAtSlot_FullMethodName      = "/pb.IngestQueryService/QueryBalanceAtSlot"
 	IngestQueryService_QueryPoolsByAddresses_FullMethodName   = "/pb.IngestQueryService/QueryPoolsByAddresses"
 	IngestQueryService_QueryPoolsByToken_FullMethodName       = "/pb.IngestQueryService/QueryPoolsByToken"
+	IngestQueryService_QueryTokensByAddresses_FullMethodName  = "/pb.IngestQueryService/QueryTokensByAddresses"
+	IngestQueryService_QueryTokenStats_FullMethodName         = "/pb.IngestQueryService/QueryTokenStats"
+	IngestQueryService_QueryCandles_FullMethodName            = "/pb.IngestQueryService/QueryCandles"
+	IngestQueryService_QueryQuotePrice_FullMethodName         = "/pb.IngestQueryService/QueryQuotePrice"
+	IngestQueryService_QueryWalletPositions_FullMethodName    = "/pb.IngestQueryService/QueryWalletPositions"
+	IngestQueryService_QueryIndexedSlot_FullMethodName        = "/pb.IngestQueryService/QueryIndexedSlot"
 )
 
 // IngestQueryServiceClient is the client API for IngestQueryService service.
@@ -43,8 +51,16 @@ type IngestQueryServiceClient interface {
 	QueryHolderCountByToken(ctx context.Context, in *TokenReq, opts ...grpc.CallOption) (*HolderCountResp, error)
 	QueryBalancesByOwner(ctx context.Context, in *OwnerReq, opts ...grpc.CallOption) (*BalanceResp, error)
 	QueryBalancesByAccounts(ctx context.Context, in *AccountsReq, opts ...grpc.CallOption) (*BalanceListResp, error)
+	QueryBalanceHistory(ctx context.Context, in *BalanceHistoryReq, opts ...grpc.CallOption) (*BalanceHistoryResp, error)
+	QueryBalanceAtSlot(ctx context.Context, in *BalanceAtSlotReq, opts ...grpc.CallOption) (*BalanceAtSlotResp, error)
 	QueryPoolsByAddresses(ctx context.Context, in *PoolAddressesReq, opts ...grpc.CallOption) (*PoolListResp, error)
 	QueryPoolsByToken(ctx context.Context, in *PoolTokenReq, opts ...grpc.CallOption) (*PoolResp, error)
+	QueryTokensByAddresses(ctx context.Context, in *TokenAddressesReq, opts ...grpc.CallOption) (*TokenListResp, error)
+	QueryTokenStats(ctx context.Context, in *TokenStatsReq, opts ...grpc.CallOption) (*TokenStatsResp, error)
+	QueryCandles(ctx context.Context, in *CandlesReq, opts ...grpc.CallOption) (*CandlesResp, error)
+	QueryQuotePrice(ctx context.Context, in *QuotePriceReq, opts ...grpc.CallOption) (*QuotePriceResp, error)
+	QueryWalletPositions(ctx context.Context, in *WalletPositionsReq, opts ...grpc.CallOption) (*WalletPositionsResp, error)
+	QueryIndexedSlot(ctx context.Context, in *IndexedSlotReq, opts ...grpc.CallOption) (*IndexedSlotResp, error)
 }
 
 type ingestQueryServiceClient struct {
@@ -135,6 +151,26 @@ func (c *ingestQueryServiceClient) QueryBalancesByAccounts(ctx context.Context,
 	return out, nil
 }
 
+func (c *ingestQueryServiceClient) QueryBalanceHistory(ctx context.Context, in *BalanceHistoryReq, opts ...grpc.CallOption) (*BalanceHistoryResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BalanceHistoryResp)
+	err := c.cc.Invoke(ctx, IngestQueryService_QueryBalanceHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingestQueryServiceClient) QueryBalanceAtSlot(ctx context.Context, in *BalanceAtSlotReq, opts ...grpc.CallOption) (*BalanceAtSlotResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BalanceAtSlotResp)
+	err := c.cc.Invoke(ctx, IngestQueryService_QueryBalanceAtSlot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *ingestQueryServiceClient) QueryPoolsByAddresses(ctx context.Context, in *PoolAddressesReq, opts ...grpc.CallOption) (*PoolListResp, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PoolListResp)
@@ -155,6 +191,66 @@ func (c *ingestQueryServiceClient) QueryPoolsByToken(ctx context.Context, in *Po
 	return out, nil
 }
 
+func (c *ingestQueryServiceClient) QueryTokensByAddresses(ctx context.Context, in *TokenAddressesReq, opts ...grpc.CallOption) (*TokenListResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TokenListResp)
+	err := c.cc.Invoke(ctx, IngestQueryService_QueryTokensByAddresses_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingestQueryServiceClient) QueryTokenStats(ctx context.Context, in *TokenStatsReq, opts ...grpc.CallOption) (*TokenStatsResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TokenStatsResp)
+	err := c.cc.Invoke(ctx, IngestQueryService_QueryTokenStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingestQueryServiceClient) QueryCandles(ctx context.Context, in *CandlesReq, opts ...grpc.CallOption) (*CandlesResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CandlesResp)
+	err := c.cc.Invoke(ctx, IngestQueryService_QueryCandles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingestQueryServiceClient) QueryQuotePrice(ctx context.Context, in *QuotePriceReq, opts ...grpc.CallOption) (*QuotePriceResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QuotePriceResp)
+	err := c.cc.Invoke(ctx, IngestQueryService_QueryQuotePrice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingestQueryServiceClient) QueryWalletPositions(ctx context.Context, in *WalletPositionsReq, opts ...grpc.CallOption) (*WalletPositionsResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WalletPositionsResp)
+	err := c.cc.Invoke(ctx, IngestQueryService_QueryWalletPositions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingestQueryServiceClient) QueryIndexedSlot(ctx context.Context, in *IndexedSlotReq, opts ...grpc.CallOption) (*IndexedSlotResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IndexedSlotResp)
+	err := c.cc.Invoke(ctx, IngestQueryService_QueryIndexedSlot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // IngestQueryServiceServer is the server API for IngestQueryService service.
 // All implementations must embed UnimplementedIngestQueryServiceServer
 // for forward compatibility.
@@ -167,8 +263,16 @@ type IngestQueryServiceServer interface {
 	QueryHolderCountByToken(context.Context, *TokenReq) (*HolderCountResp, error)
 	QueryBalancesByOwner(context.Context, *OwnerReq) (*BalanceResp, error)
 	QueryBalancesByAccounts(context.Context, *AccountsReq) (*BalanceListResp, error)
+	QueryBalanceHistory(context.Context, *BalanceHistoryReq) (*BalanceHistoryResp, error)
+	QueryBalanceAtSlot(context.Context, *BalanceAtSlotReq) (*BalanceAtSlotResp, error)
 	QueryPoolsByAddresses(context.Context, *PoolAddressesReq) (*PoolListResp, error)
 	QueryPoolsByToken(context.Context, *PoolTokenReq) (*PoolResp, error)
+	QueryTokensByAddresses(context.Context, *TokenAddressesReq) (*TokenListResp, error)
+	QueryTokenStats(context.Context, *TokenStatsReq) (*TokenStatsResp, error)
+	QueryCandles(context.Context, *CandlesReq) (*CandlesResp, error)
+	QueryQuotePrice(context.Context, *QuotePriceReq) (*QuotePriceResp, error)
+	QueryWalletPositions(context.Context, *WalletPositionsReq) (*WalletPositionsResp, error)
+	QueryIndexedSlot(context.Context, *IndexedSlotReq) (*IndexedSlotResp, error)
 	mustEmbedUnimplementedIngestQueryServiceServer()
 }
 
@@ -203,12 +307,36 @@ func (UnimplementedIngestQueryServiceServer) QueryBalancesByOwner(context.Contex
 func (UnimplementedIngestQueryServiceServer) QueryBalancesByAccounts(context.Context, *AccountsReq) (*BalanceListResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method QueryBalancesByAccounts not implemented")
 }
+func (UnimplementedIngestQueryServiceServer) QueryBalanceHistory(context.Context, *BalanceHistoryReq) (*BalanceHistoryResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryBalanceHistory not implemented")
+}
+func (UnimplementedIngestQueryServiceServer) QueryBalanceAtSlot(context.Context, *BalanceAtSlotReq) (*BalanceAtSlotResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryBalanceAtSlot not implemented")
+}
 func (UnimplementedIngestQueryServiceServer) QueryPoolsByAddresses(context.Context, *PoolAddressesReq) (*PoolListResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method QueryPoolsByAddresses not implemented")
 }
 func (UnimplementedIngestQueryServiceServer) QueryPoolsByToken(context.Context, *PoolTokenReq) (*PoolResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method QueryPoolsByToken not implemented")
 }
+func (UnimplementedIngestQueryServiceServer) QueryTokensByAddresses(context.Context, *TokenAddressesReq) (*TokenListResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryTokensByAddresses not implemented")
+}
+func (UnimplementedIngestQueryServiceServer) QueryTokenStats(context.Context, *TokenStatsReq) (*TokenStatsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryTokenStats not implemented")
+}
+func (UnimplementedIngestQueryServiceServer) QueryCandles(context.Context, *CandlesReq) (*CandlesResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryCandles not implemented")
+}
+func (UnimplementedIngestQueryServiceServer) QueryQuotePrice(context.Context, *QuotePriceReq) (*QuotePriceResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryQuotePrice not implemented")
+}
+func (UnimplementedIngestQueryServiceServer) QueryWalletPositions(context.Context, *WalletPositionsReq) (*WalletPositionsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryWalletPositions not implemented")
+}
+func (UnimplementedIngestQueryServiceServer) QueryIndexedSlot(context.Context, *IndexedSlotReq) (*IndexedSlotResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryIndexedSlot not implemented")
+}
 func (UnimplementedIngestQueryServiceServer) mustEmbedUnimplementedIngestQueryServiceServer() {}
 func (UnimplementedIngestQueryServiceServer) testEmbeddedByValue()                            {}
 
@@ -374,6 +502,42 @@ func _IngestQueryService_QueryBalancesByAccounts_Handler(srv interface{}, ctx co
 	return interceptor(ctx, in, info, handler)
 }
 
+func _IngestQueryService_QueryBalanceHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BalanceHistoryReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngestQueryServiceServer).QueryBalanceHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IngestQueryService_QueryBalanceHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngestQueryServiceServer).QueryBalanceHistory(ctx, req.(*BalanceHistoryReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IngestQueryService_QueryBalanceAtSlot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BalanceAtSlotReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngestQueryServiceServer).QueryBalanceAtSlot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IngestQueryService_QueryBalanceAtSlot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngestQueryServiceServer).QueryBalanceAtSlot(ctx, req.(*BalanceAtSlotReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _IngestQueryService_QueryPoolsByAddresses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PoolAddressesReq)
 	if err := dec(in); err != nil {
@@ -410,6 +574,114 @@ func _IngestQueryService_QueryPoolsByToken_Handler(srv interface{}, ctx context.
 	return interceptor(ctx, in, info, handler)
 }
 
+func _IngestQueryService_QueryTokensByAddresses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TokenAddressesReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngestQueryServiceServer).QueryTokensByAddresses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IngestQueryService_QueryTokensByAddresses_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngestQueryServiceServer).QueryTokensByAddresses(ctx, req.(*TokenAddressesReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IngestQueryService_QueryTokenStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TokenStatsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngestQueryServiceServer).QueryTokenStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IngestQueryService_QueryTokenStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngestQueryServiceServer).QueryTokenStats(ctx, req.(*TokenStatsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IngestQueryService_QueryCandles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CandlesReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngestQueryServiceServer).QueryCandles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IngestQueryService_QueryCandles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngestQueryServiceServer).QueryCandles(ctx, req.(*CandlesReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IngestQueryService_QueryQuotePrice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QuotePriceReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngestQueryServiceServer).QueryQuotePrice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IngestQueryService_QueryQuotePrice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngestQueryServiceServer).QueryQuotePrice(ctx, req.(*QuotePriceReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IngestQueryService_QueryWalletPositions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WalletPositionsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngestQueryServiceServer).QueryWalletPositions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IngestQueryService_QueryWalletPositions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngestQueryServiceServer).QueryWalletPositions(ctx, req.(*WalletPositionsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IngestQueryService_QueryIndexedSlot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IndexedSlotReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngestQueryServiceServer).QueryIndexedSlot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IngestQueryService_QueryIndexedSlot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngestQueryServiceServer).QueryIndexedSlot(ctx, req.(*IndexedSlotReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // IngestQueryService_ServiceDesc is the grpc.ServiceDesc for IngestQueryService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -449,6 +721,14 @@ var IngestQueryService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "QueryBalancesByAccounts",
 			Handler:    _IngestQueryService_QueryBalancesByAccounts_Handler,
 		},
+		{
+			MethodName: "QueryBalanceHistory",
+			Handler:    _IngestQueryService_QueryBalanceHistory_Handler,
+		},
+		{
+			MethodName: "QueryBalanceAtSlot",
+			Handler:    _IngestQueryService_QueryBalanceAtSlot_Handler,
+		},
 		{
 			MethodName: "QueryPoolsByAddresses",
 			Handler:    _IngestQueryService_QueryPoolsByAddresses_Handler,
@@ -457,6 +737,30 @@ var IngestQueryService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "QueryPoolsByToken",
 			Handler:    _IngestQueryService_QueryPoolsByToken_Handler,
 		},
+		{
+			MethodName: "QueryTokensByAddresses",
+			Handler:    _IngestQueryService_QueryTokensByAddresses_Handler,
+		},
+		{
+			MethodName: "QueryTokenStats",
+			Handler:    _IngestQueryService_QueryTokenStats_Handler,
+		},
+		{
+			MethodName: "QueryCandles",
+			Handler:    _IngestQueryService_QueryCandles_Handler,
+		},
+		{
+			MethodName: "QueryQuotePrice",
+			Handler:    _IngestQueryService_QueryQuotePrice_Handler,
+		},
+		{
+			MethodName: "QueryWalletPositions",
+			Handler:    _IngestQueryService_QueryWalletPositions_Handler,
+		},
+		{
+			MethodName: "QueryIndexedSlot",
+			Handler:    _IngestQueryService_QueryIndexedSlot_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "ingest_query.proto",
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS balance_history (
+    account_address VARCHAR(44) NOT NULL,
+    event_id BIGINT NOT NULL,
+
+    owner_address VARCHAR(44) NOT NULL,
+    token_address VARCHAR(44) NOT NULL,
+
+    pre_balance DECIMAL(20, 0) NOT NULL,
+    post_balance DECIMAL(20, 0) NOT NULL,
+    delta DECIMAL(21, 0) NOT NULL,
+
+    block_time INT NOT NULL,
+
+    PRIMARY KEY (account_address, event_id)
+)
+        WITH (CONSISTENCY = 'strong', MUTABILITY = 'MUTABLE_LATEST');
+
+CREATE INDEX IF NOT EXISTS idx_balance_history_owner_token_id
+    ON balance_history(owner_address, token_address, event_id DESC)
+    WITH (INDEX_COVERED_TYPE = 'COVERED_ALL_COLUMNS_IN_SCHEMA');
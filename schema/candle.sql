@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS candle (
+    pool_address VARCHAR(44) NOT NULL,
+    interval_sec INT NOT NULL,
+    open_time INT NOT NULL,
+
+    token VARCHAR(44) NOT NULL,
+    quote_token VARCHAR(44) NOT NULL,
+
+    open_price DOUBLE NOT NULL,
+    high_price DOUBLE NOT NULL,
+    low_price DOUBLE NOT NULL,
+    close_price DOUBLE NOT NULL,
+    volume_usd DOUBLE NOT NULL,
+    base_volume DECIMAL(38, 0) NOT NULL,
+    quote_volume DECIMAL(38, 0) NOT NULL,
+    buy_count INT NOT NULL,
+    sell_count INT NOT NULL,
+
+    open_event_id BIGINT NOT NULL,
+    close_event_id BIGINT NOT NULL,
+
+    update_at INT NOT NULL,
+
+    PRIMARY KEY (pool_address, interval_sec, open_time)
+)
+        WITH (CONSISTENCY = 'strong', MUTABILITY = 'MUTABLE_LATEST');
@@ -30,6 +30,15 @@ CREATE INDEX IF NOT EXISTS idx_pool_type_id
     ON chain_event(pool_address, event_type, event_id DESC)
     WITH (INDEX_COVERED_TYPE = 'COVERED_ALL_COLUMNS_IN_SCHEMA');
 
+-- K 线按 (池子, 时间范围) 从 chain_event 重算；只在重算时读取，不做覆盖，避免每条事件多写一份全部列
+CREATE INDEX IF NOT EXISTS idx_pool_time
+    ON chain_event(pool_address, block_time);
+
+-- token 分钟交易汇总按 (token, 时间范围) 从 chain_event 重算；同样不做覆盖
+CREATE INDEX IF NOT EXISTS idx_token_time
+    ON chain_event(token, block_time);
+
+-- 钱包持仓按 (钱包, token) 的全部买卖从 chain_event 重算
 CREATE INDEX IF NOT EXISTS idx_user_token_type_id_desc
     ON chain_event(user_wallet, token, event_type, event_id DESC)
     WITH (INDEX_COVERED_TYPE = 'COVERED_ALL_COLUMNS_IN_SCHEMA');
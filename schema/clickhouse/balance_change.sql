@@ -0,0 +1,14 @@
+-- ClickHouse 余额变更流水，每次 flush 的余额快照追加一行（主存储只保留最新余额）
+-- event_id 为产生该余额的最后一个事件，高 32 位即 slot
+CREATE TABLE IF NOT EXISTS balance_change (
+    account_address String,
+    owner_address String,
+    token_address String,
+    balance Decimal(38, 0),
+    event_id Int64,
+    slot UInt64,
+    create_at UInt32
+)
+ENGINE = ReplacingMergeTree
+PARTITION BY intDiv(slot, 10000000)
+ORDER BY (token_address, owner_address, account_address, event_id);
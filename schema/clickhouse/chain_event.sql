@@ -0,0 +1,31 @@
+-- ClickHouse 分析表，由 clickhouse sink 写入，按 token + 时间排序便于时间序列聚合
+-- ReplacingMergeTree 在合并时按排序键去重，Kafka 重放的重复写入不影响最终结果
+-- MIGRATE 事件在来源池和目标池各有一行（event_id 相同），排序键需包含 pool_address，否则两行会被合并
+-- 排序键无法在线修改，已按旧排序键建表的需要重建表后回灌数据
+CREATE TABLE IF NOT EXISTS chain_event (
+    event_id Int64,
+    slot UInt64,
+    event_type Int16,
+    dex Int16,
+
+    user_wallet String,
+    to_wallet String,
+
+    pool_address String,
+    token String,
+    quote_token String,
+
+    token_amount Decimal(38, 0),
+    quote_amount Decimal(38, 0),
+    volume_usd Float64,
+    price_usd Float64,
+
+    tx_hash String,
+    signer String,
+
+    block_time DateTime,
+    create_at UInt32
+)
+ENGINE = ReplacingMergeTree
+PARTITION BY toYYYYMM(block_time)
+ORDER BY (token, block_time, event_id, pool_address);
@@ -0,0 +1,10 @@
+-- clickhouse sink 的分区写入进度：每个分区已写入的最大实时 slot，重启后跳过 Kafka 重放的旧区块
+CREATE TABLE IF NOT EXISTS sink_offset (
+    sink String,
+    router String,
+    partition Int32,
+    slot UInt64,
+    update_at UInt32
+)
+ENGINE = ReplacingMergeTree(update_at)
+ORDER BY (sink, router, partition);
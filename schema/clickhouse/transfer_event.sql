@@ -0,0 +1,21 @@
+-- ClickHouse 分析表，由 clickhouse sink 写入
+CREATE TABLE IF NOT EXISTS transfer_event (
+    event_id Int64,
+    slot UInt64,
+
+    from_wallet String,
+    to_wallet String,
+
+    token String,
+    amount Decimal(38, 0),
+    decimals Int16,
+
+    tx_hash String,
+    signer String,
+
+    block_time DateTime,
+    create_at UInt32
+)
+ENGINE = ReplacingMergeTree
+PARTITION BY toYYYYMM(block_time)
+ORDER BY (token, block_time, event_id);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS ingest_checkpoint (
+    router VARCHAR(16) NOT NULL,
+    topic VARCHAR(128) NOT NULL,
+    partition_id INT NOT NULL,
+
+    kafka_offset BIGINT NOT NULL,
+    max_slot BIGINT NOT NULL,
+
+    update_at INT NOT NULL,
+
+    PRIMARY KEY (router, topic, partition_id)
+)
+        WITH (CONSISTENCY = 'strong', MUTABILITY = 'MUTABLE_LATEST');
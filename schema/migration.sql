@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS migration (
+    event_id_hash INT NOT NULL,
+    event_id BIGINT NOT NULL,
+
+    token VARCHAR(44) NOT NULL,
+    src_pool_address VARCHAR(44) NOT NULL,
+    dest_pool_address VARCHAR(44) NOT NULL,
+    src_dex SMALLINT NOT NULL,
+    dest_dex SMALLINT NOT NULL,
+    src_quote_token VARCHAR(44) NOT NULL,
+    dest_quote_token VARCHAR(44) NOT NULL,
+
+    token_amount DECIMAL(20, 0) NOT NULL,
+    quote_amount DECIMAL(20, 0) NOT NULL,
+    migration_fee DECIMAL(20, 0) NOT NULL,
+    token_decimals SMALLINT NOT NULL,
+    quote_decimals SMALLINT NOT NULL,
+
+    creator VARCHAR(44) NOT NULL,
+    user_wallet VARCHAR(44) NOT NULL,
+
+    tx_hash VARCHAR(88) NOT NULL,
+    signer VARCHAR(44) NOT NULL,
+
+    block_time INT NOT NULL,
+    create_at INT NOT NULL,
+
+    PRIMARY KEY (event_id_hash, event_id)
+)
+        WITH (CONSISTENCY = 'strong', MUTABILITY = 'MUTABLE_LATEST');
+
+CREATE INDEX IF NOT EXISTS idx_migration_token
+    ON migration(token, event_id DESC)
+    WITH (INDEX_COVERED_TYPE = 'COVERED_ALL_COLUMNS_IN_SCHEMA');
+
+CREATE INDEX IF NOT EXISTS idx_migration_src_pool
+    ON migration(src_pool_address, event_id DESC)
+    WITH (INDEX_COVERED_TYPE = 'COVERED_ALL_COLUMNS_IN_SCHEMA');
+
+CREATE INDEX IF NOT EXISTS idx_migration_dest_pool
+    ON migration(dest_pool_address, event_id DESC)
+    WITH (INDEX_COVERED_TYPE = 'COVERED_ALL_COLUMNS_IN_SCHEMA');
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS pool_state (
+    pool_address VARCHAR(44) NOT NULL,
+
+    token_reserve DECIMAL(20, 0) NOT NULL,
+    quote_reserve DECIMAL(20, 0) NOT NULL,
+    token_decimals SMALLINT NOT NULL,
+    quote_decimals SMALLINT NOT NULL,
+
+    price_usd DOUBLE NOT NULL,
+    quote_price_usd DOUBLE NOT NULL,
+    liquidity_usd DOUBLE NOT NULL,
+
+    last_event_id BIGINT NOT NULL,
+    last_trade_time INT NOT NULL,
+    update_at INT NOT NULL,
+
+    PRIMARY KEY (pool_address)
+)
+        WITH (CONSISTENCY = 'strong', MUTABILITY = 'MUTABLE_LATEST');
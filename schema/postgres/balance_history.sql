@@ -0,0 +1,19 @@
+-- PostgreSQL 版本，对应 schema/balance_history.sql
+CREATE TABLE IF NOT EXISTS balance_history (
+    account_address VARCHAR(44) NOT NULL,
+    event_id BIGINT NOT NULL,
+
+    owner_address VARCHAR(44) NOT NULL,
+    token_address VARCHAR(44) NOT NULL,
+
+    pre_balance NUMERIC(20, 0) NOT NULL,
+    post_balance NUMERIC(20, 0) NOT NULL,
+    delta NUMERIC(21, 0) NOT NULL,
+
+    block_time INT NOT NULL,
+
+    PRIMARY KEY (account_address, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_history_owner_token_id
+    ON balance_history(owner_address, token_address, event_id DESC);
@@ -0,0 +1,26 @@
+-- PostgreSQL 版本，对应 schema/candle.sql
+CREATE TABLE IF NOT EXISTS candle (
+    pool_address VARCHAR(44) NOT NULL,
+    interval_sec INT NOT NULL,
+    open_time INT NOT NULL,
+
+    token VARCHAR(44) NOT NULL,
+    quote_token VARCHAR(44) NOT NULL,
+
+    open_price DOUBLE PRECISION NOT NULL,
+    high_price DOUBLE PRECISION NOT NULL,
+    low_price DOUBLE PRECISION NOT NULL,
+    close_price DOUBLE PRECISION NOT NULL,
+    volume_usd DOUBLE PRECISION NOT NULL,
+    base_volume NUMERIC(38, 0) NOT NULL,
+    quote_volume NUMERIC(38, 0) NOT NULL,
+    buy_count INT NOT NULL,
+    sell_count INT NOT NULL,
+
+    open_event_id BIGINT NOT NULL,
+    close_event_id BIGINT NOT NULL,
+
+    update_at INT NOT NULL,
+
+    PRIMARY KEY (pool_address, interval_sec, open_time)
+);
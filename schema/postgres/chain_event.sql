@@ -0,0 +1,45 @@
+-- PostgreSQL 版本，对应 schema/chain_event.sql
+CREATE TABLE IF NOT EXISTS chain_event (
+     event_id_hash INT NOT NULL,
+     event_id BIGINT NOT NULL,
+     event_type SMALLINT NOT NULL,
+     dex SMALLINT NOT NULL,
+
+     user_wallet VARCHAR(44) NOT NULL,
+     to_wallet VARCHAR(44) NOT NULL,
+
+     pool_address VARCHAR(44) NOT NULL,
+     token VARCHAR(44) NOT NULL,
+     quote_token VARCHAR(44) NOT NULL,
+
+     token_amount NUMERIC(20, 0) NOT NULL,
+     quote_amount NUMERIC(20, 0) NOT NULL,
+     volume_usd DOUBLE PRECISION NOT NULL,
+     price_usd DOUBLE PRECISION NOT NULL,
+
+     tx_hash VARCHAR(88) NOT NULL,
+     signer VARCHAR(44) NOT NULL,
+
+     block_time INT NOT NULL,
+     create_at INT NOT NULL,
+
+     PRIMARY KEY (event_id_hash, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pool_type_id
+    ON chain_event(pool_address, event_type, event_id DESC);
+
+-- K 线按 (池子, 时间范围) 从 chain_event 重算
+CREATE INDEX IF NOT EXISTS idx_pool_time
+    ON chain_event(pool_address, block_time);
+
+-- token 分钟交易汇总按 (token, 时间范围) 从 chain_event 重算
+CREATE INDEX IF NOT EXISTS idx_token_time
+    ON chain_event(token, block_time);
+
+-- 钱包持仓按 (钱包, token) 的全部买卖从 chain_event 重算
+CREATE INDEX IF NOT EXISTS idx_user_token_type_id_desc
+    ON chain_event(user_wallet, token, event_type, event_id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_user_type_time
+    ON chain_event(user_wallet, event_type, block_time DESC);
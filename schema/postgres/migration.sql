@@ -0,0 +1,39 @@
+-- PostgreSQL 版本，对应 schema/migration.sql
+CREATE TABLE IF NOT EXISTS migration (
+    event_id_hash INT NOT NULL,
+    event_id BIGINT NOT NULL,
+
+    token VARCHAR(44) NOT NULL,
+    src_pool_address VARCHAR(44) NOT NULL,
+    dest_pool_address VARCHAR(44) NOT NULL,
+    src_dex SMALLINT NOT NULL,
+    dest_dex SMALLINT NOT NULL,
+    src_quote_token VARCHAR(44) NOT NULL,
+    dest_quote_token VARCHAR(44) NOT NULL,
+
+    token_amount NUMERIC(20, 0) NOT NULL,
+    quote_amount NUMERIC(20, 0) NOT NULL,
+    migration_fee NUMERIC(20, 0) NOT NULL,
+    token_decimals SMALLINT NOT NULL,
+    quote_decimals SMALLINT NOT NULL,
+
+    creator VARCHAR(44) NOT NULL,
+    user_wallet VARCHAR(44) NOT NULL,
+
+    tx_hash VARCHAR(88) NOT NULL,
+    signer VARCHAR(44) NOT NULL,
+
+    block_time INT NOT NULL,
+    create_at INT NOT NULL,
+
+    PRIMARY KEY (event_id_hash, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_migration_token
+    ON migration(token, event_id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_migration_src_pool
+    ON migration(src_pool_address, event_id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_migration_dest_pool
+    ON migration(dest_pool_address, event_id DESC);
@@ -0,0 +1,17 @@
+-- PostgreSQL 版本，对应 schema/pool.sql
+-- create_at 带默认值：不含 create_at 的写入（仅更新账户信息）在行不存在时也能插入
+CREATE TABLE IF NOT EXISTS pool (
+    pool_address VARCHAR(44) NOT NULL,
+    account_key BIGINT NOT NULL,
+    dex SMALLINT NOT NULL,
+    token_address VARCHAR(44) NOT NULL,
+    quote_address VARCHAR(44) NOT NULL,
+    token_account VARCHAR(44) NOT NULL,
+    quote_account VARCHAR(44) NOT NULL,
+    create_at INT NOT NULL DEFAULT 0,
+    update_at INT NOT NULL,
+    PRIMARY KEY (pool_address, account_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pool_token_quote
+    ON pool(token_address, quote_address);
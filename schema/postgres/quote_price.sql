@@ -0,0 +1,14 @@
+-- PostgreSQL 版本，对应 schema/quote_price.sql
+CREATE TABLE IF NOT EXISTS quote_price (
+    token VARCHAR(44) NOT NULL,
+    slot BIGINT NOT NULL,
+
+    block_time INT NOT NULL,
+    price DOUBLE PRECISION NOT NULL,
+    decimals SMALLINT NOT NULL,
+
+    PRIMARY KEY (token, slot)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quote_price_time
+    ON quote_price(token, block_time DESC);
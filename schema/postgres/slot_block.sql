@@ -0,0 +1,13 @@
+-- PostgreSQL 版本，对应 schema/slot_block.sql
+CREATE TABLE IF NOT EXISTS slot_block (
+    router VARCHAR(16) NOT NULL,
+    slot BIGINT NOT NULL,
+
+    block_hash VARCHAR(44) NOT NULL,
+    source SMALLINT NOT NULL,
+    rollback_keys BYTEA,
+
+    update_at INT NOT NULL,
+
+    PRIMARY KEY (router, slot)
+);
@@ -0,0 +1,11 @@
+-- PostgreSQL 版本，对应 schema/token_holder_stats.sql
+CREATE TABLE IF NOT EXISTS token_holder_stats (
+    token VARCHAR(44) NOT NULL,
+
+    holder_count BIGINT NOT NULL,
+
+    reconciled_at INT NOT NULL,
+    update_at INT NOT NULL,
+
+    PRIMARY KEY (token)
+);
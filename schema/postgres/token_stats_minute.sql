@@ -0,0 +1,22 @@
+-- PostgreSQL 版本，对应 schema/token_stats_minute.sql
+CREATE TABLE IF NOT EXISTS token_stats_minute (
+    token VARCHAR(44) NOT NULL,
+    minute_time INT NOT NULL,
+
+    volume_usd DOUBLE PRECISION NOT NULL,
+    buy_count INT NOT NULL,
+    sell_count INT NOT NULL,
+
+    open_price DOUBLE PRECISION NOT NULL,
+    open_event_id BIGINT NOT NULL,
+    close_price DOUBLE PRECISION NOT NULL,
+    close_event_id BIGINT NOT NULL,
+
+    update_at INT NOT NULL,
+
+    PRIMARY KEY (token, minute_time)
+);
+
+-- 启动时加载最近 24h 的分钟汇总
+CREATE INDEX IF NOT EXISTS idx_token_stats_minute_time
+    ON token_stats_minute(minute_time);
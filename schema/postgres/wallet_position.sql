@@ -0,0 +1,24 @@
+-- PostgreSQL 版本，对应 schema/wallet_position.sql
+CREATE TABLE IF NOT EXISTS wallet_position (
+    owner VARCHAR(44) NOT NULL,
+    token VARCHAR(44) NOT NULL,
+    decimals SMALLINT NOT NULL,
+
+    position NUMERIC(38, 0) NOT NULL,
+    avg_cost_usd DOUBLE PRECISION NOT NULL,
+
+    bought_amount NUMERIC(38, 0) NOT NULL,
+    sold_amount NUMERIC(38, 0) NOT NULL,
+    bought_usd DOUBLE PRECISION NOT NULL,
+    sold_usd DOUBLE PRECISION NOT NULL,
+    realized_pnl DOUBLE PRECISION NOT NULL,
+    buy_count INT NOT NULL,
+    sell_count INT NOT NULL,
+
+    last_event_id BIGINT NOT NULL,
+    last_trade_time INT NOT NULL,
+    update_at INT NOT NULL,
+    create_at INT NOT NULL,
+
+    PRIMARY KEY (owner, token)
+);
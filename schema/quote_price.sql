@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS quote_price (
+    token VARCHAR(44) NOT NULL,
+    slot BIGINT NOT NULL,
+
+    block_time INT NOT NULL,
+    price DOUBLE NOT NULL,
+    decimals SMALLINT NOT NULL,
+
+    PRIMARY KEY (token, slot)
+)
+        WITH (CONSISTENCY = 'strong', MUTABILITY = 'MUTABLE_LATEST');
+
+CREATE INDEX IF NOT EXISTS idx_quote_price_time
+    ON quote_price(token, block_time DESC)
+    WITH (INDEX_COVERED_TYPE = 'COVERED_ALL_COLUMNS_IN_SCHEMA');
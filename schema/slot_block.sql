@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS slot_block (
+    router VARCHAR(16) NOT NULL,
+    slot BIGINT NOT NULL,
+
+    block_hash VARCHAR(44) NOT NULL,
+    source SMALLINT NOT NULL,
+    rollback_keys VARBINARY,
+
+    update_at INT NOT NULL,
+
+    PRIMARY KEY (router, slot)
+)
+        WITH (CONSISTENCY = 'strong', MUTABILITY = 'MUTABLE_LATEST');
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS token_holder_stats (
+    token VARCHAR(44) NOT NULL,
+
+    holder_count BIGINT NOT NULL,
+
+    reconciled_at INT NOT NULL,
+    update_at INT NOT NULL,
+
+    PRIMARY KEY (token)
+)
+        WITH (CONSISTENCY = 'strong', MUTABILITY = 'MUTABLE_LATEST');
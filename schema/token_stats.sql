@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS token_stats (
+    token VARCHAR(44) NOT NULL,
+    window_sec INT NOT NULL,
+
+    volume_usd DOUBLE NOT NULL,
+    trade_count INT NOT NULL,
+    buy_count INT NOT NULL,
+    sell_count INT NOT NULL,
+    unique_traders INT NOT NULL,
+
+    price_open DOUBLE NOT NULL,
+    price_close DOUBLE NOT NULL,
+    price_change DOUBLE NOT NULL,
+
+    tracked_since INT NOT NULL,
+    update_at INT NOT NULL,
+
+    PRIMARY KEY (token, window_sec)
+)
+        WITH (CONSISTENCY = 'strong', MUTABILITY = 'MUTABLE_LATEST');
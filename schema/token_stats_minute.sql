@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS token_stats_minute (
+    token VARCHAR(44) NOT NULL,
+    minute_time INT NOT NULL,
+
+    volume_usd DOUBLE NOT NULL,
+    buy_count INT NOT NULL,
+    sell_count INT NOT NULL,
+
+    open_price DOUBLE NOT NULL,
+    open_event_id BIGINT NOT NULL,
+    close_price DOUBLE NOT NULL,
+    close_event_id BIGINT NOT NULL,
+
+    update_at INT NOT NULL,
+
+    PRIMARY KEY (token, minute_time)
+)
+        WITH (CONSISTENCY = 'strong', MUTABILITY = 'MUTABLE_LATEST');
+
+-- 启动时加载最近 24h 的分钟汇总
+CREATE INDEX IF NOT EXISTS idx_token_stats_minute_time
+    ON token_stats_minute(minute_time)
+    WITH (INDEX_COVERED_TYPE = 'COVERED_ALL_COLUMNS_IN_SCHEMA');
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS token_trader (
+    token VARCHAR(44) NOT NULL,
+    trader VARCHAR(44) NOT NULL,
+
+    last_trade_time INT NOT NULL,
+    update_at INT NOT NULL,
+
+    PRIMARY KEY (token, trader)
+)
+        WITH (CONSISTENCY = 'strong', MUTABILITY = 'MUTABLE_LATEST');
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS wallet_position (
+    owner VARCHAR(44) NOT NULL,
+    token VARCHAR(44) NOT NULL,
+    decimals SMALLINT NOT NULL,
+
+    position DECIMAL(38, 0) NOT NULL,
+    avg_cost_usd DOUBLE NOT NULL,
+
+    bought_amount DECIMAL(38, 0) NOT NULL,
+    sold_amount DECIMAL(38, 0) NOT NULL,
+    bought_usd DOUBLE NOT NULL,
+    sold_usd DOUBLE NOT NULL,
+    realized_pnl DOUBLE NOT NULL,
+    buy_count INT NOT NULL,
+    sell_count INT NOT NULL,
+
+    last_event_id BIGINT NOT NULL,
+    last_trade_time INT NOT NULL,
+    update_at INT NOT NULL,
+    create_at INT NOT NULL,
+
+    PRIMARY KEY (owner, token)
+)
+        WITH (CONSISTENCY = 'strong', MUTABILITY = 'MUTABLE_LATEST');